@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/check"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/mo"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var compileFlags struct {
+	language string
+	useFuzzy bool
+	check    bool
+}
+
+var compileCmd = &cobra.Command{
+	Use:   "compile [file...]",
+	Short: "Compile catalogs to binary .mo files",
+	Long: `Compile .po catalogs to GNU gettext binary .mo files, like msgfmt.
+
+Each .mo file is written next to its .po file (default.po → default.mo),
+little-endian with a hash table, including contexts and plural forms.
+Untranslated and obsolete entries are left out, and so are fuzzy entries
+unless --use-fuzzy is given.
+
+With --check, catalogs are checked like msgfmt -c first and are not
+compiled if problems are found:
+  • format strings (c-format, python-format, python-brace-format,
+    elixir-format, ...) must use the same arguments in msgid and msgstr
+  • msgid and msgstr must both begin and end with \n, or neither
+  • plural entries must have as many forms as the header's nplurals
+  • required header fields must be present and filled in
+
+Without a file or --language, every .po file in the gettext directory is
+compiled. Exits with a non-zero status if any catalog fails.
+
+Examples:
+  # Compile all catalogs (e.g. in CI)
+  poflow compile --check
+
+  # Compile one language, including fuzzy translations
+  poflow compile --language sv --use-fuzzy
+
+  # Compile specific files
+  poflow compile priv/gettext/sv/LC_MESSAGES/default.po`,
+	RunE:         runCompile,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(compileCmd)
+	compileCmd.Flags().StringVar(&compileFlags.language, "language", "", "language code (uses config to resolve path)")
+	compileCmd.Flags().BoolVar(&compileFlags.useFuzzy, "use-fuzzy", false, "also compile fuzzy entries")
+	compileCmd.Flags().BoolVarP(&compileFlags.check, "check", "c", false, "check format strings and header like msgfmt -c before compiling")
+}
+
+// compileResult describes a compiled catalog in --json output
+type compileResult struct {
+	File         string `json:"file"`
+	MoFile       string `json:"mo_file"`
+	Messages     int    `json:"messages"`
+	Fuzzy        int    `json:"fuzzy"`
+	Untranslated int    `json:"untranslated"`
+}
+
+func runCompile(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	// Determine which files to compile
+	files := args
+	if len(files) == 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if compileFlags.language != "" {
+			path, err := cfg.ResolvePOPath(compileFlags.language)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+			files = []string{path}
+		} else {
+			files, err = cfg.GetAllPOFiles()
+			if err != nil {
+				return fmt.Errorf("failed to find .po files: %w", err)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no .po files found in gettext directory")
+	}
+
+	totalProblems := 0
+	failedFiles := 0
+
+	for _, filePath := range files {
+		result, problems, err := compileFile(filePath)
+		if err != nil {
+			// Unreadable or unwritable files count as failures too
+			problems = []*po.SyntaxError{{File: filePath, Msg: err.Error()}}
+		}
+
+		for _, problem := range problems {
+			if jsonOutput {
+				data, err := json.Marshal(problem)
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Println(string(data))
+			} else {
+				fmt.Println(problem)
+			}
+		}
+
+		if len(problems) > 0 {
+			failedFiles++
+			totalProblems += len(problems)
+			continue
+		}
+
+		if jsonOutput {
+			data, err := json.Marshal(result)
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		} else if !quiet {
+			fmt.Printf("  ✓ %s (%d messages", result.MoFile, result.Messages)
+			if result.Fuzzy > 0 || result.Untranslated > 0 {
+				fmt.Printf(", %d fuzzy and %d untranslated skipped", result.Fuzzy, result.Untranslated)
+			}
+			fmt.Printf(")\n")
+		}
+	}
+
+	if failedFiles > 0 {
+		return fmt.Errorf("compile failed: %d problem(s) in %d of %d file(s)", totalProblems, failedFiles, len(files))
+	}
+
+	if !quiet && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "\nCompiled %d file(s)\n", len(files))
+	}
+	return nil
+}
+
+// compileFile compiles a single catalog to a .mo file next to it. With
+// --check, problems found are returned and nothing is written.
+func compileFile(filePath string) (*compileResult, []*po.SyntaxError, error) {
+	catalog, err := po.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if catalog.IsMO() {
+		return nil, nil, fmt.Errorf("already a compiled .mo file")
+	}
+
+	if compileFlags.check {
+		if problems := check.Catalog(catalog, check.Options{UseFuzzy: compileFlags.useFuzzy}); len(problems) > 0 {
+			return nil, problems, nil
+		}
+	}
+
+	result := &compileResult{
+		File:   filePath,
+		MoFile: strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".mo",
+	}
+	for _, entry := range catalog.Entries() {
+		switch {
+		case entry.Obsolete:
+		case entry.IsEmpty():
+			result.Untranslated++
+		case entry.IsFuzzy() && !compileFlags.useFuzzy:
+			result.Fuzzy++
+		default:
+			result.Messages++
+		}
+	}
+
+	messages, err := mo.Messages(catalog.HeaderEntry(), catalog.Entries(), mo.Options{UseFuzzy: compileFlags.useFuzzy})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compile %s: %w", filePath, err)
+	}
+	if err := mo.WriteFile(result.MoFile, messages); err != nil {
+		return nil, nil, fmt.Errorf("failed to write %s: %w", result.MoFile, err)
+	}
+	return result, nil, nil
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/stats"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var coverageFlags struct {
+	language string
+	min      []string
+}
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [po-file...]",
+	Short: "Check translation coverage against minimum thresholds",
+	Long: `Check the percentage of entries translated per language (as in poflow
+stats) against thresholds set in poflow.yml:
+
+  coverage:
+    min:            # fail below these
+      sv: 98
+      "*": 50       # languages not listed
+    warn:           # only warn below these
+      fi: 80
+
+A region falls back to its base language (pt_BR uses pt). Languages without
+a threshold are listed but not checked. --min sets a threshold from the
+command line, for one language (sv=98) or for those not listed (98), and
+takes precedence over poflow.yml.
+
+Catalogs are parsed in strict mode first, as in poflow validate.
+
+Exit status:
+  0  every language meets its minimum (warnings allowed)
+  2  a language is below its minimum
+  3  a catalog could not be read or parsed
+  1  any other error
+
+Examples:
+  # In CI
+  poflow coverage
+
+  # Require 98% for Swedish, whatever poflow.yml says
+  poflow coverage --min sv=98
+
+  # One JSON object per language
+  poflow coverage --json`,
+	SilenceUsage: true,
+	RunE:         runCoverage,
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().StringVar(&coverageFlags.language, "language", "", "language code (uses config to resolve path)")
+	coverageCmd.Flags().StringArrayVar(&coverageFlags.min, "min", nil, "minimum percentage, as LANG=PERCENT or PERCENT for languages without one (repeatable)")
+}
+
+// coverageRow is a line of coverage output, also used for --json
+type coverageRow struct {
+	Language   string   `json:"language"`
+	Translated int      `json:"translated"`
+	Total      int      `json:"total"`
+	Percent    float64  `json:"percent"`
+	Min        *float64 `json:"min,omitempty"`
+	WarnOnly   bool     `json:"warn_only,omitempty"`
+	Status     string   `json:"status"` // ok, fail, warn or unchecked
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	thresholds := cfg.Coverage
+	if err := applyMinFlags(&thresholds, coverageFlags.min); err != nil {
+		return err
+	}
+
+	files, err := catalogFiles(coverageFlags.language, args)
+	if err != nil {
+		return err
+	}
+
+	var languages []string
+	totals := make(map[string]*stats.Counts)
+	parseErrors := 0
+
+	for _, filePath := range files {
+		catalog, err := readCoverageCatalog(filePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			parseErrors++
+			continue
+		}
+		lang := catalogLanguage(catalog, filePath)
+		if lang == "" {
+			lang = filePath
+		}
+		if totals[lang] == nil {
+			languages = append(languages, lang)
+			totals[lang] = &stats.Counts{}
+		}
+		totals[lang].Merge(stats.Count(catalog.Entries()))
+	}
+	slices.Sort(languages)
+
+	var rows []coverageRow
+	checked, failed, warned := 0, 0, 0
+	for _, lang := range languages {
+		row := newCoverageRow(lang, totals[lang], thresholds)
+		if row.Min != nil {
+			checked++
+		}
+		switch row.Status {
+		case "fail":
+			failed++
+		case "warn":
+			warned++
+		}
+		rows = append(rows, row)
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row.Language))
+	}
+	for _, row := range rows {
+		if jsonOutput {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			continue
+		}
+		if quiet && row.Status != "fail" {
+			continue
+		}
+		fmt.Println(formatCoverageRow(row, width))
+	}
+
+	if parseErrors > 0 {
+		return &exitError{
+			code: exitParseError,
+			err:  fmt.Errorf("failed to parse %d of %d file(s)", parseErrors, len(files)),
+		}
+	}
+	if failed > 0 {
+		return &exitError{
+			code: exitBelowThreshold,
+			err:  fmt.Errorf("%d of %d language(s) below minimum coverage", failed, len(rows)),
+		}
+	}
+
+	if !quiet && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "\n✓ %d of %d language(s) checked, %d warning(s)\n", checked, len(rows), warned)
+	}
+	return nil
+}
+
+// applyMinFlags sets the thresholds given with --min, which take precedence
+// over poflow.yml
+func applyMinFlags(thresholds *config.CoverageConfig, values []string) error {
+	for _, value := range values {
+		lang, percentText, ok := strings.Cut(value, "=")
+		if !ok {
+			lang, percentText = "*", value
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(percentText, "%"), 64)
+		if err != nil || lang == "" || percent < 0 || percent > 100 {
+			return fmt.Errorf("invalid --min %q: expected LANG=PERCENT or PERCENT between 0 and 100", value)
+		}
+		thresholds.SetMin(lang, percent)
+	}
+	return nil
+}
+
+// readCoverageCatalog reads a catalog, failing on syntax errors that the
+// lenient reader would skip over
+func readCoverageCatalog(filePath string) (*po.Catalog, error) {
+	errs, err := validateFile(filePath)
+	if err != nil {
+		errs = append(errs, &po.SyntaxError{File: filePath, Msg: err.Error()})
+	}
+	if len(errs) > 0 {
+		var lines []string
+		for _, syntaxErr := range errs {
+			lines = append(lines, syntaxErr.Error())
+		}
+		return nil, fmt.Errorf("%s", strings.Join(lines, "\n"))
+	}
+
+	catalog, err := po.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return catalog, nil
+}
+
+// newCoverageRow checks the counts of a language against its threshold
+func newCoverageRow(lang string, counts *stats.Counts, thresholds config.CoverageConfig) coverageRow {
+	percent := counts.Percent()
+	row := coverageRow{
+		Language:   lang,
+		Translated: counts.Translated,
+		Total:      counts.Total,
+		// Round down, as in poflow stats, so that 97.96% does not show as 98.0%
+		Percent: math.Floor(percent*10) / 10,
+		Status:  "unchecked",
+	}
+
+	threshold, ok := thresholds.Threshold(lang)
+	if !ok {
+		return row
+	}
+	row.Min = &threshold.Percent
+	row.WarnOnly = threshold.Warn
+	switch {
+	case percent >= threshold.Percent:
+		row.Status = "ok"
+	case threshold.Warn:
+		row.Status = "warn"
+	default:
+		row.Status = "fail"
+	}
+	return row
+}
+
+// formatCoverageRow returns the summary line of a language, with language
+// codes padded to width
+func formatCoverageRow(row coverageRow, width int) string {
+	marks := map[string]string{"ok": "✓", "fail": "✗", "warn": "!", "unchecked": "-"}
+	line := fmt.Sprintf("  %s %-*s %5.1f%% (%d/%d", marks[row.Status], width, row.Language, row.Percent, row.Translated, row.Total)
+
+	switch {
+	case row.Min == nil:
+		line += ", no threshold"
+	case row.WarnOnly:
+		line += fmt.Sprintf(", warn below %g%%", *row.Min)
+	default:
+		line += fmt.Sprintf(", min %g%%", *row.Min)
+	}
+	return line + ")"
+}
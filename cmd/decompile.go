@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var decompileFlags struct {
+	output string
+}
+
+var decompileCmd = &cobra.Command{
+	Use:   "decompile [file.mo]",
+	Short: "Convert a binary .mo file back to a .po catalog",
+	Long: `Convert a compiled .mo file back to .po text, like msgunfmt.
+
+The .po catalog is written to stdout, or to the file given with --output.
+Comments, references and flags are not stored in .mo files, so only the
+header, contexts, msgids and translations (including plural forms) come back.
+
+Read-only commands (search, searchvalue, listempty, header, ...) accept .mo
+files directly, so decompiling is only needed to get an editable catalog.
+
+Examples:
+  poflow decompile vendor/sv/LC_MESSAGES/default.mo
+  poflow decompile default.mo --output default.po
+  cat default.mo | poflow decompile`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDecompile,
+}
+
+func init() {
+	rootCmd.AddCommand(decompileCmd)
+	decompileCmd.Flags().StringVarP(&decompileFlags.output, "output", "o", "", "write the .po catalog to this file instead of stdout")
+}
+
+func runDecompile(cmd *cobra.Command, args []string) error {
+	// Read from file or stdin
+	input := os.Stdin
+	if len(args) > 0 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	catalog, err := po.ReadCatalog(input)
+	if err != nil {
+		return fmt.Errorf("failed to read .mo file: %w", err)
+	}
+	if !catalog.IsMO() {
+		return fmt.Errorf("input is not a compiled .mo file")
+	}
+
+	if decompileFlags.output != "" {
+		if err := catalog.WriteFile(decompileFlags.output); err != nil {
+			return fmt.Errorf("failed to write %s: %w", decompileFlags.output, err)
+		}
+		return nil
+	}
+	return catalog.Write(os.Stdout)
+}
@@ -10,7 +10,8 @@ import (
 )
 
 var editFlags struct {
-	dryRun bool
+	dryRun  bool
+	context string
 }
 
 var editCmd = &cobra.Command{
@@ -22,7 +23,9 @@ This command:
   1. Finds all .po files in your gettext directory
   2. Finds the .pot template file (if it exists)
   3. Updates the msgid in all matching entries
-  4. Updates source code files that reference the msgid
+  4. Updates source code files that reference the msgid (with --context,
+     only calls passing that context, e.g. pgettext("button", "Open");
+     without it, only calls passing no context)
   5. Preserves translations (msgstr) exactly
   6. Reports what was changed
 
@@ -31,7 +34,10 @@ Examples:
   poflow edit "Sign In" "Log In"
 
   # Preview changes without modifying files
-  poflow edit --dry-run "Sign In" "Log In"`,
+  poflow edit --dry-run "Sign In" "Log In"
+
+  # Update only the entry with msgctxt "button"
+  poflow edit --context button "Open" "Launch"`,
 	Args: cobra.ExactArgs(2),
 	RunE: runEdit,
 }
@@ -39,6 +45,7 @@ Examples:
 func init() {
 	rootCmd.AddCommand(editCmd)
 	editCmd.Flags().BoolVar(&editFlags.dryRun, "dry-run", false, "show what would be changed without modifying files")
+	editCmd.Flags().StringVar(&editFlags.context, "context", "", "msgctxt of the entry to update (default: entries without context)")
 }
 
 func runEdit(cmd *cobra.Command, args []string) error {
@@ -68,7 +75,7 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	}
 
 	if editFlags.dryRun {
-		fmt.Println("DRY RUN - No files will be modified\n")
+		fmt.Print("DRY RUN - No files will be modified\n\n")
 	}
 
 	// Get current working directory as base for source file paths
@@ -83,7 +90,7 @@ func runEdit(cmd *cobra.Command, args []string) error {
 
 	for _, filePath := range poFiles {
 		// Always update source files along with .po files
-		result, err := editor.UpdateMsgIDInFileWithSources(filePath, oldMsgID, newMsgID, editFlags.dryRun, baseDir)
+		result, err := editor.UpdateMsgIDInFileWithSources(filePath, editFlags.context, oldMsgID, newMsgID, editFlags.dryRun, baseDir)
 		if err != nil {
 			fmt.Printf("  ✗ %s: %v\n", filePath, err)
 			continue
@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/exchange/jsonfmt"
+	"github.com/xnilsson/poflow/internal/exchange/mobile"
+	"github.com/xnilsson/poflow/internal/exchange/xliff"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var exportFlags struct {
+	format         string
+	language       string
+	outputDir      string
+	sourceLanguage string
+	xliffVersion   string
+}
+
+// exportFormats are the supported --format values of export
+var exportFormats = []string{"xliff", "i18next", "arb", "flat-json", "android", "ios", "csv", "tsv"}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [po-file...]",
+	Short: "Export catalogs for translation agencies and tools",
+	Long: `Export catalogs to an exchange format, one file per language (per
+domain for spreadsheets).
+
+Formats:
+  xliff   XLIFF 1.2 (default) or 2.0, for translation agencies and CAT tools.
+          Written as {domain}.{lang}.xlf. Every entry becomes a unit with
+          its msgctxt, references and developer (#.) and translator (#)
+          comments as notes. Plural entries get one unit per plural form.
+          Fuzzy translations are marked for review.
+  i18next i18next JSON (v4), written as {lang}/{domain}.json. The msgid is
+          the key (set keySeparator and nsSeparator to false), with
+          _{msgctxt} for contexts and _one, _few, _other, ... for plural
+          forms. %{name} becomes {{name}}.
+  arb     Flutter ARB, written as {domain}_{lang}.arb. Keys are camelCase
+          identifiers from the msgid; plurals become ICU plural messages on
+          {count}, and %{name} becomes {name}. Developer comments become
+          descriptions.
+  flat-json
+          A flat JSON object of msgid to msgstr, written as
+          {domain}.{lang}.json. Keys are written like translate input:
+          msgctxt::msgid, and msgid[N] for plural forms.
+  android Android string resources, written as values-{lang}/strings.xml
+          (values/ for the source language). Names are snake_case
+          identifiers from the msgid, plurals become <plurals> resources,
+          and %{name} becomes a format argument (%d for count, %s, %1$s).
+  ios     Apple string tables, written as {lang}.lproj/Localizable.strings,
+          plus Localizable.stringsdict for plural entries. The msgid is the
+          key, and %{name} becomes a format argument (%ld for count, %@,
+          %1$@).
+  csv, tsv
+          A spreadsheet per domain with every language, written as
+          {domain}.csv or {domain}.tsv: columns msgctxt, msgid,
+          msgid_plural, plural (form index), one per language, references,
+          comments (developer comments) and base. Plural entries get a row
+          per form. Edit the language cells and import the file again; base
+          records the msgstrs as exported, so that translations changed in
+          the .po files since are reported as conflicts rather than
+          overwritten.
+
+i18next, ARB, Android and iOS files are for apps to load, so untranslated
+and fuzzy entries are left out (apps fall back to the source language); for
+the source language (--source-language), msgids stand in for empty
+msgstrs. XLIFF, flat JSON and spreadsheets include every entry, to be
+translated.
+
+The keys generated for arb (camel, snake, hash), android (snake, hash) and
+ios (msgid, snake, camel, hash) can be chosen in poflow.yml:
+
+  export:
+    keys:
+      android: hash
+      ios: snake
+
+Keys depend only on the msgctxts and msgids of the domain's catalogs and
+template in the gettext directory, so an entry gets the same key in every
+language, and on every export.
+
+Without a file or --language, every .po file in the gettext directory is
+exported. The language is taken from the catalog's Language header, or from
+its {lang}/LC_MESSAGES path. Send the files back with 'poflow import'.
+
+Examples:
+  # Export all languages for the agency
+  poflow export --format xliff --output-dir to-agency
+
+  # Export Swedish as XLIFF 2.0
+  poflow export --format xliff --xliff-version 2.0 --language sv
+
+  # Generate the React app's i18next resources
+  poflow export --format i18next --output-dir assets/locales
+
+  # One spreadsheet with all languages for reviewers
+  poflow export --format csv
+
+  # Generate the Android app's resources
+  poflow export --format android --output-dir app/src/main/res`,
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFlags.format, "format", "xliff", "export format (xliff, i18next, arb, flat-json, android, ios, csv, tsv)")
+	exportCmd.Flags().StringVar(&exportFlags.language, "language", "", "language code (uses config to resolve path)")
+	exportCmd.Flags().StringVarP(&exportFlags.outputDir, "output-dir", "o", ".", "directory to write exported files to")
+	exportCmd.Flags().StringVar(&exportFlags.sourceLanguage, "source-language", "en", "language of the msgids")
+	exportCmd.Flags().StringVar(&exportFlags.xliffVersion, "xliff-version", xliff.Version12, "XLIFF version (1.2 or 2.0)")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	if !slices.Contains(exportFormats, exportFlags.format) {
+		return fmt.Errorf("unknown export format %q (supported: %s)", exportFlags.format, strings.Join(exportFormats, ", "))
+	}
+	if exportFlags.xliffVersion != xliff.Version12 && exportFlags.xliffVersion != xliff.Version20 {
+		return fmt.Errorf("unsupported XLIFF version %q (use %s or %s)", exportFlags.xliffVersion, xliff.Version12, xliff.Version20)
+	}
+
+	strategy, err := keyStrategy(exportFlags.format)
+	if err != nil {
+		return err
+	}
+
+	files, err := catalogFiles(exportFlags.language, args)
+	if err != nil {
+		return err
+	}
+
+	if exportFlags.format == "csv" || exportFlags.format == "tsv" {
+		return exportSpreadsheets(files, quiet)
+	}
+
+	keys, err := domainKeys(files, strategy)
+	if err != nil {
+		return err
+	}
+
+	for _, filePath := range files {
+		outputPaths, units, err := exportFile(filePath, keys[catalogDomain(filePath)])
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", filePath, err)
+		}
+		if !quiet {
+			for _, outputPath := range outputPaths {
+				fmt.Printf("  ✓ %s (%d units)\n", outputPath, units)
+			}
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "\nExported %d file(s)\n", len(files))
+	}
+	return nil
+}
+
+// catalogFiles returns the .po files a command works on: the files given, the
+// one for --language, or every .po file in the gettext directory
+func catalogFiles(language string, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if language != "" {
+		path, err := cfg.ResolvePOPath(language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path: %w", err)
+		}
+		return []string{path}, nil
+	}
+
+	files, err := cfg.GetAllPOFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find .po files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .po files found in gettext directory")
+	}
+	return files, nil
+}
+
+// catalogLanguage returns the language of a catalog: its Language header, or
+// the language directory it is in
+func catalogLanguage(catalog *po.Catalog, filePath string) string {
+	if lang := catalog.Header().Language(); lang != "" {
+		return lang
+	}
+	return config.LanguageFromPath(filePath)
+}
+
+// keyStrategies are the key strategies of the formats that generate keys;
+// the first is the default
+var keyStrategies = map[string][]exchange.KeyStrategy{
+	"arb":     jsonfmt.ARBKeyStrategies,
+	"android": mobile.AndroidKeyStrategies,
+	"ios":     mobile.IOSKeyStrategies,
+}
+
+// keyStrategy returns the key strategy for a format, as set under
+// export.keys in poflow.yml, or "" for formats that do not generate keys
+func keyStrategy(format string) (exchange.KeyStrategy, error) {
+	allowed, ok := keyStrategies[format]
+	if !ok {
+		return "", nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	strategy, err := exchange.ParseKeyStrategy(cfg.Export.Keys[format], allowed...)
+	if err != nil {
+		return "", fmt.Errorf("invalid export.keys.%s in config: %w", format, err)
+	}
+	return strategy, nil
+}
+
+// domainKeys returns the keys strategy generates (see exchange.Keys) for the
+// domains of files, by domain. Keys are computed over every catalog of a
+// domain in the gettext directory, its template and files, so that an entry
+// gets the same key in every language even when their catalogs differ.
+// It returns nil for formats that do not generate keys.
+func domainKeys(files []string, strategy exchange.KeyStrategy) (map[string]map[string]string, error) {
+	if strategy == "" {
+		return nil, nil
+	}
+
+	domains := make(map[string]bool)
+	for _, path := range files {
+		domains[catalogDomain(path)] = true
+	}
+
+	// The domains' catalogs and templates in the gettext directory, if set up
+	paths := slices.Clone(files)
+	if cfg, err := config.Load(); err == nil && cfg.GettextPath != "" {
+		if all, err := cfg.GetAllPOFiles(); err == nil {
+			for _, path := range all {
+				if domains[catalogDomain(path)] {
+					paths = append(paths, path)
+				}
+			}
+		}
+		for domain := range domains {
+			template := filepath.Join(cfg.GettextPath, domain+".pot")
+			if _, err := os.Stat(template); err == nil {
+				paths = append(paths, template)
+			}
+		}
+	}
+
+	units := make(map[string][]*exchange.Unit)
+	read := make(map[string]bool)
+	for _, path := range paths {
+		if read[filepath.Clean(path)] {
+			continue
+		}
+		read[filepath.Clean(path)] = true
+
+		catalog, err := po.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		domain := catalogDomain(path)
+		units[domain] = append(units[domain], exchange.Units(catalog)...)
+	}
+
+	keys := make(map[string]map[string]string)
+	for domain, domainUnits := range units {
+		keys[domain] = exchange.Keys(domainUnits, strategy)
+	}
+	return keys, nil
+}
+
+// catalogDomain returns the domain of a catalog or template: its file name
+// without extension
+func catalogDomain(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// exportOutput is a file written by an export
+type exportOutput struct {
+	path  string
+	write func(io.Writer) error
+}
+
+// exportFile exports a single catalog and returns the paths written and the
+// number of units exported. keys are the keys of the catalog's domain for
+// formats that generate them (see domainKeys).
+func exportFile(filePath string, keys map[string]string) ([]string, int, error) {
+	catalog, err := po.ReadFile(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lang := catalogLanguage(catalog, filePath)
+	if lang == "" {
+		return nil, 0, fmt.Errorf("cannot determine the language (set the Language header)")
+	}
+
+	domain := catalogDomain(filePath)
+	units := exchange.Units(catalog)
+	categories := exchange.CatalogPluralCategories(catalog, lang)
+
+	var outputs []exportOutput
+	switch exportFlags.format {
+	case "xliff":
+		outputs = append(outputs, exportOutput{filepath.Join(exportFlags.outputDir, domain+"."+lang+".xlf"), func(w io.Writer) error {
+			return xliff.Write(w, &xliff.Document{
+				Version:        exportFlags.xliffVersion,
+				Original:       filePath,
+				SourceLanguage: exportFlags.sourceLanguage,
+				TargetLanguage: lang,
+				Units:          units,
+			})
+		}})
+	case "i18next":
+		outputs = append(outputs, exportOutput{filepath.Join(exportFlags.outputDir, lang, domain+".json"), func(w io.Writer) error {
+			return jsonfmt.WriteI18next(w, sourceFallback(units, lang), categories)
+		}})
+	case "arb":
+		outputs = append(outputs, exportOutput{filepath.Join(exportFlags.outputDir, domain+"_"+lang+".arb"), func(w io.Writer) error {
+			return jsonfmt.WriteARB(w, sourceFallback(units, lang), lang, categories, keys)
+		}})
+	case "flat-json":
+		outputs = append(outputs, exportOutput{filepath.Join(exportFlags.outputDir, domain+"."+lang+".json"), func(w io.Writer) error {
+			return jsonfmt.WriteFlat(w, units)
+		}})
+	case "android":
+		dir := "values-" + mobile.AndroidQualifier(lang)
+		if lang == exportFlags.sourceLanguage {
+			dir = "values"
+		}
+		name := "strings.xml"
+		if domain != "default" {
+			name = domain + ".xml"
+		}
+		outputs = append(outputs, exportOutput{filepath.Join(exportFlags.outputDir, dir, name), func(w io.Writer) error {
+			return mobile.WriteAndroid(w, sourceFallback(units, lang), categories, keys)
+		}})
+	case "ios":
+		table := "Localizable"
+		if domain != "default" {
+			table = domain
+		}
+		dir := filepath.Join(exportFlags.outputDir, mobile.LprojName(lang))
+		outputs = append(outputs, exportOutput{filepath.Join(dir, table+".strings"), func(w io.Writer) error {
+			return mobile.WriteStrings(w, sourceFallback(units, lang), keys)
+		}})
+		if mobile.HasPlurals(units) {
+			outputs = append(outputs, exportOutput{filepath.Join(dir, table+".stringsdict"), func(w io.Writer) error {
+				return mobile.WriteStringsdict(w, sourceFallback(units, lang), categories, keys)
+			}})
+		}
+	}
+
+	var paths []string
+	for _, output := range outputs {
+		if err := writeExportFile(output.path, output.write); err != nil {
+			return nil, 0, err
+		}
+		paths = append(paths, output.path)
+	}
+	return paths, len(units), nil
+}
+
+// sourceFallback returns units for the source language with the msgid as
+// translation where the msgstr is empty, as source-language catalogs are
+// usually left untranslated; units for other languages are returned as is
+func sourceFallback(units []*exchange.Unit, lang string) []*exchange.Unit {
+	if lang != exportFlags.sourceLanguage {
+		return units
+	}
+
+	filled := make([]*exchange.Unit, len(units))
+	for i, unit := range units {
+		if unit.Target == "" {
+			copied := *unit
+			copied.Target = unit.Source
+			copied.Fuzzy = false
+			unit = &copied
+		}
+		filled[i] = unit
+	}
+	return filled
+}
+
+// writeExportFile creates the file at path, and its directory, with write
+func writeExportFile(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
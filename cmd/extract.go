@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/extract"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var extractFlags struct {
+	keywords  []string
+	outputDir string
+	dryRun    bool
+}
+
+var extractCmd = &cobra.Command{
+	Use:   "extract [source-glob...]",
+	Short: "Generate .pot templates from source code",
+	Long: `Scan source files for translatable strings and write the .pot templates,
+like xgettext or mix gettext.extract, without a language-specific toolchain.
+
+Recognized calls:
+  Elixir and HEEx  gettext, dgettext, ngettext, dngettext, pgettext,
+                   dpgettext, pngettext, dpngettext and their _noop variants,
+                   in .ex/.exs files, ~H sigils and .heex templates
+  JavaScript       _() and t(), in .js, .jsx, .ts, .tsx, .vue and .svelte files
+
+Arguments must be string literals (concatenated with <> or + is fine).
+More functions can be added with --keyword or in poflow.yml, in xgettext
+--keyword syntax: "translate" takes the msgid from its first argument,
+"ntranslate:1,2" also a msgid_plural, "ptranslate:1c,2" a msgctxt first, and
+"dtranslate:1d,2" a domain first.
+
+Messages go to {gettext_path}/default.pot, or {domain}.pot for calls naming
+a domain. Every call is listed as a #: reference; comments right before a
+call that start with "TRANSLATORS:" (or the configured comment_tag) become
+#. comments. A template is only rewritten when its messages changed.
+
+Source files come from the glob patterns given, or extract.sources in
+poflow.yml:
+
+  extract:
+    sources:
+      - "lib/**/*.{ex,heex}"
+      - "assets/js/**/*.{js,ts,tsx}"
+    keywords:
+      - "translate"
+    comment_tag: "TRANSLATORS:"
+
+Run 'poflow merge' afterwards to update the .po files.
+
+Examples:
+  poflow extract
+  poflow extract --dry-run
+  poflow extract "lib/**/*.ex" --keyword "lgettext:1"`,
+	SilenceUsage: true,
+	RunE:         runExtract,
+}
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+	extractCmd.Flags().StringArrayVar(&extractFlags.keywords, "keyword", nil, "additional translation function (xgettext syntax, e.g. ntr:1,2; repeatable)")
+	extractCmd.Flags().StringVarP(&extractFlags.outputDir, "output-dir", "o", "", "directory to write templates to (default: gettext_path)")
+	extractCmd.Flags().BoolVar(&extractFlags.dryRun, "dry-run", false, "show what would be written without modifying files")
+}
+
+func runExtract(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	outputDir := extractFlags.outputDir
+	if outputDir == "" {
+		potPath, err := cfg.ResolvePOTPath()
+		if err != nil {
+			return fmt.Errorf("failed to resolve template path: %w (hint: use --output-dir)", err)
+		}
+		outputDir = filepath.Dir(potPath)
+	}
+
+	patterns := args
+	if len(patterns) == 0 {
+		patterns = cfg.Extract.Sources
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf("no source files to scan (give glob patterns or set extract.sources in poflow.yml)")
+	}
+
+	extractor, err := extract.New(append(cfg.Extract.Keywords, extractFlags.keywords...), cfg.Extract.CommentTag)
+	if err != nil {
+		return err
+	}
+
+	files, err := extract.Glob(patterns)
+	if err != nil {
+		return fmt.Errorf("failed to find source files: %w", err)
+	}
+
+	template := extract.NewTemplate()
+	scanned := 0
+	for _, path := range files {
+		if extract.LanguageOf(path) == "" {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		messages, err := extractor.Extract(path, src)
+		if err != nil {
+			return err
+		}
+		template.Add(messages...)
+		scanned++
+	}
+	if scanned == 0 {
+		return fmt.Errorf("no supported source files match %v", patterns)
+	}
+
+	if extractFlags.dryRun && !quiet {
+		fmt.Printf("DRY RUN - No files will be modified\n\n")
+	}
+
+	marker := "✓"
+	if extractFlags.dryRun {
+		marker = "→"
+	}
+
+	total := 0
+	for _, domain := range template.Domains() {
+		catalog := template.Catalog(domain)
+		potPath := filepath.Join(outputDir, domain+".pot")
+		total += catalog.Len()
+
+		changed, err := writeTemplate(catalog, potPath, extractFlags.dryRun)
+		if err != nil {
+			return err
+		}
+		if quiet {
+			continue
+		}
+		if changed {
+			fmt.Printf("  %s %s (%d messages)\n", marker, potPath, catalog.Len())
+		} else {
+			fmt.Printf("  = %s (%d messages, up to date)\n", potPath, catalog.Len())
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "\nExtracted %d message(s) from %d file(s)\n", total, scanned)
+	}
+	return nil
+}
+
+// writeTemplate writes an extracted template to potPath, unless the file
+// already has the same messages. It reports whether the template changed.
+// The POT-Creation-Date is only updated along with the messages.
+func writeTemplate(catalog *po.Catalog, potPath string, dryRun bool) (bool, error) {
+	header := catalog.Header()
+	header.Set("POT-Creation-Date", "")
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", "text/plain; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "8bit")
+
+	if existing, err := os.ReadFile(potPath); err == nil {
+		if old, err := po.ReadCatalog(bytes.NewReader(existing)); err == nil {
+			header.Set("POT-Creation-Date", old.Header().Get("POT-Creation-Date"))
+		}
+		var buf bytes.Buffer
+		if err := catalog.Write(&buf); err != nil {
+			return false, fmt.Errorf("failed to write %s: %w", potPath, err)
+		}
+		if bytes.Equal(buf.Bytes(), existing) {
+			return false, nil
+		}
+	}
+
+	header.Set("POT-Creation-Date", time.Now().Format("2006-01-02 15:04-0700"))
+	if dryRun {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(potPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := catalog.WriteFile(potPath); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", potPath, err)
+	}
+	return true, nil
+}
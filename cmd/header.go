@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/editor"
+)
+
+var headerFlags struct {
+	language string
+	dryRun   bool
+}
+
+var headerCmd = &cobra.Command{
+	Use:   "header [file]",
+	Short: "Show the .po header fields",
+	Long: `Show the fields of the .po header entry (msgid ""), such as Language,
+Plural-Forms and Content-Type.
+
+Examples:
+  poflow header file.po
+  poflow header --language sv
+  poflow header --language sv --json
+  cat file.po | poflow header`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHeader,
+}
+
+var headerSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE [file]",
+	Short: "Set a header field in one or all catalogs",
+	Long: `Set a field of the .po header entry, rewriting it in place and leaving the
+rest of the file untouched. Missing fields are appended to the header.
+
+Without a file or --language, every .po file in the gettext directory is updated.
+
+Examples:
+  # Update one catalog
+  poflow header set Language sv --language sv
+  poflow header set "Plural-Forms" "nplurals=2; plural=(n != 1);" file.po
+
+  # Update all catalogs
+  poflow header set "Project-Id-Version" "my_app 2.0"
+
+  # Preview changes without modifying files
+  poflow header set --dry-run "Project-Id-Version" "my_app 2.0"`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runHeaderSet,
+}
+
+func init() {
+	rootCmd.AddCommand(headerCmd)
+	headerCmd.AddCommand(headerSetCmd)
+	headerCmd.PersistentFlags().StringVar(&headerFlags.language, "language", "", "language code (uses config to resolve path)")
+	headerSetCmd.Flags().BoolVar(&headerFlags.dryRun, "dry-run", false, "show what would be changed without modifying files")
+}
+
+func runHeader(cmd *cobra.Command, args []string) error {
+	// Determine input source: --language, file argument or stdin
+	filePath := ""
+	if len(args) > 0 {
+		filePath = args[0]
+	}
+	p, closeCatalog, err := openCatalog(headerFlags.language, filePath)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	// The header is available once the first entry has been read
+	p.Next()
+	if err := p.Err(); err != nil {
+		return fmt.Errorf("parsing error: %w", err)
+	}
+
+	header := p.Header()
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		data, err := json.Marshal(header)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, field := range header.Fields {
+		fmt.Println(field.String())
+	}
+
+	return nil
+}
+
+func runHeaderSet(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	value := args[1]
+
+	// Determine which files to update
+	var poFiles []string
+	if len(args) > 2 {
+		poFiles = []string{args[2]}
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if headerFlags.language != "" {
+			path, err := cfg.ResolvePOPath(headerFlags.language)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+			poFiles = []string{path}
+		} else {
+			poFiles, err = cfg.GetAllPOFiles()
+			if err != nil {
+				return fmt.Errorf("failed to find .po files: %w", err)
+			}
+		}
+	}
+
+	if len(poFiles) == 0 {
+		return fmt.Errorf("no .po files found in gettext directory")
+	}
+
+	if headerFlags.dryRun {
+		fmt.Print("DRY RUN - No files will be modified\n\n")
+	}
+
+	totalUpdated := 0
+	for _, filePath := range poFiles {
+		result, err := editor.SetHeaderFieldInFile(filePath, key, value, headerFlags.dryRun)
+		if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", filePath, err)
+			continue
+		}
+
+		if result.EntriesFound > 0 {
+			totalUpdated++
+			status := "✓"
+			if headerFlags.dryRun {
+				status = "→"
+			}
+			fmt.Printf("  %s %s\n", status, filePath)
+		}
+	}
+
+	// Summary
+	fmt.Printf("\n")
+	if totalUpdated == 0 {
+		fmt.Printf("%s is already \"%s\" in all files\n", key, value)
+	} else if headerFlags.dryRun {
+		fmt.Printf("Would set %s in %d file(s)\n", key, totalUpdated)
+		fmt.Printf("\nRun without --dry-run to apply changes\n")
+	} else {
+		fmt.Printf("Set %s in %d file(s)\n", key, totalUpdated)
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/exchange/jsonfmt"
+	"github.com/xnilsson/poflow/internal/exchange/xliff"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var importFlags struct {
+	format   string
+	language string
+	domain   string
+	dryRun   bool
+}
+
+// importFormats are the supported --format values of import; the mobile
+// formats are export only
+var importFormats = []string{"xliff", "i18next", "arb", "flat-json", "csv", "tsv"}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file] [po-file]",
+	Short: "Merge translations from an exported file back into a .po file",
+	Long: `Merge translations returned by a translation agency or tool back into
+the .po file they were exported from with 'poflow export'.
+
+Supported input (see 'poflow export --help' for the formats):
+  xliff      XLIFF 1.2 and 2.0 (.xlf, .xliff)
+  i18next    i18next JSON resources ({lang}/{domain}.json)
+  arb        Flutter ARB files (.arb)
+  flat-json  Flat msgid to msgstr JSON ({domain}.{lang}.json)
+  csv, tsv   Spreadsheets with a column per language ({domain}.csv)
+
+The format is detected from the file extension, except for .json files,
+which need --format.
+
+Units are matched to entries by their ID or key, and merged like 'poflow
+translate': the msgstr (or msgstr[N]) is replaced and the fuzzy flag is
+cleared. Units with an empty target, or whose state says they still need
+translation or review, are skipped. Units that match no entry (for example
+because the msgid changed after export) are reported.
+
+Spreadsheets update the catalog of every language column (or only
+--language) in the gettext directory, for the domain named by the file:
+default.csv updates the default.po catalogs. Use --domain for a file that
+was saved under another name, such as "default (reviewed).csv".
+Cells are applied where they were edited since export; if the .po file's
+msgstr changed since export too, the row is reported as a conflict and left
+alone, and the command fails. Rows whose msgid no longer exists are
+reported.
+
+For other formats, the .po file is taken from the second argument, else from --language, else
+from the path recorded in the file (XLIFF), else from the file's language:
+its target language or locale, its {lang}/ directory (i18next) or its
+{domain}.{lang} name (flat JSON).
+
+Examples:
+  poflow import default.sv.xlf
+  poflow import default.sv.xlf priv/gettext/sv/LC_MESSAGES/default.po
+  poflow import --dry-run --language sv from-agency/default.sv.xlf
+  poflow import --format i18next assets/locales/sv/default.json
+  poflow import --dry-run default.csv
+  poflow import --domain default "default (reviewed).csv"`,
+	Args:         cobra.RangeArgs(1, 2),
+	RunE:         runImport,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFlags.format, "format", "", "input format (xliff, i18next, arb, flat-json, csv, tsv; default from the file extension)")
+	importCmd.Flags().StringVar(&importFlags.language, "language", "", "language code (uses config to resolve path)")
+	importCmd.Flags().StringVar(&importFlags.domain, "domain", "", "domain of a spreadsheet's catalogs (default from the file name)")
+	importCmd.Flags().BoolVar(&importFlags.dryRun, "dry-run", false, "show what would be updated without modifying files")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	format, err := importFormat(args[0])
+	if err != nil {
+		return err
+	}
+	if format == "csv" || format == "tsv" {
+		if len(args) > 1 {
+			return fmt.Errorf("spreadsheets update every language's catalog; use --language instead of a po-file")
+		}
+		return importSpreadsheet(args[0], format, quiet)
+	}
+	if importFlags.domain != "" {
+		return fmt.Errorf("--domain only applies to spreadsheets")
+	}
+
+	imported, err := readImportFile(args[0], format)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	poFilePath, err := importTarget(args, imported.original, imported.language)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := po.ReadFile(poFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", poFilePath, err)
+	}
+	if catalog.IsMO() {
+		return fmt.Errorf("%s is a compiled .mo file and cannot be updated", poFilePath)
+	}
+
+	var id func(*exchange.Unit) string
+	if imported.id != nil {
+		id, err = imported.id(catalog, poFilePath, catalogLanguage(catalog, poFilePath))
+		if err != nil {
+			return err
+		}
+	}
+	translations, unmatched := exchange.Match(catalog, imported.units, id, imported.reviewed)
+	updated, err := exchange.Apply(catalog, translations)
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %w", args[0], err)
+	}
+
+	skipped := 0
+	for _, unit := range imported.units {
+		if unit.Fuzzy && unit.Target != "" {
+			skipped++
+		}
+	}
+
+	if importFlags.dryRun {
+		if !quiet {
+			fmt.Printf("DRY RUN - No files will be modified\n\n")
+		}
+	} else if len(updated) > 0 {
+		if err := catalog.WriteFile(poFilePath); err != nil {
+			return fmt.Errorf("failed to write %s: %w", poFilePath, err)
+		}
+	}
+
+	if quiet {
+		return nil
+	}
+
+	marker := "✓"
+	if importFlags.dryRun {
+		marker = "→"
+	}
+	fmt.Printf("Updated %d translation(s) in %s:\n", len(updated), poFilePath)
+	for _, key := range updated {
+		fmt.Printf("  %s %s\n", marker, key)
+	}
+	if skipped > 0 {
+		fmt.Printf("\nSkipped %d unit(s) not marked as translated\n", skipped)
+	}
+	if len(unmatched) > 0 {
+		fmt.Fprintf(os.Stderr, "\nWarning: %d unit(s) match no entry in %s:\n", len(unmatched), poFilePath)
+		for _, unit := range unmatched {
+			fmt.Fprintf(os.Stderr, "  - %s\n", imported.describe(unit))
+		}
+	}
+	return nil
+}
+
+// importTarget determines the .po file to import into: the po-file argument,
+// --language, the original path recorded at export if it exists, or the
+// target language resolved through the config
+func importTarget(args []string, original, targetLanguage string) (string, error) {
+	if len(args) > 1 {
+		return args[1], nil
+	}
+
+	if importFlags.language == "" && original != "" {
+		if _, err := os.Stat(original); err == nil {
+			return original, nil
+		}
+	}
+
+	lang := importFlags.language
+	if lang == "" {
+		lang = targetLanguage
+	}
+	if lang == "" {
+		return "", fmt.Errorf("cannot determine the .po file (give it as an argument or use --language)")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	path, err := cfg.ResolvePOPath(lang)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return path, nil
+}
+
+// importFile is a file read for import
+type importFile struct {
+	units    []*exchange.Unit
+	original string // Path of the .po file recorded at export, if any
+	language string // Language of the file, if known
+	reviewed bool   // The format records review state (see exchange.Match)
+
+	// id returns the function computing the IDs of the units of the catalog
+	// at path in this format, to match the imported units against; nil uses
+	// UnitID
+	id func(catalog *po.Catalog, path, lang string) (func(*exchange.Unit) string, error)
+}
+
+// describe formats an imported unit for messages
+func (f *importFile) describe(unit *exchange.Unit) string {
+	if f.id == nil {
+		return unit.ID + ": " + exchange.Describe(unit)
+	}
+	return unit.ID
+}
+
+// importFormat returns the format of a file to import: the one given with
+// --format, or the one implied by its extension
+func importFormat(path string) (string, error) {
+	format := importFlags.format
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".xlf", ".xliff":
+			format = "xliff"
+		case ".arb":
+			format = "arb"
+		case ".csv":
+			format = "csv"
+		case ".tsv":
+			format = "tsv"
+		case ".json":
+			return "", fmt.Errorf("cannot tell JSON formats apart (use --format i18next or --format flat-json)")
+		default:
+			return "", fmt.Errorf("unknown file type (use --format)")
+		}
+	}
+	if !slices.Contains(importFormats, format) {
+		return "", fmt.Errorf("unknown import format %q (supported: %s)", format, strings.Join(importFormats, ", "))
+	}
+	return format, nil
+}
+
+// readImportFile reads a file to import in the given format
+func readImportFile(path, format string) (*importFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch format {
+	case "xliff":
+		doc, err := xliff.Read(file)
+		if err != nil {
+			return nil, err
+		}
+		return &importFile{units: doc.Units, original: doc.Original, language: doc.TargetLanguage, reviewed: true}, nil
+	case "i18next":
+		return readJSONImport(file, jsonfmt.ReadI18next, filepath.Base(filepath.Dir(path)),
+			func(catalog *po.Catalog, _, lang string) (func(*exchange.Unit) string, error) {
+				return jsonfmt.I18nextKey(exchange.CatalogPluralCategories(catalog, lang)), nil
+			})
+	case "arb":
+		strategy, err := keyStrategy("arb")
+		if err != nil {
+			return nil, err
+		}
+		return readJSONImport(file, jsonfmt.ReadARB, "",
+			func(catalog *po.Catalog, path, lang string) (func(*exchange.Unit) string, error) {
+				keys, err := domainKeys([]string{path}, strategy)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfmt.ARBKey(keys[catalogDomain(path)], exchange.CatalogPluralCategories(catalog, lang)), nil
+			})
+	default:
+		// {domain}.{lang}.json
+		lang := filepath.Ext(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		return readJSONImport(file, jsonfmt.ReadFlat, strings.TrimPrefix(lang, "."),
+			func(*po.Catalog, string, string) (func(*exchange.Unit) string, error) {
+				return jsonfmt.FlatKey, nil
+			})
+	}
+}
+
+// readJSONImport reads a JSON format file; the language declared in the file
+// takes precedence over the one derived from its path
+func readJSONImport(r io.Reader, read func(io.Reader) (*jsonfmt.File, error), pathLanguage string,
+	id func(*po.Catalog, string, string) (func(*exchange.Unit) string, error)) (*importFile, error) {
+	file, err := read(r)
+	if err != nil {
+		return nil, err
+	}
+	lang := file.Language
+	if lang == "" && pathLanguage != "." {
+		lang = pathLanguage
+	}
+	return &importFile{units: file.Units, language: lang, id: id}, nil
+}
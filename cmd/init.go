@@ -58,6 +58,32 @@ gettext_path: "%s"
 # For Phoenix/Elixir projects: "priv/gettext"
 # For Rails projects: "config/locales"
 # For custom setup: "translations" or any other path
+
+# Line wrapping for written catalogs (same rules as msgcat --width / --no-wrap)
+# wrap_width: 79
+# no_wrap: false
+
+# Key strategies for 'poflow export' (camel, snake, msgid or hash)
+# export:
+#   keys:
+#     android: snake
+#     ios: msgid
+
+# Source files and extra translation functions for 'poflow extract'
+# extract:
+#   sources:
+#     - "lib/**/*.{ex,heex}"
+#     - "assets/js/**/*.{js,ts,tsx}"
+#   keywords:
+#     - "translate"
+#   comment_tag: "TRANSLATORS:"
+
+# Minimum translated percentages for 'poflow coverage' ("*" for other languages)
+# coverage:
+#   min:
+#     sv: 98
+#   warn:
+#     "*": 50
 `, gettextPath)
 
 	// Write config file
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// openCatalog opens the catalog a read command works on: the file for the
+// --language flag from the config, else filePath, else stdin. Entries record
+// the catalog path and language in their positions. The returned close
+// function must be called when done.
+func openCatalog(language, filePath string) (*po.Reader, func() error, error) {
+	// Handle --language flag
+	if language != "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		path, err := cfg.ResolvePOPath(language)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve path: %w", err)
+		}
+		filePath = path
+	}
+
+	// Read from stdin
+	if filePath == "" {
+		r := po.NewReader(os.Stdin)
+		r.SetLanguage(language)
+		return r, func() error { return nil }, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if language == "" {
+		language = config.LanguageFromPath(filePath)
+	}
+	r := po.NewReader(file)
+	r.SetFilename(filePath)
+	r.SetLanguage(language)
+	return r, file.Close, nil
+}
@@ -2,17 +2,16 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
-	"github.com/xnilsson/poflow/internal/config"
 	"github.com/xnilsson/poflow/internal/output"
-	"github.com/xnilsson/poflow/internal/parser"
 	"github.com/spf13/cobra"
 )
 
 var (
 	listEmptyLimit    int
 	listEmptyLanguage string
+	listEmptyContext  string
+	listEmptyFuzzy    bool
 )
 
 var listemptyCmd = &cobra.Command{
@@ -20,12 +19,17 @@ var listemptyCmd = &cobra.Command{
 	Short: "List untranslated entries",
 	Long: `List all entries with empty translations (msgstr).
 
+Use --include-fuzzy to also list fuzzy entries, whose translation still
+needs review (see also: poflow listfuzzy).
+
 Examples:
   poflow listempty file.po
   poflow listempty --json file.po
   poflow listempty --limit 10 file.po
   cat file.po | poflow listempty
-  poflow listempty --language sv --json`,
+  poflow listempty --language sv --json
+  poflow listempty --context button file.po
+  poflow listempty --include-fuzzy --language sv`,
 	RunE: runListEmpty,
 }
 
@@ -33,45 +37,25 @@ func init() {
 	rootCmd.AddCommand(listemptyCmd)
 	listemptyCmd.Flags().IntVar(&listEmptyLimit, "limit", 0, "limit number of entries (0 = no limit)")
 	listemptyCmd.Flags().StringVar(&listEmptyLanguage, "language", "", "language code (uses config to resolve path)")
+	listemptyCmd.Flags().BoolVar(&listEmptyFuzzy, "include-fuzzy", false, "also list fuzzy entries")
+	listemptyCmd.Flags().StringVar(&listEmptyContext, "context", "", "only list entries with this msgctxt (use \"\" for entries without context)")
 }
 
 func runListEmpty(cmd *cobra.Command, args []string) error {
-	// Determine input source
-	var reader *os.File
-	var err error
-
-	// Handle --language flag
-	if listEmptyLanguage != "" {
-		cfg, err := config.Load()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
-		path, err := cfg.ResolvePOPath(listEmptyLanguage)
-		if err != nil {
-			return fmt.Errorf("failed to resolve path: %w", err)
-		}
-		reader, err = os.Open(path)
-		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
-		}
-		defer reader.Close()
-	} else if len(args) > 0 {
-		// Read from file
-		reader, err = os.Open(args[0])
-		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
-		}
-		defer reader.Close()
-	} else {
-		// Read from stdin
-		reader = os.Stdin
+	// Determine input source: --language, file argument or stdin
+	filePath := ""
+	if len(args) > 0 {
+		filePath = args[0]
 	}
-
-	// Create parser
-	p := parser.NewParser(reader)
+	p, closeCatalog, err := openCatalog(listEmptyLanguage, filePath)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
 
 	// Get output format from global flag
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	filterContext := cmd.Flags().Changed("context")
 	count := 0
 
 	// Stream entries
@@ -81,8 +65,18 @@ func runListEmpty(cmd *cobra.Command, args []string) error {
 			break
 		}
 
-		// Skip non-empty entries
-		if !entry.IsEmpty() {
+		// Skip obsolete entries
+		if entry.Obsolete {
+			continue
+		}
+
+		// Skip non-empty entries (fuzzy ones are kept with --include-fuzzy)
+		if !entry.IsEmpty() && !(listEmptyFuzzy && entry.IsFuzzy()) {
+			continue
+		}
+
+		// Skip entries outside the requested context
+		if filterContext && entry.MsgCtxt != listEmptyContext {
 			continue
 		}
 
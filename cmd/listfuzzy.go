@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/output"
+)
+
+var (
+	listFuzzyLimit    int
+	listFuzzyLanguage string
+	listFuzzyContext  string
+)
+
+var listfuzzyCmd = &cobra.Command{
+	Use:   "listfuzzy [file]",
+	Short: "List fuzzy entries that need review",
+	Long: `List all entries marked with the fuzzy flag ("#, fuzzy").
+
+Fuzzy entries have a translation that gettext tools ignore until it has been
+reviewed. Translating them with "poflow translate" clears the flag.
+
+Examples:
+  poflow listfuzzy file.po
+  poflow listfuzzy --json file.po
+  poflow listfuzzy --limit 10 file.po
+  cat file.po | poflow listfuzzy
+  poflow listfuzzy --language sv --json`,
+	RunE: runListFuzzy,
+}
+
+func init() {
+	rootCmd.AddCommand(listfuzzyCmd)
+	listfuzzyCmd.Flags().IntVar(&listFuzzyLimit, "limit", 0, "limit number of entries (0 = no limit)")
+	listfuzzyCmd.Flags().StringVar(&listFuzzyLanguage, "language", "", "language code (uses config to resolve path)")
+	listfuzzyCmd.Flags().StringVar(&listFuzzyContext, "context", "", "only list entries with this msgctxt (use \"\" for entries without context)")
+}
+
+func runListFuzzy(cmd *cobra.Command, args []string) error {
+	// Determine input source: --language, file argument or stdin
+	filePath := ""
+	if len(args) > 0 {
+		filePath = args[0]
+	}
+	p, closeCatalog, err := openCatalog(listFuzzyLanguage, filePath)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	// Get output format from global flag
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	filterContext := cmd.Flags().Changed("context")
+	count := 0
+
+	// Stream entries
+	for {
+		entry := p.Next()
+		if entry == nil {
+			break
+		}
+
+		// Skip obsolete entries
+		if entry.Obsolete {
+			continue
+		}
+
+		// Skip entries that are not fuzzy
+		if !entry.IsFuzzy() {
+			continue
+		}
+
+		// Skip entries outside the requested context
+		if filterContext && entry.MsgCtxt != listFuzzyContext {
+			continue
+		}
+
+		// Check limit
+		if listFuzzyLimit > 0 && count >= listFuzzyLimit {
+			break
+		}
+
+		// Output entry
+		if err := output.OutputEntry(entry, jsonOutput); err != nil {
+			return err
+		}
+
+		count++
+	}
+
+	// Check for errors
+	if err := p.Err(); err != nil {
+		return fmt.Errorf("parsing error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var mergeFlags struct {
+	language string
+	template string
+	dryRun   bool
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [po-file...]",
+	Short: "Update .po files from the .pot template, like msgmerge",
+	Long: `Update every .po file of the template's domain from the .pot template,
+like msgmerge.
+
+  - Entries are put in template order.
+  - Entries still in the template keep their translation and get the
+    template's references (#:), extracted comments (#.) and format flags.
+  - New entries are added untranslated, unless an old msgid is similar
+    enough: then its translation is reused, marked fuzzy, with the old
+    msgid kept as "#| msgid" so reviewers can see what changed.
+  - Entries no longer in the template are marked obsolete (#~) and moved to
+    the end; an obsolete entry whose msgid comes back is revived.
+
+The template is {gettext_path}/default.pot unless --template is given.
+Without a file or --language, every {domain}.po file in the gettext
+directory is merged.
+
+Examples:
+  # Merge all languages after the template changed
+  poflow merge
+
+  # Preview what would change for Swedish
+  poflow merge --language sv --dry-run
+
+  # Merge another domain
+  poflow merge --template priv/gettext/errors.pot priv/gettext/*/LC_MESSAGES/errors.po`,
+	SilenceUsage: true,
+	RunE:         runMerge,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().StringVar(&mergeFlags.language, "language", "", "language code (uses config to resolve path)")
+	mergeCmd.Flags().StringVar(&mergeFlags.template, "template", "", "template file (default: {gettext_path}/default.pot)")
+	mergeCmd.Flags().BoolVar(&mergeFlags.dryRun, "dry-run", false, "show what would change without modifying files")
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	templatePath := mergeFlags.template
+	if templatePath == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		templatePath, err = cfg.ResolvePOTPath()
+		if err != nil {
+			return fmt.Errorf("failed to resolve template path: %w", err)
+		}
+	}
+	template, err := po.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	files, err := mergeFiles(templatePath, args)
+	if err != nil {
+		return err
+	}
+
+	if mergeFlags.dryRun && !quiet {
+		fmt.Printf("DRY RUN - No files will be modified\n\n")
+	}
+
+	marker := "✓"
+	if mergeFlags.dryRun {
+		marker = "→"
+	}
+
+	merged := 0
+	for _, filePath := range files {
+		catalog, err := po.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		if catalog.IsMO() {
+			return fmt.Errorf("%s is a compiled .mo file and cannot be merged", filePath)
+		}
+
+		result := catalog.Merge(template)
+		if result.Changed() {
+			merged++
+			if !mergeFlags.dryRun {
+				if err := catalog.WriteFile(filePath); err != nil {
+					return fmt.Errorf("failed to write %s: %w", filePath, err)
+				}
+			}
+		}
+
+		if quiet {
+			continue
+		}
+		lang := catalogLanguage(catalog, filePath)
+		if lang == "" {
+			lang = filePath
+		}
+		if !result.Changed() {
+			fmt.Printf("  = %s: up to date (%s)\n", lang, filePath)
+			continue
+		}
+		fmt.Printf("  %s %s: %d new, %d fuzzy, %d obsolete, %d updated (%s)\n",
+			marker, lang, result.Added, result.Fuzzy, result.Obsolete, result.Updated, filePath)
+	}
+
+	if !quiet {
+		if mergeFlags.dryRun {
+			fmt.Fprintf(os.Stderr, "\nWould update %d of %d file(s) from %s\n", merged, len(files), templatePath)
+		} else {
+			fmt.Fprintf(os.Stderr, "\nUpdated %d of %d file(s) from %s\n", merged, len(files), templatePath)
+		}
+	}
+	return nil
+}
+
+// mergeFiles returns the .po files to merge with a template: the files given,
+// the one for --language, or every .po file of the template's domain in the
+// gettext directory
+func mergeFiles(templatePath string, args []string) ([]string, error) {
+	files, err := catalogFiles(mergeFlags.language, args)
+	if err != nil || len(args) > 0 || mergeFlags.language != "" {
+		return files, err
+	}
+
+	// Other domains have templates of their own
+	domain := strings.TrimSuffix(filepath.Base(templatePath), filepath.Ext(templatePath))
+	var domainFiles []string
+	for _, filePath := range files {
+		if filepath.Base(filePath) == domain+".po" {
+			domainFiles = append(domainFiles, filePath)
+		}
+	}
+	if len(domainFiles) == 0 {
+		return nil, fmt.Errorf("no %s.po files found in gettext directory", domain)
+	}
+	return domainFiles, nil
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/internal/editor"
+	"github.com/xnilsson/poflow/internal/output"
+)
+
+var obsoleteFlags struct {
+	language string
+	purge    bool
+	dryRun   bool
+}
+
+var obsoleteCmd = &cobra.Command{
+	Use:   "obsolete [file]",
+	Short: "List or purge obsolete (#~) entries",
+	Long: `List or remove obsolete entries left behind by msgmerge.
+
+Obsolete entries are written with a "#~" prefix and are ignored by search,
+searchvalue, listempty and listfuzzy.
+
+Without a file or --language, every .po file in the gettext directory is
+processed.
+
+Examples:
+  # List obsolete entries in all catalogs
+  poflow obsolete
+
+  # List obsolete entries for one language
+  poflow obsolete --language sv --json
+
+  # Remove obsolete entries from all catalogs
+  poflow obsolete --purge
+
+  # Preview what would be removed
+  poflow obsolete --purge --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runObsolete,
+}
+
+func init() {
+	rootCmd.AddCommand(obsoleteCmd)
+	obsoleteCmd.Flags().StringVar(&obsoleteFlags.language, "language", "", "language code (uses config to resolve path)")
+	obsoleteCmd.Flags().BoolVar(&obsoleteFlags.purge, "purge", false, "remove obsolete entries from the files")
+	obsoleteCmd.Flags().BoolVar(&obsoleteFlags.dryRun, "dry-run", false, "with --purge, show what would be removed without modifying files")
+}
+
+func runObsolete(cmd *cobra.Command, args []string) error {
+	// Determine which files to process
+	var poFiles []string
+	if len(args) > 0 {
+		poFiles = []string{args[0]}
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if obsoleteFlags.language != "" {
+			path, err := cfg.ResolvePOPath(obsoleteFlags.language)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+			poFiles = []string{path}
+		} else {
+			poFiles, err = cfg.GetAllPOFiles()
+			if err != nil {
+				return fmt.Errorf("failed to find .po files: %w", err)
+			}
+		}
+	}
+
+	if len(poFiles) == 0 {
+		return fmt.Errorf("no .po files found in gettext directory")
+	}
+
+	if obsoleteFlags.purge {
+		return purgeObsolete(poFiles)
+	}
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	for _, filePath := range poFiles {
+		if err := listObsolete(filePath, jsonOutput, len(poFiles) > 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listObsolete outputs the obsolete entries of a single file
+func listObsolete(filePath string, jsonOutput, showFileName bool) error {
+	p, closeCatalog, err := openCatalog("", filePath)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	nameShown := false
+
+	for {
+		entry := p.Next()
+		if entry == nil {
+			break
+		}
+
+		if !entry.Obsolete {
+			continue
+		}
+
+		// Separate files in text output
+		if showFileName && !jsonOutput && !nameShown {
+			fmt.Printf("# ==> %s <==\n\n", filePath)
+			nameShown = true
+		}
+
+		if err := output.OutputEntry(entry, jsonOutput); err != nil {
+			return err
+		}
+	}
+
+	if err := p.Err(); err != nil {
+		return fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// purgeObsolete removes obsolete entries from every file and prints a summary
+func purgeObsolete(poFiles []string) error {
+	if obsoleteFlags.dryRun {
+		fmt.Print("DRY RUN - No files will be modified\n\n")
+	}
+
+	totalFiles := 0
+	totalEntries := 0
+
+	for _, filePath := range poFiles {
+		result, err := editor.PurgeObsoleteInFile(filePath, obsoleteFlags.dryRun)
+		if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", filePath, err)
+			continue
+		}
+
+		if result.EntriesFound > 0 {
+			totalFiles++
+			totalEntries += result.EntriesFound
+			status := "✓"
+			if obsoleteFlags.dryRun {
+				status = "→"
+			}
+			fmt.Printf("  %s %s (%d entries)\n", status, filePath, result.EntriesFound)
+		}
+	}
+
+	// Summary
+	fmt.Printf("\n")
+	if totalEntries == 0 {
+		fmt.Printf("No obsolete entries found\n")
+	} else if obsoleteFlags.dryRun {
+		fmt.Printf("Would remove %d obsolete entries from %d file(s)\n", totalEntries, totalFiles)
+		fmt.Printf("\nRun without --dry-run to apply changes\n")
+	} else {
+		fmt.Printf("Removed %d obsolete entries from %d file(s)\n", totalEntries, totalFiles)
+	}
+
+	return nil
+}
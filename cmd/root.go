@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/xnilsson/poflow/internal/output"
 )
 
 var cfgFile string
@@ -30,10 +32,30 @@ Features:
   • Config file support for project-specific paths`,
 }
 
+// Exit codes of commands whose failures CI may want to tell apart; other
+// errors exit with 1
+const (
+	exitBelowThreshold = 2
+	exitParseError     = 3
+)
+
+// exitError is an error that exits with a specific code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -44,6 +66,12 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./poflow.yml or ~/.config/poflow/config.yml)")
 	rootCmd.PersistentFlags().Bool("json", false, "output in JSON format")
 	rootCmd.PersistentFlags().Bool("quiet", false, "suppress progress output")
+	rootCmd.PersistentFlags().Int("width", output.DefaultWidth, "page width for wrapping strings in written catalogs")
+	rootCmd.PersistentFlags().Bool("no-wrap", false, "do not wrap strings in written catalogs (only break at newlines)")
+
+	// Wrapping can also be set in poflow.yml as wrap_width and no_wrap
+	viper.BindPFlag("wrap_width", rootCmd.PersistentFlags().Lookup("width"))
+	viper.BindPFlag("no_wrap", rootCmd.PersistentFlags().Lookup("no-wrap"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -72,4 +100,11 @@ func initConfig() {
 			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 		}
 	}
+
+	// Apply line wrapping for written catalogs (flags override poflow.yml)
+	if viper.GetBool("no_wrap") {
+		output.SetWrapWidth(0)
+	} else {
+		output.SetWrapWidth(viper.GetInt("wrap_width"))
+	}
 }
@@ -2,13 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
-	"github.com/xnilsson/poflow/internal/config"
 	"github.com/xnilsson/poflow/internal/output"
-	"github.com/xnilsson/poflow/internal/parser"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +21,7 @@ Examples:
   poflow search "Welcome" file.po
   poflow search --re "^Login" file.po
   poflow search --json "error" file.po
+  poflow search --context button "Open" file.po
   cat file.po | poflow search "Welcome"`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runSearch,
@@ -33,6 +31,7 @@ var searchFlags struct {
 	useRegex bool
 	limit    int
 	language string
+	context  string
 }
 
 func init() {
@@ -40,6 +39,7 @@ func init() {
 	searchCmd.Flags().BoolVar(&searchFlags.useRegex, "re", false, "use regex pattern matching")
 	searchCmd.Flags().IntVar(&searchFlags.limit, "limit", 0, "maximum number of entries to output (0 = no limit)")
 	searchCmd.Flags().StringVar(&searchFlags.language, "language", "", "language code (uses config to resolve path)")
+	searchCmd.Flags().StringVar(&searchFlags.context, "context", "", "only match entries with this msgctxt (use \"\" for entries without context)")
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
@@ -58,42 +58,20 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		pattern = strings.ToLower(pattern)
 	}
 
-	// Determine input source
-	var reader *os.File
-
-	// Handle --language flag
-	if searchFlags.language != "" {
-		cfg, cfgErr := config.Load()
-		if cfgErr != nil {
-			return fmt.Errorf("failed to load config: %w", cfgErr)
-		}
-		path, pathErr := cfg.ResolvePOPath(searchFlags.language)
-		if pathErr != nil {
-			return fmt.Errorf("failed to resolve path: %w", pathErr)
-		}
-		var openErr error
-		reader, openErr = os.Open(path)
-		if openErr != nil {
-			return fmt.Errorf("failed to open file: %w", openErr)
-		}
-		defer reader.Close()
-	} else if len(args) == 2 {
-		// File input
-		reader, err = os.Open(args[1])
-		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
-		}
-		defer reader.Close()
-	} else {
-		// Stdin input
-		reader = os.Stdin
+	// Determine input source: --language, file argument or stdin
+	filePath := ""
+	if len(args) == 2 {
+		filePath = args[1]
 	}
-
-	// Create parser
-	p := parser.NewParser(reader)
+	p, closeCatalog, err := openCatalog(searchFlags.language, filePath)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
 
 	// Get JSON output flag
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	filterContext := cmd.Flags().Changed("context")
 
 	// Process entries
 	count := 0
@@ -103,13 +81,28 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			break
 		}
 
-		// Check if msgid matches pattern
+		// Skip obsolete entries
+		if entry.Obsolete {
+			continue
+		}
+
+		// Skip entries outside the requested context
+		if filterContext && entry.MsgCtxt != searchFlags.context {
+			continue
+		}
+
+		// Check if msgid (or any plural form) matches pattern
 		matches := false
-		if searchFlags.useRegex {
-			matches = re.MatchString(entry.MsgID)
-		} else {
-			// Case-insensitive substring match
-			matches = strings.Contains(strings.ToLower(entry.MsgID), pattern)
+		for _, value := range entry.Sources() {
+			if searchFlags.useRegex {
+				matches = re.MatchString(value)
+			} else {
+				// Case-insensitive substring match
+				matches = strings.Contains(strings.ToLower(value), pattern)
+			}
+			if matches {
+				break
+			}
 		}
 
 		if !matches {
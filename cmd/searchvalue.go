@@ -2,13 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
-	"github.com/xnilsson/poflow/internal/config"
 	"github.com/xnilsson/poflow/internal/output"
-	"github.com/xnilsson/poflow/internal/parser"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +21,7 @@ Examples:
   poflow searchvalue "Välkommen" file.po
   poflow searchvalue --re "^Tack" file.po
   poflow searchvalue --json "fel" file.po
+  poflow searchvalue --context button "Öppna" file.po
   cat file.po | poflow searchvalue "error"`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runSearchValue,
@@ -33,6 +31,7 @@ var searchvalueFlags struct {
 	useRegex bool
 	limit    int
 	language string
+	context  string
 }
 
 func init() {
@@ -40,6 +39,7 @@ func init() {
 	searchvalueCmd.Flags().BoolVar(&searchvalueFlags.useRegex, "re", false, "use regex pattern matching")
 	searchvalueCmd.Flags().IntVar(&searchvalueFlags.limit, "limit", 0, "maximum number of entries to output (0 = no limit)")
 	searchvalueCmd.Flags().StringVar(&searchvalueFlags.language, "language", "", "language code (uses config to resolve path)")
+	searchvalueCmd.Flags().StringVar(&searchvalueFlags.context, "context", "", "only match entries with this msgctxt (use \"\" for entries without context)")
 }
 
 func runSearchValue(cmd *cobra.Command, args []string) error {
@@ -58,42 +58,20 @@ func runSearchValue(cmd *cobra.Command, args []string) error {
 		pattern = strings.ToLower(pattern)
 	}
 
-	// Determine input source
-	var reader *os.File
-
-	// Handle --language flag
-	if searchvalueFlags.language != "" {
-		cfg, cfgErr := config.Load()
-		if cfgErr != nil {
-			return fmt.Errorf("failed to load config: %w", cfgErr)
-		}
-		path, pathErr := cfg.ResolvePOPath(searchvalueFlags.language)
-		if pathErr != nil {
-			return fmt.Errorf("failed to resolve path: %w", pathErr)
-		}
-		var openErr error
-		reader, openErr = os.Open(path)
-		if openErr != nil {
-			return fmt.Errorf("failed to open file: %w", openErr)
-		}
-		defer reader.Close()
-	} else if len(args) == 2 {
-		// File input
-		reader, err = os.Open(args[1])
-		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
-		}
-		defer reader.Close()
-	} else {
-		// Stdin input
-		reader = os.Stdin
+	// Determine input source: --language, file argument or stdin
+	filePath := ""
+	if len(args) == 2 {
+		filePath = args[1]
 	}
-
-	// Create parser
-	p := parser.NewParser(reader)
+	p, closeCatalog, err := openCatalog(searchvalueFlags.language, filePath)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
 
 	// Get JSON output flag
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	filterContext := cmd.Flags().Changed("context")
 
 	// Process entries
 	count := 0
@@ -103,13 +81,28 @@ func runSearchValue(cmd *cobra.Command, args []string) error {
 			break
 		}
 
-		// Check if msgstr matches pattern
+		// Skip obsolete entries
+		if entry.Obsolete {
+			continue
+		}
+
+		// Skip entries outside the requested context
+		if filterContext && entry.MsgCtxt != searchvalueFlags.context {
+			continue
+		}
+
+		// Check if msgstr (or any plural form) matches pattern
 		matches := false
-		if searchvalueFlags.useRegex {
-			matches = re.MatchString(entry.MsgStr)
-		} else {
-			// Case-insensitive substring match
-			matches = strings.Contains(strings.ToLower(entry.MsgStr), pattern)
+		for _, value := range entry.Translations() {
+			if searchvalueFlags.useRegex {
+				matches = re.MatchString(value)
+			} else {
+				// Case-insensitive substring match
+				matches = strings.Contains(strings.ToLower(value), pattern)
+			}
+			if matches {
+				break
+			}
 		}
 
 		if !matches {
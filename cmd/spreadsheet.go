@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange/csvfmt"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// spreadsheetComma returns the field separator of a spreadsheet format
+func spreadsheetComma(format string) rune {
+	if format == "tsv" {
+		return '\t'
+	}
+	return ','
+}
+
+// domainCatalogs are the catalogs of one domain, by language
+type domainCatalogs struct {
+	domain    string
+	languages []string
+	catalogs  map[string]*po.Catalog
+	paths     map[string]string
+}
+
+// readDomainCatalogs reads the given .po files grouped by domain (file name),
+// in order of domain and then language
+func readDomainCatalogs(files []string) ([]*domainCatalogs, error) {
+	byDomain := make(map[string]*domainCatalogs)
+	var domains []string
+
+	for _, filePath := range files {
+		catalog, err := po.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		lang := catalogLanguage(catalog, filePath)
+		if lang == "" {
+			return nil, fmt.Errorf("cannot determine the language of %s (set the Language header)", filePath)
+		}
+
+		domain := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		d, ok := byDomain[domain]
+		if !ok {
+			d = &domainCatalogs{domain: domain, catalogs: make(map[string]*po.Catalog), paths: make(map[string]string)}
+			byDomain[domain] = d
+			domains = append(domains, domain)
+		}
+		if other, ok := d.paths[lang]; ok {
+			return nil, fmt.Errorf("both %s and %s are %s catalogs of domain %s", other, filePath, lang, domain)
+		}
+		d.languages = append(d.languages, lang)
+		d.catalogs[lang] = catalog
+		d.paths[lang] = filePath
+	}
+
+	slices.Sort(domains)
+	result := make([]*domainCatalogs, len(domains))
+	for i, domain := range domains {
+		result[i] = byDomain[domain]
+		slices.Sort(result[i].languages)
+	}
+	return result, nil
+}
+
+// exportSpreadsheets writes a spreadsheet per domain with a column per language
+func exportSpreadsheets(files []string, quiet bool) error {
+	domains, err := readDomainCatalogs(files)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(exportFlags.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, d := range domains {
+		table := csvfmt.Build(d.catalogs, d.languages)
+		outputPath := filepath.Join(exportFlags.outputDir, d.domain+"."+exportFlags.format)
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", outputPath, err)
+		}
+		if err := csvfmt.Write(file, table, spreadsheetComma(exportFlags.format)); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
+
+		if !quiet {
+			fmt.Printf("  ✓ %s (%d rows, %s)\n", outputPath, len(table.Rows), strings.Join(d.languages, ", "))
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "\nExported %d file(s)\n", len(domains))
+	}
+	return nil
+}
+
+// importSpreadsheet applies the edited language columns of a spreadsheet to
+// the catalogs of its domain
+func importSpreadsheet(path, format string, quiet bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	table, err := csvfmt.Read(file, spreadsheetComma(format))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// The catalogs of the spreadsheet's domain, by language
+	files, err := catalogFiles("", nil)
+	if err != nil {
+		return err
+	}
+	domain := importFlags.domain
+	if domain == "" {
+		domain = catalogDomain(path)
+	}
+	var domainFiles []string
+	for _, f := range files {
+		if catalogDomain(f) == domain {
+			domainFiles = append(domainFiles, f)
+		}
+	}
+	domains, err := readDomainCatalogs(domainFiles)
+	if err != nil {
+		return err
+	}
+	if len(domains) == 0 {
+		return fmt.Errorf("no catalogs found for domain %s (spreadsheets are named after their domain; set it with --domain)", domain)
+	}
+	d := domains[0]
+
+	languages := table.Languages
+	if importFlags.language != "" {
+		if !slices.Contains(languages, importFlags.language) {
+			return fmt.Errorf("%s has no %s column", path, importFlags.language)
+		}
+		languages = []string{importFlags.language}
+	}
+
+	if importFlags.dryRun && !quiet {
+		fmt.Printf("DRY RUN - No files will be modified\n\n")
+	}
+	marker := "✓"
+	if importFlags.dryRun {
+		marker = "→"
+	}
+
+	missing := make(map[*csvfmt.Row]bool)
+	var missingRows []*csvfmt.Row
+	conflicts := 0
+
+	for _, lang := range languages {
+		catalog, ok := d.catalogs[lang]
+		if !ok {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Warning: no %s catalog for column %s, skipped\n", domain, lang)
+			}
+			continue
+		}
+		poFilePath := d.paths[lang]
+
+		result, err := csvfmt.Apply(table, lang, catalog)
+		if err != nil {
+			return fmt.Errorf("failed to apply %s to %s: %w", path, poFilePath, err)
+		}
+		for _, row := range result.Missing {
+			if !missing[row] {
+				missing[row] = true
+				missingRows = append(missingRows, row)
+			}
+		}
+		conflicts += len(result.Conflicts)
+
+		if len(result.Updated) > 0 && !importFlags.dryRun {
+			if err := catalog.WriteFile(poFilePath); err != nil {
+				return fmt.Errorf("failed to write %s: %w", poFilePath, err)
+			}
+		}
+
+		if quiet {
+			continue
+		}
+		fmt.Printf("Updated %d translation(s) in %s:\n", len(result.Updated), poFilePath)
+		for _, key := range result.Updated {
+			fmt.Printf("  %s %s\n", marker, key)
+		}
+		for _, row := range result.Conflicts {
+			fmt.Printf("  ! %s (line %d): changed in both the spreadsheet and the .po file since export, skipped\n", row.Describe(), row.Line)
+		}
+		fmt.Println()
+	}
+
+	if !quiet && len(missingRows) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d row(s) match no entry (msgid changed or removed since export):\n", len(missingRows))
+		for _, row := range missingRows {
+			fmt.Fprintf(os.Stderr, "  - line %d: %s\n", row.Line, row.Describe())
+		}
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("%d conflict(s) not applied; resolve them in the .po files or re-export", conflicts)
+	}
+	return nil
+}
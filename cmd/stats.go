@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/stats"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var statsFlags struct {
+	language string
+	byFile   bool
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [po-file...]",
+	Short: "Show translation progress per language",
+	Long: `Show translation progress for every language in the gettext directory
+(or the files given, or the one for --language).
+
+For each language, entries are counted as translated, fuzzy or untranslated
+(plural entries with any empty form are untranslated), along with obsolete
+entries, the words in their msgids (placeholders like %{name} and %d are not
+words) and the percentage of entries translated. Catalogs of several domains
+add up per language.
+
+With --by-file, entries are grouped by the source files in their #:
+references instead, least translated first, to see which parts of the code
+lag behind. An entry referenced from several files counts for each.
+
+Output is a table, or one JSON object per line with --json.
+
+Examples:
+  poflow stats
+  poflow stats --language sv --by-file
+  poflow stats --json`,
+	SilenceUsage: true,
+	RunE:         runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringVar(&statsFlags.language, "language", "", "language code (uses config to resolve path)")
+	statsCmd.Flags().BoolVar(&statsFlags.byFile, "by-file", false, "group by the source files of #: references")
+}
+
+// statsRow is a line of stats output, also used for --json
+type statsRow struct {
+	Language string `json:"language"`
+	File     string `json:"file,omitempty"`
+	*stats.Counts
+	Percent float64 `json:"percent"`
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	files, err := catalogFiles(statsFlags.language, args)
+	if err != nil {
+		return err
+	}
+
+	var languages []string
+	totals := make(map[string]*stats.Counts)
+	byFile := make(map[string]map[string]*stats.Counts)
+
+	for _, filePath := range files {
+		catalog, err := po.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		lang := catalogLanguage(catalog, filePath)
+		if lang == "" {
+			lang = filePath
+		}
+		if totals[lang] == nil {
+			languages = append(languages, lang)
+			totals[lang] = &stats.Counts{}
+			byFile[lang] = make(map[string]*stats.Counts)
+		}
+
+		totals[lang].Merge(stats.Count(catalog.Entries()))
+		if statsFlags.byFile {
+			for path, counts := range stats.ByFile(catalog.Entries()) {
+				if byFile[lang][path] == nil {
+					byFile[lang][path] = &stats.Counts{}
+				}
+				byFile[lang][path].Merge(counts)
+			}
+		}
+	}
+	slices.Sort(languages)
+
+	var rows []statsRow
+	for _, lang := range languages {
+		if !statsFlags.byFile {
+			rows = append(rows, newStatsRow(lang, "", totals[lang]))
+			continue
+		}
+
+		var fileRows []statsRow
+		for path, counts := range byFile[lang] {
+			fileRows = append(fileRows, newStatsRow(lang, path, counts))
+		}
+		slices.SortFunc(fileRows, func(a, b statsRow) int {
+			return cmp.Or(cmp.Compare(a.Percent, b.Percent), cmp.Compare(a.File, b.File))
+		})
+		rows = append(rows, fileRows...)
+	}
+
+	if jsonOutput {
+		for _, row := range rows {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "LANGUAGE\tTOTAL\tTRANSLATED\tFUZZY\tUNTRANSLATED\tOBSOLETE\tWORDS\tDONE"
+	if statsFlags.byFile {
+		header = "LANGUAGE\tFILE\tTOTAL\tTRANSLATED\tFUZZY\tUNTRANSLATED\tOBSOLETE\tWORDS\tDONE"
+	}
+	fmt.Fprintln(w, header)
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t", row.Language)
+		if statsFlags.byFile {
+			fmt.Fprintf(w, "%s\t", row.File)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d/%d\t%.1f%%\n",
+			row.Total, row.Translated, row.Fuzzy, row.Untranslated, row.Obsolete,
+			row.TranslatedWords, row.Words, row.Percent)
+	}
+	return w.Flush()
+}
+
+// newStatsRow returns the output row for counts
+func newStatsRow(lang, file string, counts *stats.Counts) statsRow {
+	// Round down, so that a catalog is only at 100% when it is complete
+	percent := math.Floor(counts.Percent()*10) / 10
+	return statsRow{Language: lang, File: file, Counts: counts, Percent: percent}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/exchange/tmx"
+	"github.com/xnilsson/poflow/internal/parser"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var tmFlags struct {
+	format         string
+	output         string
+	sourceLanguage string
+	language       string
+	dryRun         bool
+	fuzzy          bool
+}
+
+var tmCmd = &cobra.Command{
+	Use:   "tm",
+	Short: "Export and reuse translation memories",
+	Long: `Export the translations of every catalog as a translation memory, or
+pre-fill untranslated entries from one.
+
+Subcommands:
+  export  Write a multilingual TMX 1.4 file from all catalogs
+  import  Fill empty msgstrs with exact matches from a TMX file`,
+}
+
+var tmExportCmd = &cobra.Command{
+	Use:   "export [po-file...]",
+	Short: "Export all catalogs as a TMX translation memory",
+	Long: `Export the translations of every .po file in the gettext directory as
+a single multilingual TMX 1.4 file, for CAT tools and other projects.
+
+Each msgid (with its msgctxt) becomes a translation unit with a variant per
+language it is translated into. The msgctxt is kept in an x-context prop;
+plural entries get a unit per plural form, with the form index in an
+x-plural-form prop and the msgid_plural as the source of forms 1 and up.
+Untranslated and fuzzy translations are left out.
+
+The language of each catalog is taken from its Language header, or from its
+{lang}/LC_MESSAGES path.
+
+Examples:
+  poflow tm export
+  poflow tm export --output memory.tmx --source-language en`,
+	SilenceUsage: true,
+	RunE:         runTMExport,
+}
+
+var tmImportCmd = &cobra.Command{
+	Use:   "import [tmx-file] [po-file...]",
+	Short: "Pre-fill empty translations from a TMX file",
+	Long: `Fill the empty msgstrs of every .po file in the gettext directory (or
+the files given, or the one for --language) with exact matches from a TMX
+translation memory.
+
+A match needs the same msgid, msgctxt (the x-context prop) and plural form
+(the x-plural-form prop) as written by 'poflow tm export'; units from other
+tools without props match entries without a context. The source of a unit is
+its srclang segment, or its first one with srclang="*all*". Existing
+translations are never changed. Use --fuzzy to flag filled entries for review.
+
+Languages match regardless of case and separator (pt_BR, pt-br); without a
+translation in the catalog's own language, one in a variant of it is used
+(de-DE for a de catalog, or pt for pt_BR).
+
+Examples:
+  poflow tm import memory.tmx
+  poflow tm import memory.tmx --language sv --dry-run
+  poflow tm import vendor.tmx --fuzzy`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runTMImport,
+}
+
+func init() {
+	rootCmd.AddCommand(tmCmd)
+	tmCmd.AddCommand(tmExportCmd)
+	tmCmd.AddCommand(tmImportCmd)
+
+	tmExportCmd.Flags().StringVar(&tmFlags.format, "format", "tmx", "translation memory format (tmx)")
+	tmExportCmd.Flags().StringVarP(&tmFlags.output, "output", "o", "poflow.tmx", "file to write the translation memory to")
+	tmExportCmd.Flags().StringVar(&tmFlags.sourceLanguage, "source-language", "en", "language of the msgids")
+
+	tmImportCmd.Flags().StringVar(&tmFlags.language, "language", "", "language code (uses config to resolve path)")
+	tmImportCmd.Flags().BoolVar(&tmFlags.dryRun, "dry-run", false, "show what would be filled without modifying files")
+	tmImportCmd.Flags().BoolVar(&tmFlags.fuzzy, "fuzzy", false, "mark filled entries as fuzzy")
+}
+
+func runTMExport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	if tmFlags.format != "tmx" {
+		return fmt.Errorf("unknown translation memory format %q (supported: tmx)", tmFlags.format)
+	}
+
+	files, err := catalogFiles("", args)
+	if err != nil {
+		return err
+	}
+
+	memory := tmx.NewMemory(tmFlags.sourceLanguage)
+	for _, filePath := range files {
+		catalog, err := po.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		lang := catalogLanguage(catalog, filePath)
+		if lang == "" {
+			return fmt.Errorf("cannot determine the language of %s (set the Language header)", filePath)
+		}
+		memory.Add(catalog, lang)
+	}
+
+	if dir := filepath.Dir(tmFlags.output); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	file, err := os.Create(tmFlags.output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmFlags.output, err)
+	}
+	if err := tmx.Write(file, memory, Version); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", tmFlags.output, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmFlags.output, err)
+	}
+
+	if !quiet {
+		units := 0
+		for _, tu := range memory.Units {
+			if len(tu.Translations) > 0 {
+				units++
+			}
+		}
+		fmt.Printf("  ✓ %s (%d units, %d languages)\n", tmFlags.output, units, len(memory.Languages()))
+		fmt.Fprintf(os.Stderr, "\nExported %d file(s)\n", len(files))
+	}
+	return nil
+}
+
+func runTMImport(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", args[0], err)
+	}
+	memory, err := tmx.Read(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	files, err := catalogFiles(tmFlags.language, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if tmFlags.dryRun && !quiet {
+		fmt.Printf("DRY RUN - No files will be modified\n\n")
+	}
+
+	marker := "✓"
+	if tmFlags.dryRun {
+		marker = "→"
+	}
+
+	// Filled entries per language, in the order languages are first seen
+	var languages []string
+	filledByLanguage := make(map[string]int)
+
+	for _, filePath := range files {
+		catalog, err := po.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		if catalog.IsMO() {
+			return fmt.Errorf("%s is a compiled .mo file and cannot be updated", filePath)
+		}
+		lang := catalogLanguage(catalog, filePath)
+		if lang == "" {
+			return fmt.Errorf("cannot determine the language of %s (set the Language header)", filePath)
+		}
+
+		filled := memory.Fill(catalog, lang, tmFlags.fuzzy)
+		if len(filled) > 0 && !tmFlags.dryRun {
+			if err := catalog.WriteFile(filePath); err != nil {
+				return fmt.Errorf("failed to write %s: %w", filePath, err)
+			}
+		}
+
+		if _, ok := filledByLanguage[lang]; !ok {
+			languages = append(languages, lang)
+		}
+		filledByLanguage[lang] += len(filled)
+
+		if !quiet && len(filled) > 0 {
+			fmt.Printf("Filled %d entry(ies) in %s:\n", len(filled), filePath)
+			for _, entry := range filled {
+				fmt.Printf("  %s %s\n", marker, parser.DisplayKey(entry.Key()))
+			}
+			fmt.Println()
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("Filled from %s:\n", args[0])
+		for _, lang := range languages {
+			fmt.Printf("  %s: %d\n", lang, filledByLanguage[lang])
+		}
+	}
+	return nil
+}
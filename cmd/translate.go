@@ -1,13 +1,14 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/xnilsson/poflow/internal/config"
 	"github.com/xnilsson/poflow/internal/output"
 	"github.com/xnilsson/poflow/internal/parser"
+	"github.com/xnilsson/poflow/pkg/po"
 	"github.com/spf13/cobra"
 )
 
@@ -25,11 +26,20 @@ var translateCmd = &cobra.Command{
 
 Translation input format (one per line):
   msgid = msgstr
+  msgctxt::msgid = msgstr    (for entries with a context)
+  msgid[N] = msgstr          (for plural form msgstr[N], N < nplurals)
+
+A backslash escapes ":", "[" and "=" in the msgctxt and msgid, e.g.
+"Item\[1] = Objekt[1]" for a msgid that ends in "[1]".
 
 Examples:
   Sign In = Logga in
   Sign Out = Logga ut
   Welcome = Välkommen
+  button::Open = Öppna
+  state::Open = Öppen
+  %d file[0] = %d fil
+  %d file[1] = %d filer
 
 BEHAVIOR:
 
@@ -142,7 +152,7 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse translations
-	translations, err := parser.ParseTranslations(translationInput)
+	translations, err := parser.ParseTranslationSet(translationInput)
 	if err != nil {
 		return fmt.Errorf("failed to parse translations: %w", err)
 	}
@@ -159,19 +169,19 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 	defer poFile.Close()
 
 	// Parse and merge
-	p := parser.NewParser(poFile)
+	p := po.NewReader(poFile)
+	if p.IsMO() && !translateFlags.stdout {
+		return fmt.Errorf("%s is a compiled .mo file and cannot be updated in place (use --stdout)", poFilePath)
+	}
 	notFound := []string{}
 	updated := 0
 	updatedMsgIDs := []string{}
 
 	// Determine output destination
-	var outputWriter *bufio.Writer
+	var destination io.Writer = os.Stdout
 	var tempFile *os.File
 
-	if translateFlags.stdout {
-		// Output to stdout
-		outputWriter = bufio.NewWriter(os.Stdout)
-	} else {
+	if !translateFlags.stdout {
 		// Write to temp file for in-place update
 		var err error
 		tempFile, err = os.CreateTemp("", "poflow-*.po")
@@ -179,7 +189,13 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to create temp file: %w", err)
 		}
 		defer os.Remove(tempFile.Name())
-		outputWriter = bufio.NewWriter(tempFile)
+		destination = tempFile
+	}
+
+	// Re-encode .po output in the catalog's declared charset
+	outputWriter, err := po.NewWriter(destination, p.Charset())
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
 	}
 
 	// Write file header first
@@ -193,19 +209,20 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 
 		// Write header before first entry
 		if !headerWritten {
-			header := p.Header()
-			for _, line := range header {
-				outputWriter.WriteString(line + "\n")
+			if err := outputWriter.WriteLines(p.HeaderLines()); err != nil {
+				return fmt.Errorf("failed to write header: %w", err)
 			}
 			headerWritten = true
 		}
 
-		// Check if we have a translation for this msgid
-		if newMsgStr, ok := translations[entry.MsgID]; ok {
-			entry.MsgStr = newMsgStr
+		// Check if we have a translation for this (msgctxt, msgid); obsolete entries are left alone
+		merged, err := parser.Merge(entry, translations, p.Header().NPlurals())
+		if err != nil {
+			return fmt.Errorf("invalid translation input: %w", err)
+		}
+		if merged {
 			updated++
-			updatedMsgIDs = append(updatedMsgIDs, entry.MsgID)
-			delete(translations, entry.MsgID) // Mark as found
+			updatedMsgIDs = append(updatedMsgIDs, parser.DisplayKey(entry.Key()))
 		}
 
 		// Output the entry (possibly updated)
@@ -215,7 +232,7 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 			}
 		} else {
 			// Write as .po format
-			if _, err := outputWriter.WriteString(output.FormatEntry(entry)); err != nil {
+			if err := outputWriter.WriteEntry(entry); err != nil {
 				return fmt.Errorf("failed to write entry: %w", err)
 			}
 		}
@@ -226,7 +243,7 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Flush output
-	if err := outputWriter.Flush(); err != nil {
+	if err := outputWriter.Close(); err != nil {
 		return fmt.Errorf("failed to flush output: %w", err)
 	}
 
@@ -252,8 +269,8 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 
 	// Check for unfound translations
 	if len(translations) > 0 {
-		for msgid := range translations {
-			notFound = append(notFound, msgid)
+		for key := range translations {
+			notFound = append(notFound, parser.DisplayKey(key))
 		}
 
 		if !quiet {
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/xnilsson/poflow/internal/config"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+var validateFlags struct {
+	language string
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check catalogs for syntax errors",
+	Long: `Parse catalogs in strict mode and report syntax errors with file, line
+and column, e.g.:
+
+  priv/gettext/sv/LC_MESSAGES/default.po:42:8: unterminated string
+
+Checks include msgstr without a preceding msgid, unterminated quotes,
+continuation lines outside any field, and duplicate (msgctxt, msgid) pairs.
+
+Without a file or --language, every .po file in the gettext directory and
+the default.pot template (if present) are checked.
+
+Exits with a non-zero status if any error is found.
+
+Examples:
+  # Validate all catalogs (e.g. in CI)
+  poflow validate
+
+  # Validate one language
+  poflow validate --language sv
+
+  # Validate specific files, JSON diagnostics (one per line)
+  poflow validate file.po other.po --json`,
+	RunE:         runValidate,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&validateFlags.language, "language", "", "language code (uses config to resolve path)")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	// Determine which files to check
+	files := args
+	if len(files) == 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if validateFlags.language != "" {
+			path, err := cfg.ResolvePOPath(validateFlags.language)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+			files = []string{path}
+		} else {
+			files, err = cfg.GetAllPOFiles()
+			if err != nil {
+				return fmt.Errorf("failed to find .po files: %w", err)
+			}
+			if potPath, err := cfg.GetPOTFile(); err == nil {
+				files = append(files, potPath)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no .po files found in gettext directory")
+	}
+
+	totalErrors := 0
+	failedFiles := 0
+
+	for _, filePath := range files {
+		errs, err := validateFile(filePath)
+		if err != nil {
+			// Unreadable files count as failures too
+			errs = append(errs, &po.SyntaxError{File: filePath, Msg: err.Error()})
+		}
+
+		for _, syntaxErr := range errs {
+			if jsonOutput {
+				data, err := json.Marshal(syntaxErr)
+				if err != nil {
+					return fmt.Errorf("failed to marshal JSON: %w", err)
+				}
+				fmt.Println(string(data))
+			} else {
+				fmt.Println(syntaxErr)
+			}
+		}
+
+		if len(errs) > 0 {
+			failedFiles++
+			totalErrors += len(errs)
+		}
+	}
+
+	if totalErrors > 0 {
+		return fmt.Errorf("validation failed: %d error(s) in %d of %d file(s)", totalErrors, failedFiles, len(files))
+	}
+
+	if !quiet && !jsonOutput {
+		fmt.Fprintf(os.Stderr, "✓ %d file(s) valid\n", len(files))
+	}
+	return nil
+}
+
+// validateFile parses a single file in strict mode and returns its syntax
+// errors, and the error that stopped parsing early, if any, along with them
+func validateFile(filePath string) ([]*po.SyntaxError, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	p := po.NewReader(file)
+	p.SetStrict(true)
+	p.SetFilename(filePath)
+	for p.Next() != nil {
+	}
+
+	// Errors other than syntax errors (e.g. an unsupported charset) stop
+	// parsing, so the syntax errors found before are not all there is
+	errs := p.Errors()
+	var syntaxErr *po.SyntaxError
+	if err := p.Err(); err != nil && !errors.As(err, &syntaxErr) {
+		return errs, err
+	}
+	return errs, nil
+}
@@ -0,0 +1,86 @@
+package charset
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+)
+
+// DetectLimit is how many bytes from the start of a .po file are searched for the charset
+const DetectLimit = 64 * 1024
+
+// contentTypeCharset matches the charset in a "Content-Type: ...; charset=X" header line
+var contentTypeCharset = regexp.MustCompile(`Content-Type:[^"\\]*charset\s*=\s*([A-Za-z0-9_.:\-]+)`)
+
+// Detect returns the charset declared in the Content-Type header found in head
+// (the first bytes of a .po file), or "" if none is declared
+func Detect(head []byte) string {
+	m := contentTypeCharset.FindSubmatch(head)
+	if m == nil {
+		return ""
+	}
+	return string(m[1])
+}
+
+// IsUTF8 returns true if the charset needs no transcoding: UTF-8, ASCII,
+// the template placeholder "CHARSET" or no charset at all
+func IsUTF8(name string) bool {
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8", "ascii", "us-ascii", "charset":
+		return true
+	}
+	return false
+}
+
+// Lookup returns the encoding for a charset name (IANA names first, then WHATWG labels such as CP1252).
+// It returns nil for charsets that need no transcoding.
+func Lookup(name string) (encoding.Encoding, error) {
+	if IsUTF8(name) {
+		return nil, nil
+	}
+	if enc, err := ianaindex.IANA.Encoding(name); err == nil && enc != nil {
+		return enc, nil
+	}
+	if enc, err := htmlindex.Get(name); err == nil {
+		return enc, nil
+	}
+	return nil, fmt.Errorf("unsupported charset: %s", name)
+}
+
+// NewReader returns a reader that decodes r from the given charset to UTF-8
+func NewReader(r io.Reader, name string) (io.Reader, error) {
+	enc, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return r, nil
+	}
+	return transform.NewReader(r, enc.NewDecoder()), nil
+}
+
+// nopCloser adds a no-op Close to a writer that needs no flushing
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// NewWriter returns a writer that encodes UTF-8 text written to it into the given charset.
+// Close must be called to flush the last bytes; it does not close w.
+func NewWriter(w io.Writer, name string) (io.WriteCloser, error) {
+	enc, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return nopCloser{w}, nil
+	}
+	return transform.NewWriter(w, enc.NewEncoder()), nil
+}
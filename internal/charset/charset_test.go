@@ -0,0 +1,92 @@
+package charset
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{`"Content-Type: text/plain; charset=UTF-8\n"`, "UTF-8"},
+		{`"Content-Type: text/plain; charset=ISO-8859-1\n"`, "ISO-8859-1"},
+		{`"Content-Type: text/plain; charset=CP1252\n"`, "CP1252"},
+		{`"Language: sv\n"`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Detect([]byte(tt.head)); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"ISO-8859-1", "iso-8859-15", "CP1252", "windows-1252"} {
+		enc, err := Lookup(name)
+		if err != nil || enc == nil {
+			t.Errorf("Lookup(%q) = %v, %v; want an encoding", name, enc, err)
+		}
+	}
+
+	for _, name := range []string{"", "UTF-8", "CHARSET"} {
+		enc, err := Lookup(name)
+		if err != nil || enc != nil {
+			t.Errorf("Lookup(%q) = %v, %v; want no transcoding", name, enc, err)
+		}
+	}
+
+	if _, err := Lookup("no-such-charset"); err == nil {
+		t.Error("Expected error for unsupported charset")
+	}
+}
+
+func TestRoundTrip_Latin1(t *testing.T) {
+	latin1 := []byte("V\xe4lkommen, \xd6ppna")
+
+	r, err := NewReader(bytes.NewReader(latin1), "ISO-8859-1")
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Decoding failed: %v", err)
+	}
+	if string(decoded) != "Välkommen, Öppna" {
+		t.Errorf("Expected UTF-8 text, got %q", decoded)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "ISO-8859-1")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if _, err := io.Copy(w, strings.NewReader(string(decoded))); err != nil {
+		t.Fatalf("Encoding failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), latin1) {
+		t.Errorf("Expected original bytes %q, got %q", latin1, buf.Bytes())
+	}
+}
+
+func TestNewWriter_Unencodable(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "ISO-8859-1")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	_, err = io.WriteString(w, "日本語")
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		t.Error("Expected error writing characters outside ISO-8859-1")
+	}
+}
@@ -0,0 +1,177 @@
+// Package check finds problems that msgfmt -c reports before compiling a
+// catalog: format strings that do not match between msgid and msgstr,
+// mismatched leading or trailing newlines, plural forms that disagree with
+// the header, and header fields that are missing or still hold their
+// template values.
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// Options controls which entries are checked
+type Options struct {
+	UseFuzzy bool // Also check fuzzy entries (they are compiled with --use-fuzzy)
+}
+
+// requiredHeaderFields are the header fields msgfmt --check-header expects,
+// with the placeholder values xgettext writes into new templates
+var requiredHeaderFields = []struct {
+	key     string
+	initial string
+}{
+	{"Project-Id-Version", "PACKAGE VERSION"},
+	{"PO-Revision-Date", "YEAR-MO-DA HO:MI+ZONE"},
+	{"Last-Translator", "FULL NAME <EMAIL@ADDRESS>"},
+	{"Language-Team", "LANGUAGE <LL@li.org>"},
+	{"MIME-Version", ""},
+	{"Content-Type", "text/plain; charset=CHARSET"},
+	{"Content-Transfer-Encoding", ""},
+}
+
+// Catalog checks every entry that would be compiled, and the header, and
+// returns the problems found with the line of the entry they concern
+func Catalog(c *po.Catalog, opts Options) []*po.SyntaxError {
+	var problems []*po.SyntaxError
+	report := func(entry *model.MsgEntry, messages []string) {
+		for _, msg := range messages {
+			problem := &po.SyntaxError{Msg: msg}
+			if entry != nil && entry.Position != nil {
+				problem.File = entry.Position.File
+				problem.Line = entry.Position.StartLine
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	headerEntry := c.HeaderEntry()
+	report(headerEntry, Header(c.Header(), headerEntry != nil, c.Entries()))
+
+	nplurals := c.Header().NPlurals()
+	for _, entry := range c.Entries() {
+		if entry.Obsolete || (entry.IsFuzzy() && !opts.UseFuzzy) {
+			continue
+		}
+		messages := Entry(entry)
+		if entry.IsPlural() && nplurals > 0 && len(entry.MsgStrPlural) != nplurals {
+			messages = append(messages, fmt.Sprintf("plural entry has %d msgstr forms, but the header's Plural-Forms says nplurals=%d",
+				len(entry.MsgStrPlural), nplurals))
+		}
+		report(entry, messages)
+	}
+
+	// Problems without a file name still belong to the catalog
+	for _, problem := range problems {
+		if problem.File == "" && problem.Line == 0 {
+			problem.File = catalogFile(c)
+		}
+	}
+	return problems
+}
+
+// catalogFile returns the path the catalog was read from, if known
+func catalogFile(c *po.Catalog) string {
+	for _, entry := range c.Entries() {
+		if entry.Position != nil {
+			return entry.Position.File
+		}
+	}
+	return ""
+}
+
+// Header checks the header fields like msgfmt --check-header. hasEntry tells
+// whether the catalog has a header entry at all.
+func Header(header *model.Header, hasEntry bool, entries []*model.MsgEntry) []string {
+	if !hasEntry {
+		return []string{"missing header entry (msgid \"\")"}
+	}
+
+	var problems []string
+	for _, field := range requiredHeaderFields {
+		if !header.Has(field.key) {
+			problems = append(problems, fmt.Sprintf("header field %s is missing", field.key))
+		} else if field.initial != "" && header.Get(field.key) == field.initial {
+			problems = append(problems, fmt.Sprintf("header field %s still has the initial value %q", field.key, field.initial))
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.IsPlural() && !entry.Obsolete {
+			if header.NPlurals() == 0 {
+				problems = append(problems, "header has no valid Plural-Forms field, but the catalog has plural entries")
+			}
+			break
+		}
+	}
+	return problems
+}
+
+// Entry checks the translations of a single entry against its msgid: format
+// strings in the languages named by its flags, and leading and trailing
+// newlines. Untranslated forms are not checked.
+func Entry(entry *model.MsgEntry) []string {
+	var problems []string
+
+	// Source strings, and the field name and text of each translation
+	type translation struct{ field, text string }
+	var translations []translation
+	if entry.IsPlural() {
+		for i, msgstr := range entry.MsgStrPlural {
+			translations = append(translations, translation{fmt.Sprintf("msgstr[%d]", i), msgstr})
+		}
+	} else {
+		translations = append(translations, translation{"msgstr", entry.MsgStr})
+	}
+
+	for _, t := range translations {
+		if t.text == "" {
+			continue
+		}
+		if strings.HasPrefix(entry.MsgID, "\n") != strings.HasPrefix(t.text, "\n") {
+			problems = append(problems, fmt.Sprintf("msgid and %s do not both begin with \\n", t.field))
+		}
+		if strings.HasSuffix(entry.MsgID, "\n") != strings.HasSuffix(t.text, "\n") {
+			problems = append(problems, fmt.Sprintf("msgid and %s do not both end with \\n", t.field))
+		}
+	}
+
+	for _, flag := range entry.Flags {
+		parse, ok := formatParsers[flag]
+		if !ok {
+			continue
+		}
+
+		source, err := parse(entry.MsgID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("msgid is not a valid %s string: %v", flag, err))
+			continue
+		}
+		if entry.IsPlural() {
+			plural, err := parse(entry.MsgIDPlural)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("msgid_plural is not a valid %s string: %v", flag, err))
+				continue
+			}
+			for key, kind := range plural {
+				source[key] = kind
+			}
+		}
+
+		for _, t := range translations {
+			if t.text == "" {
+				continue
+			}
+			target, err := parse(t.text)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s is not a valid %s string, unlike msgid: %v", t.field, flag, err))
+				continue
+			}
+			problems = append(problems, compareDirectives(source, target, t.field, entry.IsPlural())...)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,156 @@
+package check
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/testutil"
+)
+
+func TestEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    model.MsgEntry
+		expected []string
+	}{
+		{
+			name:  "matching c-format",
+			entry: model.MsgEntry{MsgID: "%s has %d items", MsgStr: "%s har %d saker", Flags: []string{"c-format"}},
+		},
+		{
+			name:  "reordered positional c-format",
+			entry: model.MsgEntry{MsgID: "%s has %d items", MsgStr: "%2$d saker i %1$s", Flags: []string{"c-format"}},
+		},
+		{
+			name:     "swapped c-format types",
+			entry:    model.MsgEntry{MsgID: "%s has %d items", MsgStr: "%d har %s saker", Flags: []string{"c-format"}},
+			expected: []string{"msgstr uses argument 1 as integer, but msgid uses it as string", "msgstr uses argument 2 as string, but msgid uses it as integer"},
+		},
+		{
+			name:     "missing c-format argument",
+			entry:    model.MsgEntry{MsgID: "%d items", MsgStr: "saker", Flags: []string{"c-format"}},
+			expected: []string{"msgstr is missing argument 1 from msgid"},
+		},
+		{
+			name:     "invalid c-format in msgstr",
+			entry:    model.MsgEntry{MsgID: "100%% done", MsgStr: "100% klart", Flags: []string{"c-format"}},
+			expected: []string{`msgstr is not a valid c-format string, unlike msgid: invalid conversion specifier 'k'`},
+		},
+		{
+			name:  "sh-format percent is literal",
+			entry: model.MsgEntry{MsgID: "100% of $FILES copied", MsgStr: "100% av $FILES kopierade", Flags: []string{"sh-format"}},
+		},
+		{
+			name:     "sh-format variable renamed",
+			entry:    model.MsgEntry{MsgID: "Hello ${USER}, costs $5", MsgStr: "Hej $ANVANDARE, kostar $5", Flags: []string{"sh-format"}},
+			expected: []string{"msgstr is missing argument 'USER' from msgid", "msgstr has argument 'ANVANDARE', which does not exist in msgid"},
+		},
+		{
+			name:  "format not checked without flag",
+			entry: model.MsgEntry{MsgID: "%d items", MsgStr: "saker"},
+		},
+		{
+			name:     "elixir interpolation renamed",
+			entry:    model.MsgEntry{MsgID: "Hi %{name}", MsgStr: "Hej %{namn}", Flags: []string{"elixir-format"}},
+			expected: []string{"msgstr is missing argument 'name' from msgid", "msgstr has argument 'namn', which does not exist in msgid"},
+		},
+		{
+			name:     "python named format",
+			entry:    model.MsgEntry{MsgID: "%(count)d of %(total)d", MsgStr: "%(count)d av %(totalt)d", Flags: []string{"python-format"}},
+			expected: []string{"msgstr is missing argument 'total' from msgid", "msgstr has argument 'totalt', which does not exist in msgid"},
+		},
+		{
+			name:  "python brace format with escapes",
+			entry: model.MsgEntry{MsgID: "{{literal}} {name!r:>10}", MsgStr: "{{bokstavligt}} {name}", Flags: []string{"python-brace-format"}},
+		},
+		{
+			name: "plural forms may leave out the number",
+			entry: model.MsgEntry{
+				MsgID: "One file", MsgIDPlural: "%{count} files",
+				MsgStrPlural: []string{"En fil", "%{count} filer"},
+				Flags:        []string{"elixir-format"},
+			},
+		},
+		{
+			name: "plural form with unknown argument",
+			entry: model.MsgEntry{
+				MsgID: "One file", MsgIDPlural: "%{count} files",
+				MsgStrPlural: []string{"En fil", "%{antal} filer"},
+				Flags:        []string{"elixir-format"},
+			},
+			expected: []string{"msgstr[1] has argument 'antal', which does not exist in msgid"},
+		},
+		{
+			name:     "newline mismatch",
+			entry:    model.MsgEntry{MsgID: "\nHello\n", MsgStr: "Hej"},
+			expected: []string{`msgid and msgstr do not both begin with \n`, `msgid and msgstr do not both end with \n`},
+		},
+		{
+			name:  "untranslated forms are not checked",
+			entry: model.MsgEntry{MsgID: "%d items\n", MsgStr: "", Flags: []string{"c-format"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Entry(&tt.entry); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Entry() = %q\nexpected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	input := `msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"PO-Revision-Date: 2025-01-01 10:00+0100\n"
+"Last-Translator: Nille <nille@example.com>\n"
+"Language-Team: Swedish\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#, c-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] "%d filer"
+msgstr[2] "%d filer"
+
+#, fuzzy, c-format
+msgid "%s"
+msgstr "%d"
+
+#~ msgid "\nGone"
+#~ msgstr "Borta"
+`
+	catalog := testutil.ReadCatalog(t, input)
+
+	var got []string
+	for _, problem := range Catalog(catalog, Options{}) {
+		got = append(got, problem.Error())
+	}
+	expected := []string{
+		`1: header field Project-Id-Version still has the initial value "PACKAGE VERSION"`,
+		"12: plural entry has 3 msgstr forms, but the header's Plural-Forms says nplurals=2",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Catalog() = %q\nexpected %q", got, expected)
+	}
+
+	// Fuzzy entries are checked when they are compiled too
+	if problems := Catalog(catalog, Options{UseFuzzy: true}); len(problems) != 3 {
+		t.Errorf("expected the fuzzy entry to be checked with UseFuzzy, got %d problems", len(problems))
+	}
+}
+
+func TestHeader_Missing(t *testing.T) {
+	got := Header(&model.Header{}, false, nil)
+	if len(got) != 1 || !strings.Contains(got[0], "missing header entry") {
+		t.Errorf("unexpected problems: %q", got)
+	}
+}
@@ -0,0 +1,278 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// directives maps each argument of a format string (a position such as "1"
+// or a name such as "count") to the kind of value it expects
+type directives map[string]string
+
+// formatParser extracts the directives of a format string, or reports why
+// the string is not a valid format string of its language
+type formatParser func(s string) (directives, error)
+
+// formatParsers holds the format string languages checked, by "#," flag
+var formatParsers = map[string]formatParser{
+	"c-format":            parseCFormat,
+	"objc-format":         parseCFormat,
+	"sh-format":           parseShellFormat,
+	"php-format":          parseCFormat,
+	"python-format":       parsePythonFormat,
+	"python-brace-format": parseBraceFormat,
+	"elixir-format":       parseElixirFormat,
+}
+
+// cConversions maps printf conversion characters to the kind of argument they take
+var cConversions = map[byte]string{
+	'd': "integer", 'i': "integer", 'o': "integer", 'u': "integer", 'x': "integer", 'X': "integer", 'c': "integer",
+	'e': "float", 'E': "float", 'f': "float", 'F': "float", 'g': "float", 'G': "float", 'a': "float", 'A': "float",
+	's': "string", 'p': "pointer", 'n': "count",
+}
+
+// parseCFormat parses printf directives: %[argnum$][flags][width][.precision][length]conversion
+func parseCFormat(s string) (directives, error) {
+	result := directives{}
+	next := 1
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(s) && s[i] == '%' {
+			continue
+		}
+
+		// Optional argument number
+		arg := 0
+		j := i
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j > i && j < len(s) && s[j] == '$' {
+			arg, _ = strconv.Atoi(s[i:j])
+			if arg == 0 {
+				return nil, fmt.Errorf("argument number 0 is not valid")
+			}
+			i = j + 1
+		}
+
+		// Flags, width, precision and length modifiers
+		for i < len(s) && strings.IndexByte("-+ #0'123456789.*hlLqjzt", s[i]) >= 0 {
+			i++
+		}
+		if i >= len(s) {
+			return nil, fmt.Errorf("the string ends in the middle of a directive")
+		}
+		kind, ok := cConversions[s[i]]
+		if !ok {
+			return nil, fmt.Errorf("invalid conversion specifier %q", s[i])
+		}
+
+		if arg == 0 {
+			arg = next
+			next++
+		}
+		key := strconv.Itoa(arg)
+		if previous, ok := result[key]; ok && previous != kind {
+			return nil, fmt.Errorf("argument %d is used with different types", arg)
+		}
+		result[key] = kind
+	}
+	return result, nil
+}
+
+// parsePythonFormat parses %-directives, either all named (%(name)s) or all positional (%s)
+func parsePythonFormat(s string) (directives, error) {
+	result := directives{}
+	next := 1
+	named, positional := false, false
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(s) && s[i] == '%' {
+			continue
+		}
+
+		key := ""
+		if i < len(s) && s[i] == '(' {
+			end := strings.IndexByte(s[i:], ')')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated argument name")
+			}
+			key = s[i+1 : i+end]
+			i += end + 1
+			named = true
+		} else {
+			key = strconv.Itoa(next)
+			next++
+			positional = true
+		}
+		if named && positional {
+			return nil, fmt.Errorf("named and positional directives are mixed")
+		}
+
+		for i < len(s) && strings.IndexByte("-+ #0123456789.*hlL", s[i]) >= 0 {
+			i++
+		}
+		if i >= len(s) {
+			return nil, fmt.Errorf("the string ends in the middle of a directive")
+		}
+		if strings.IndexByte("diouxXeEfFgGcrsa", s[i]) < 0 {
+			return nil, fmt.Errorf("invalid conversion specifier %q", s[i])
+		}
+		result[key] = pythonKind(s[i])
+	}
+	return result, nil
+}
+
+// pythonKind maps a Python conversion character to the kind of argument it takes
+func pythonKind(c byte) string {
+	switch {
+	case strings.IndexByte("diouxXc", c) >= 0:
+		return "integer"
+	case strings.IndexByte("eEfFgG", c) >= 0:
+		return "float"
+	}
+	return "string"
+}
+
+// parseBraceFormat parses str.format fields: {name}, {0} or {} with optional
+// conversion and format spec; "{{" and "}}" are literal braces
+func parseBraceFormat(s string) (directives, error) {
+	result := directives{}
+	next := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '}':
+			if i+1 < len(s) && s[i+1] == '}' {
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unmatched '}'")
+		case '{':
+			if i+1 < len(s) && s[i+1] == '{' {
+				i++
+				continue
+			}
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated '{'")
+			}
+			field := s[i+1 : i+end]
+			if cut := strings.IndexAny(field, "!:.["); cut >= 0 {
+				field = field[:cut]
+			}
+			if field == "" {
+				field = strconv.Itoa(next)
+				next++
+			}
+			result[field] = "value"
+			i += end
+		}
+	}
+	return result, nil
+}
+
+// parseElixirFormat parses Gettext for Elixir interpolations: %{name}
+func parseElixirFormat(s string) (directives, error) {
+	result := directives{}
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] != '%' || s[i+1] != '{' {
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated %%{ interpolation")
+		}
+		result[s[i+2:i+end]] = "value"
+		i += end
+	}
+	return result, nil
+}
+
+// parseShellFormat parses shell variable references as envsubst expands
+// them: $name and ${name}. A $ not followed by a name is literal.
+func parseShellFormat(s string) (directives, error) {
+	result := directives{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			continue
+		}
+
+		start, braced := i+1, s[i+1] == '{'
+		if braced {
+			start++
+		}
+		end := start
+		for end < len(s) && (isShellNameStart(s[end]) || end > start && s[end] >= '0' && s[end] <= '9') {
+			end++
+		}
+		if braced {
+			if end == start || end >= len(s) || s[end] != '}' {
+				return nil, fmt.Errorf("invalid ${...} variable reference")
+			}
+			result[s[start:end]] = "value"
+			i = end
+			continue
+		}
+		if end > start {
+			result[s[start:end]] = "value"
+			i = end - 1
+		}
+	}
+	return result, nil
+}
+
+// isShellNameStart reports whether c can start (or continue) a shell variable name
+func isShellNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// compareDirectives reports the differences between the directives of a
+// source string and a translation. With subset, the translation may leave out
+// arguments (used for plural forms, which need not all mention the number).
+func compareDirectives(source, translation directives, field string, subset bool) []string {
+	var problems []string
+	for _, key := range sortedKeys(source) {
+		kind, ok := translation[key]
+		if !ok {
+			if !subset {
+				problems = append(problems, fmt.Sprintf("%s is missing %s from msgid", field, describeArg(key)))
+			}
+			continue
+		}
+		if kind != source[key] {
+			problems = append(problems, fmt.Sprintf("%s uses %s as %s, but msgid uses it as %s", field, describeArg(key), kind, source[key]))
+		}
+	}
+	for _, key := range sortedKeys(translation) {
+		if _, ok := source[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s has %s, which does not exist in msgid", field, describeArg(key)))
+		}
+	}
+	return problems
+}
+
+// describeArg names an argument in messages
+func describeArg(key string) string {
+	if _, err := strconv.Atoi(key); err == nil {
+		return "argument " + key
+	}
+	return "argument '" + key + "'"
+}
+
+// sortedKeys returns the keys of d in sorted order, for stable messages
+func sortedKeys(d directives) []string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,13 +4,107 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration
 type Config struct {
-	GettextPath string `mapstructure:"gettext_path"`
+	GettextPath string         `mapstructure:"gettext_path"`
+	Export      ExportConfig   `mapstructure:"export"`
+	Extract     ExtractConfig  `mapstructure:"extract"`
+	Coverage    CoverageConfig `mapstructure:"coverage"`
+}
+
+// ExportConfig holds settings for poflow export and import
+type ExportConfig struct {
+	// Keys sets the key strategy (camel, snake, msgid or hash) per format
+	// that generates keys: arb, android and ios
+	Keys map[string]string `mapstructure:"keys"`
+}
+
+// ExtractConfig holds settings for poflow extract
+type ExtractConfig struct {
+	// Sources are glob patterns of the source files to scan, e.g. "lib/**/*.{ex,heex}"
+	Sources []string `mapstructure:"sources"`
+
+	// Keywords are extra translation functions in xgettext --keyword syntax,
+	// e.g. "translate" or "ntranslate:1,2"
+	Keywords []string `mapstructure:"keywords"`
+
+	// CommentTag starts the source comments copied into the template (default "TRANSLATORS:")
+	CommentTag string `mapstructure:"comment_tag"`
+}
+
+// CoverageConfig holds the thresholds for poflow coverage, in percent of
+// entries translated per language. The key "*" applies to languages that are
+// not listed.
+type CoverageConfig struct {
+	// Min fails the check for languages below it, e.g. sv: 98
+	Min map[string]float64 `mapstructure:"min"`
+
+	// Warn only warns for languages below it, e.g. for newly added languages
+	Warn map[string]float64 `mapstructure:"warn"`
+}
+
+// Threshold is the coverage a language is checked against
+type Threshold struct {
+	Percent float64
+	Warn    bool // Only warn when below, rather than fail
+}
+
+// Threshold returns the threshold for a language, or false if it has none.
+// Language codes match case-insensitively, with - and _ alike, and a region
+// falls back to its base language (pt_BR to pt). A language's own threshold
+// takes precedence over "*", and Min over Warn.
+func (c CoverageConfig) Threshold(lang string) (Threshold, bool) {
+	lang = normalizeLanguage(lang)
+	base, _, _ := strings.Cut(lang, "_")
+
+	for _, key := range []string{lang, base, "*"} {
+		if percent, ok := lookupLanguage(c.Min, key); ok {
+			return Threshold{Percent: percent}, true
+		}
+		if percent, ok := lookupLanguage(c.Warn, key); ok {
+			return Threshold{Percent: percent, Warn: true}, true
+		}
+	}
+	return Threshold{}, false
+}
+
+// SetMin sets the minimum for a language ("*" for those not listed),
+// replacing any threshold the config has for it
+func (c *CoverageConfig) SetMin(lang string, percent float64) {
+	lang = normalizeLanguage(lang)
+	for _, m := range []map[string]float64{c.Min, c.Warn} {
+		for key := range m {
+			if normalizeLanguage(key) == lang {
+				delete(m, key)
+			}
+		}
+	}
+	if c.Min == nil {
+		c.Min = make(map[string]float64)
+	}
+	c.Min[lang] = percent
+}
+
+// lookupLanguage looks up a normalized language code in a map keyed by
+// language codes as written in the config
+func lookupLanguage(m map[string]float64, lang string) (float64, bool) {
+	for key, value := range m {
+		if normalizeLanguage(key) == lang {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeLanguage lowercases a language code and uses _ between its parts.
+// Config keys are lowercased when loaded, so codes are compared this way.
+func normalizeLanguage(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(lang), "-", "_")
 }
 
 // Load returns the loaded configuration
@@ -36,6 +130,20 @@ func (c *Config) ResolvePOPath(lang string) (string, error) {
 	return path, nil
 }
 
+// LanguageFromPath returns the language code of a .po file laid out as
+// {lang}/LC_MESSAGES/{domain}.po, or "" if the path does not follow that layout
+func LanguageFromPath(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) != "LC_MESSAGES" {
+		return ""
+	}
+	lang := filepath.Base(filepath.Dir(dir))
+	if lang == "." || lang == string(filepath.Separator) {
+		return ""
+	}
+	return lang
+}
+
 // ResolvePOTPath resolves the .pot template file path
 // Returns: {gettext_path}/default.pot
 func (c *Config) ResolvePOTPath() (string, error) {
@@ -0,0 +1,73 @@
+package config
+
+import "testing"
+
+func TestLanguageFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"priv/gettext/sv/LC_MESSAGES/default.po", "sv"},
+		{"/abs/gettext/pt_BR/LC_MESSAGES/errors.po", "pt_BR"},
+		{"LC_MESSAGES/default.po", ""},
+		{"translations/sv.po", ""},
+		{"default.po", ""},
+	}
+
+	for _, tt := range tests {
+		if got := LanguageFromPath(tt.path); got != tt.expected {
+			t.Errorf("LanguageFromPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestCoverageThreshold(t *testing.T) {
+	cfg := CoverageConfig{
+		Min:  map[string]float64{"sv": 98, "pt_br": 95, "*": 50},
+		Warn: map[string]float64{"fi": 80, "pt": 70, "sv": 99},
+	}
+
+	tests := []struct {
+		lang     string
+		expected Threshold
+	}{
+		{"sv", Threshold{Percent: 98}},
+		{"sv_SE", Threshold{Percent: 98}},
+		{"pt_BR", Threshold{Percent: 95}},
+		{"pt-BR", Threshold{Percent: 95}},
+		{"pt_PT", Threshold{Percent: 70, Warn: true}},
+		{"fi", Threshold{Percent: 80, Warn: true}},
+		{"de", Threshold{Percent: 50}},
+	}
+
+	for _, tt := range tests {
+		got, ok := cfg.Threshold(tt.lang)
+		if !ok || got != tt.expected {
+			t.Errorf("Threshold(%q) = %+v, %v, expected %+v", tt.lang, got, ok, tt.expected)
+		}
+	}
+
+	if got, ok := (CoverageConfig{Min: map[string]float64{"sv": 98}}).Threshold("de"); ok {
+		t.Errorf("Threshold(%q) = %+v, expected none", "de", got)
+	}
+}
+
+func TestCoverageSetMin(t *testing.T) {
+	cfg := CoverageConfig{
+		Min:  map[string]float64{"pt_br": 95},
+		Warn: map[string]float64{"fi": 80},
+	}
+	cfg.SetMin("pt-BR", 90)
+	cfg.SetMin("fi", 85)
+	cfg.SetMin("*", 50)
+
+	for lang, expected := range map[string]float64{"pt_BR": 90, "fi": 85, "de": 50} {
+		got, ok := cfg.Threshold(lang)
+		if !ok || got != (Threshold{Percent: expected}) {
+			t.Errorf("Threshold(%q) = %+v, %v, expected min %g", lang, got, ok, expected)
+		}
+	}
+	if len(cfg.Min) != 3 || len(cfg.Warn) != 0 {
+		t.Errorf("SetMin left stale thresholds: min %v, warn %v", cfg.Min, cfg.Warn)
+	}
+}
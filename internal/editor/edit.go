@@ -1,16 +1,13 @@
 package editor
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
-	"github.com/xnilsson/poflow/internal/model"
-	"github.com/xnilsson/poflow/internal/output"
-	"github.com/xnilsson/poflow/internal/parser"
+	"github.com/xnilsson/poflow/pkg/po"
 )
 
 // UpdateResult tracks what was updated in a file
@@ -21,88 +18,35 @@ type UpdateResult struct {
 	Error        error
 }
 
-// UpdateMsgIDInFile updates msgid in a single .po file
-func UpdateMsgIDInFile(filePath, oldMsgID, newMsgID string, dryRun bool) (*UpdateResult, error) {
+// UpdateMsgIDInFile updates msgid in a single .po file.
+// Only entries matching both msgctxt and oldMsgID are updated (use "" for entries without context).
+func UpdateMsgIDInFile(filePath, msgctxt, oldMsgID, newMsgID string, dryRun bool) (*UpdateResult, error) {
 	result := &UpdateResult{FilePath: filePath}
 
-	// Open and parse file
-	file, err := os.Open(filePath)
+	catalog, err := readCatalog(filePath)
 	if err != nil {
 		result.Error = err
 		return result, err
 	}
-	defer file.Close()
 
-	p := parser.NewParser(file)
-
-	// Track if we found any matches
-	foundMatch := false
-	var updatedEntries []*model.MsgEntry
-
-	// Parse all entries
-	for {
-		entry := p.Next()
-		if entry == nil {
-			break
-		}
-
-		// Check if msgid matches
-		if entry.MsgID == oldMsgID {
-			foundMatch = true
-			result.EntriesFound++
-
-			if !dryRun {
-				// Update msgid (preserve msgstr!)
-				entry.MsgID = newMsgID
-				// Update msgid in RawLines to preserve comment order
-				updateMsgIDInRawLines(entry, newMsgID)
-			}
-		}
-
-		updatedEntries = append(updatedEntries, entry)
-	}
-
-	if err := p.Err(); err != nil {
-		result.Error = err
-		return result, err
-	}
-
-	// If no matches, nothing to do
-	if !foundMatch {
+	// Only live entries match (obsolete entries are left alone)
+	if catalog.Get(msgctxt, oldMsgID) == nil {
 		return result, nil
 	}
+	result.EntriesFound++
 
 	// If dry run, just report what would be changed
 	if dryRun {
 		return result, nil
 	}
 
-	// Write to temp file
-	tempFile, err := os.CreateTemp("", "poflow-edit-*.po")
-	if err != nil {
+	// Update msgid (preserve msgstr!); only the msgid lines are rewritten
+	if err := catalog.Rename(msgctxt, oldMsgID, newMsgID); err != nil {
 		result.Error = err
 		return result, err
 	}
-	tempFileName := tempFile.Name()
-	defer os.Remove(tempFileName)
 
-	writer := bufio.NewWriter(tempFile)
-
-	// Write header
-	for _, line := range p.Header() {
-		writer.WriteString(line + "\n")
-	}
-
-	// Write all entries (updated ones have new msgid)
-	for _, entry := range updatedEntries {
-		writer.WriteString(output.FormatEntry(entry))
-	}
-
-	writer.Flush()
-	tempFile.Close()
-
-	// Replace original file
-	if err := os.Rename(tempFileName, filePath); err != nil {
+	if err := catalog.WriteFile(filePath); err != nil {
 		result.Error = err
 		return result, err
 	}
@@ -111,126 +55,48 @@ func UpdateMsgIDInFile(filePath, oldMsgID, newMsgID string, dryRun bool) (*Updat
 	return result, nil
 }
 
-// updateMsgIDInRawLines updates the msgid in the entry's RawLines while preserving all comments and formatting
-func updateMsgIDInRawLines(entry *model.MsgEntry, newMsgID string) {
-	if len(entry.RawLines) == 0 {
-		return
+// readCatalog reads a catalog to be modified in place; compiled .mo files cannot be
+func readCatalog(filePath string) (*po.Catalog, error) {
+	catalog, err := po.ReadFile(filePath)
+	if err != nil {
+		return nil, err
 	}
-
-	var updatedLines []string
-	inMsgID := false
-
-	for _, line := range entry.RawLines {
-		trimmed := strings.TrimSpace(line)
-
-		// Detect start of msgid
-		if strings.HasPrefix(trimmed, "msgid ") {
-			inMsgID = true
-
-			// Replace msgid line with new value
-			if strings.Contains(newMsgID, "\n") {
-				// Multi-line msgid
-				updatedLines = append(updatedLines, "msgid \"\"")
-				for _, msgLine := range strings.Split(newMsgID, "\n") {
-					updatedLines = append(updatedLines, fmt.Sprintf("\"%s\\n\"", escapeString(msgLine)))
-				}
-			} else {
-				// Single-line msgid
-				updatedLines = append(updatedLines, fmt.Sprintf("msgid \"%s\"", escapeString(newMsgID)))
-			}
-			continue
-		}
-
-		// Detect start of msgstr (end of msgid section)
-		if strings.HasPrefix(trimmed, "msgstr ") {
-			inMsgID = false
-		}
-
-		// Skip continuation lines of msgid (they start with ")
-		if inMsgID && strings.HasPrefix(trimmed, "\"") {
-			continue
-		}
-
-		// Keep all other lines (comments, references, msgstr, etc.)
-		updatedLines = append(updatedLines, line)
+	if catalog.IsMO() {
+		return nil, fmt.Errorf("%s is a compiled .mo file and cannot be modified", filePath)
 	}
-
-	// Update the entry's RawLines
-	entry.RawLines = updatedLines
-}
-
-// escapeString escapes special characters for .po file format
-func escapeString(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	s = strings.ReplaceAll(s, "\t", "\\t")
-	return s
+	return catalog, nil
 }
 
 // UpdateMsgIDInFileWithSources updates msgid in .po file AND in source code files
-func UpdateMsgIDInFileWithSources(filePath, oldMsgID, newMsgID string, dryRun bool, baseDir string) (*UpdateResult, error) {
+func UpdateMsgIDInFileWithSources(filePath, msgctxt, oldMsgID, newMsgID string, dryRun bool, baseDir string) (*UpdateResult, error) {
 	result := &UpdateResult{FilePath: filePath}
 
-	// Open and parse file
-	file, err := os.Open(filePath)
+	catalog, err := readCatalog(filePath)
 	if err != nil {
 		result.Error = err
 		return result, err
 	}
-	defer file.Close()
-
-	p := parser.NewParser(file)
 
-	// Track if we found any matches and collect source file references
-	foundMatch := false
-	var updatedEntries []*model.MsgEntry
-	var sourceFiles []string // Collect all source file references
-
-	// Parse all entries
-	for {
-		entry := p.Next()
-		if entry == nil {
-			break
-		}
-
-		// Check if msgid matches
-		if entry.MsgID == oldMsgID {
-			foundMatch = true
-			result.EntriesFound++
-
-			// Collect source file references from this entry
-			for _, ref := range entry.References {
-				// Parse "file.ex:123" format - can have multiple space-separated refs
-				parts := strings.Fields(ref)
-				for _, part := range parts {
-					// Split by colon to separate file path from line number
-					colonIdx := strings.LastIndex(part, ":")
-					if colonIdx > 0 {
-						filePath := part[:colonIdx]
-						sourceFiles = append(sourceFiles, filePath)
-					}
-				}
-			}
-
-			if !dryRun {
-				// Update msgid (preserve msgstr!)
-				entry.MsgID = newMsgID
-				// Update msgid in RawLines to preserve comment order
-				updateMsgIDInRawLines(entry, newMsgID)
+	// Only live entries match (obsolete entries are left alone)
+	entry := catalog.Get(msgctxt, oldMsgID)
+	if entry == nil {
+		return result, nil
+	}
+	result.EntriesFound++
+
+	// Collect source file references from this entry
+	var sourceFiles []string
+	for _, ref := range entry.References {
+		// Parse "file.ex:123" format - can have multiple space-separated refs
+		parts := strings.Fields(ref)
+		for _, part := range parts {
+			// Split by colon to separate file path from line number
+			colonIdx := strings.LastIndex(part, ":")
+			if colonIdx > 0 {
+				filePath := part[:colonIdx]
+				sourceFiles = append(sourceFiles, filePath)
 			}
 		}
-
-		updatedEntries = append(updatedEntries, entry)
-	}
-
-	if err := p.Err(); err != nil {
-		result.Error = err
-		return result, err
-	}
-
-	// If no matches, nothing to do
-	if !foundMatch {
-		return result, nil
 	}
 
 	// If dry run, just report what would be changed
@@ -238,6 +104,12 @@ func UpdateMsgIDInFileWithSources(filePath, oldMsgID, newMsgID string, dryRun bo
 		return result, nil
 	}
 
+	// Update msgid (preserve msgstr!); only the msgid lines are rewritten
+	if err := catalog.Rename(msgctxt, oldMsgID, newMsgID); err != nil {
+		result.Error = err
+		return result, err
+	}
+
 	// Update source files
 	for _, sourceFile := range sourceFiles {
 		fullPath := sourceFile
@@ -245,39 +117,14 @@ func UpdateMsgIDInFileWithSources(filePath, oldMsgID, newMsgID string, dryRun bo
 			fullPath = filepath.Join(baseDir, sourceFile)
 		}
 
-		if err := updateSourceFile(fullPath, oldMsgID, newMsgID); err != nil {
+		if err := updateSourceFile(fullPath, msgctxt, oldMsgID, newMsgID); err != nil {
 			// Don't fail the whole operation if a source file update fails
 			// Just log and continue
 			fmt.Printf("  Warning: failed to update source file %s: %v\n", sourceFile, err)
 		}
 	}
 
-	// Write to temp file
-	tempFile, err := os.CreateTemp("", "poflow-edit-*.po")
-	if err != nil {
-		result.Error = err
-		return result, err
-	}
-	tempFileName := tempFile.Name()
-	defer os.Remove(tempFileName)
-
-	writer := bufio.NewWriter(tempFile)
-
-	// Write header
-	for _, line := range p.Header() {
-		writer.WriteString(line + "\n")
-	}
-
-	// Write all entries (updated ones have new msgid)
-	for _, entry := range updatedEntries {
-		writer.WriteString(output.FormatEntry(entry))
-	}
-
-	writer.Flush()
-	tempFile.Close()
-
-	// Replace original file
-	if err := os.Rename(tempFileName, filePath); err != nil {
+	if err := catalog.WriteFile(filePath); err != nil {
 		result.Error = err
 		return result, err
 	}
@@ -286,8 +133,13 @@ func UpdateMsgIDInFileWithSources(filePath, oldMsgID, newMsgID string, dryRun bo
 	return result, nil
 }
 
-// updateSourceFile updates a single source file, replacing oldMsgID with newMsgID in gettext calls
-func updateSourceFile(filePath, oldMsgID, newMsgID string) error {
+// updateSourceFile updates a single source file, replacing oldMsgID with newMsgID in gettext calls.
+// With a msgctxt, only msgids passed right after that context (as in
+// pgettext("button", "Open")) are replaced, leaving the same text in other
+// contexts alone. Without one, msgids passed after another string are left
+// alone too, as that string is a context, unless the call takes a domain
+// first (dgettext("errors", "Open")).
+func updateSourceFile(filePath, msgctxt, oldMsgID, newMsgID string) error {
 	// Read the source file
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -306,9 +158,23 @@ func updateSourceFile(filePath, oldMsgID, newMsgID string) error {
 	oldQuoted := `"` + regexp.QuoteMeta(oldMsgID) + `"`
 	newQuoted := `"` + newMsgID + `"`
 
-	// Use regex to find quoted strings
-	re := regexp.MustCompile(oldQuoted)
-	updatedContent := re.ReplaceAllString(contentStr, newQuoted)
+	// With a context, the msgid must be the argument after it. Without one,
+	// the call and a string argument before the msgid are matched as well, to
+	// tell context entries apart.
+	var re *regexp.Regexp
+	if msgctxt != "" {
+		re = regexp.MustCompile(`()("` + regexp.QuoteMeta(msgctxt) + `"\s*,\s*)` + oldQuoted)
+	} else {
+		re = regexp.MustCompile(`(\w*\s*\(\s*)?("(?:[^"\\]|\\.)*"\s*,\s*)?` + oldQuoted)
+	}
+
+	updatedContent := re.ReplaceAllStringFunc(contentStr, func(match string) string {
+		m := re.FindStringSubmatch(match)
+		if msgctxt == "" && m[2] != "" && !isDomainCall(m[1]) {
+			return match
+		}
+		return m[1] + m[2] + newQuoted
+	})
 
 	// If content changed, write it back
 	if updatedContent != contentStr {
@@ -319,3 +185,11 @@ func updateSourceFile(filePath, oldMsgID, newMsgID string) error {
 
 	return nil
 }
+
+// isDomainCall reports whether call (a function name and its opening
+// parenthesis) is a gettext function taking a domain but no context, such
+// as dgettext or dngettext
+func isDomainCall(call string) bool {
+	name := strings.TrimRight(call, "( \t\r\n")
+	return strings.HasPrefix(name, "d") && strings.HasSuffix(name, "gettext") && !strings.Contains(name, "pgettext")
+}
@@ -37,7 +37,7 @@ msgstr "B: Fylligare byst | C: Standard | D: Fylligare höft"
 	oldMsgID := "B: Fuller bust | C: Standard | D: Fuller hip"
 	newMsgID := "B: Athletic | C: Standard | D: Curvy"
 
-	result, err := UpdateMsgIDInFile(testFile, oldMsgID, newMsgID, false)
+	result, err := UpdateMsgIDInFile(testFile, "", oldMsgID, newMsgID, false)
 	if err != nil {
 		t.Fatalf("UpdateMsgIDInFile failed: %v", err)
 	}
@@ -108,7 +108,7 @@ msgstr "Testa"
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	result, err := UpdateMsgIDInFile(testFile, "Test", "Updated", false)
+	result, err := UpdateMsgIDInFile(testFile, "", "Test", "Updated", false)
 	if err != nil {
 		t.Fatalf("UpdateMsgIDInFile failed: %v", err)
 	}
@@ -215,7 +215,7 @@ msgstr "B: Fylligare byst | C: Standard | D: Fylligare höft"
 	oldMsgID := "B: Fuller bust | C: Standard | D: Fuller hip"
 	newMsgID := "B: Athletic | C: Standard | D: Curvy"
 
-	result, err := UpdateMsgIDInFileWithSources(poFile, oldMsgID, newMsgID, false, tmpDir)
+	result, err := UpdateMsgIDInFileWithSources(poFile, "", oldMsgID, newMsgID, false, tmpDir)
 	if err != nil {
 		t.Fatalf("UpdateMsgIDInFileWithSources failed: %v", err)
 	}
@@ -305,7 +305,7 @@ msgstr "Välkommen"
 	}
 
 	// Run the edit command
-	result, err := UpdateMsgIDInFileWithSources(poFile, "Welcome", "Hello", false, tmpDir)
+	result, err := UpdateMsgIDInFileWithSources(poFile, "", "Welcome", "Hello", false, tmpDir)
 	if err != nil {
 		t.Fatalf("UpdateMsgIDInFileWithSources failed: %v", err)
 	}
@@ -328,3 +328,216 @@ msgstr "Välkommen"
 		t.Logf("Content:\n%s", string(updatedSource2))
 	}
 }
+
+// TestUpdateMsgIDInFileWithSources_Context verifies that only source calls
+// with the entry's msgctxt are rewritten
+func TestUpdateMsgIDInFileWithSources_Context(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sourceFile := filepath.Join(tmpDir, "lib", "menu.ex")
+	if err := os.MkdirAll(filepath.Dir(sourceFile), 0755); err != nil {
+		t.Fatalf("Failed to create source directory: %v", err)
+	}
+	sourceContent := `pgettext("button", "Open")
+pgettext("state", "Open")
+gettext("Open")
+`
+	if err := os.WriteFile(sourceFile, []byte(sourceContent), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	poFile := filepath.Join(tmpDir, "default.po")
+	poContent := `#: lib/menu.ex:1
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+#: lib/menu.ex:2
+msgctxt "state"
+msgid "Open"
+msgstr "Öppen"
+`
+	if err := os.WriteFile(poFile, []byte(poContent), 0644); err != nil {
+		t.Fatalf("Failed to create .po file: %v", err)
+	}
+
+	if _, err := UpdateMsgIDInFileWithSources(poFile, "button", "Open", "Launch", false, tmpDir); err != nil {
+		t.Fatalf("UpdateMsgIDInFileWithSources failed: %v", err)
+	}
+
+	updated, _ := os.ReadFile(sourceFile)
+	expected := `pgettext("button", "Launch")
+pgettext("state", "Open")
+gettext("Open")
+`
+	if string(updated) != expected {
+		t.Errorf("unexpected source:\n%s\nexpected:\n%s", updated, expected)
+	}
+}
+
+func TestUpdateMsgIDInFileWithSources_NoContext(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sourceFile := filepath.Join(tmpDir, "lib", "menu.ex")
+	if err := os.MkdirAll(filepath.Dir(sourceFile), 0755); err != nil {
+		t.Fatalf("Failed to create source directory: %v", err)
+	}
+	sourceContent := `gettext("Open")
+pgettext("menu", "Open")
+dgettext("errors", "Open")
+`
+	if err := os.WriteFile(sourceFile, []byte(sourceContent), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	poFile := filepath.Join(tmpDir, "default.po")
+	poContent := `#: lib/menu.ex:1
+msgid "Open"
+msgstr "Öppna"
+
+#: lib/menu.ex:2
+msgctxt "menu"
+msgid "Open"
+msgstr "Öppna"
+`
+	if err := os.WriteFile(poFile, []byte(poContent), 0644); err != nil {
+		t.Fatalf("Failed to create .po file: %v", err)
+	}
+
+	if _, err := UpdateMsgIDInFileWithSources(poFile, "", "Open", "Open now", false, tmpDir); err != nil {
+		t.Fatalf("UpdateMsgIDInFileWithSources failed: %v", err)
+	}
+
+	updated, _ := os.ReadFile(sourceFile)
+	expected := `gettext("Open now")
+pgettext("menu", "Open")
+dgettext("errors", "Open now")
+`
+	if string(updated) != expected {
+		t.Errorf("unexpected source:\n%s\nexpected:\n%s", updated, expected)
+	}
+}
+
+// TestUpdateMsgIDInFile_Context verifies that only the entry with the requested msgctxt is renamed
+func TestUpdateMsgIDInFile_Context(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.po")
+
+	originalContent := `msgid ""
+msgstr ""
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgid "Open"
+msgstr "Öppen"
+
+`
+
+	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := UpdateMsgIDInFile(testFile, "button", "Open", "Launch", false)
+	if err != nil {
+		t.Fatalf("UpdateMsgIDInFile failed: %v", err)
+	}
+
+	if result.EntriesFound != 1 {
+		t.Errorf("Expected 1 entry found, got %d", result.EntriesFound)
+	}
+
+	updatedContent, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+
+	updatedStr := string(updatedContent)
+
+	if !strings.Contains(updatedStr, "msgctxt \"button\"\nmsgid \"Launch\"\nmsgstr \"Öppna\"") {
+		t.Errorf("Entry with context was not updated")
+		t.Logf("Updated content:\n%s", updatedStr)
+	}
+
+	if !strings.Contains(updatedStr, "msgid \"Open\"\nmsgstr \"Öppen\"") {
+		t.Errorf("Entry without context should not have been updated")
+		t.Logf("Updated content:\n%s", updatedStr)
+	}
+}
+
+func TestUpdateMsgIDInFile_PreservesCharset(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.po")
+
+	originalContent := "msgid \"\"\n" +
+		"msgstr \"\"\n" +
+		"\"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n" +
+		"\n" +
+		"msgid \"Open\"\n" +
+		"msgstr \"\xd6ppna\"\n" +
+		"\n"
+
+	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := UpdateMsgIDInFile(testFile, "", "Open", "Öppna fil", false); err != nil {
+		t.Fatalf("UpdateMsgIDInFile failed: %v", err)
+	}
+
+	updatedContent, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+
+	// The file must still be ISO-8859-1, not UTF-8
+	expected := "msgid \"\xd6ppna fil\"\nmsgstr \"\xd6ppna\"\n"
+	if !strings.Contains(string(updatedContent), expected) {
+		t.Errorf("Expected Latin-1 encoded entry %q, got %q", expected, updatedContent)
+	}
+}
+
+func TestUpdateMsgIDInFile_MultilineMsgID(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.po")
+
+	originalContent := `#: lib/page.ex:1
+msgid "Address"
+msgstr ""
+"Gatan 1\n"
+"Staden"
+
+msgid "Other"
+msgstr "Annan"
+`
+
+	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := UpdateMsgIDInFile(testFile, "", "Address", "Street\nCity", false); err != nil {
+		t.Fatalf("UpdateMsgIDInFile failed: %v", err)
+	}
+
+	updatedContent, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+
+	// Only the msgid lines change; no "\n" is added after the last line
+	expected := `#: lib/page.ex:1
+msgid ""
+"Street\n"
+"City"
+msgstr ""
+"Gatan 1\n"
+"Staden"
+
+msgid "Other"
+msgstr "Annan"
+`
+	if string(updatedContent) != expected {
+		t.Errorf("Unexpected content:\n%s\nexpected:\n%s", updatedContent, expected)
+	}
+}
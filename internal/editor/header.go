@@ -0,0 +1,34 @@
+package editor
+
+// SetHeaderFieldInFile sets a header field (e.g. Language) in a single .po file,
+// leaving the rest of the file untouched. A header entry is created if the file has none.
+func SetHeaderFieldInFile(filePath, key, value string, dryRun bool) (*UpdateResult, error) {
+	result := &UpdateResult{FilePath: filePath}
+
+	catalog, err := readCatalog(filePath)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	// Nothing to do if the field already has this value
+	header := catalog.Header()
+	if header.Has(key) && header.Get(key) == value {
+		return result, nil
+	}
+	result.EntriesFound = 1
+
+	if dryRun {
+		return result, nil
+	}
+
+	// The catalog is encoded in the charset the updated header declares
+	header.Set(key, value)
+	if err := catalog.WriteFile(filePath); err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Updated = true
+	return result, nil
+}
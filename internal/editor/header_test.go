@@ -0,0 +1,66 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetHeaderFieldInFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.po")
+
+	originalContent := `# HEADER COMMENT
+msgid ""
+msgstr ""
+"Language: sv\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: lib/file.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+`
+
+	expectedContent := `# HEADER COMMENT
+msgid ""
+msgstr ""
+"Language: sv\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#: lib/file.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+`
+
+	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := SetHeaderFieldInFile(testFile, "Plural-Forms", "nplurals=2; plural=(n != 1);", false)
+	if err != nil {
+		t.Fatalf("SetHeaderFieldInFile failed: %v", err)
+	}
+	if !result.Updated {
+		t.Error("Expected file to be updated")
+	}
+
+	updatedContent, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+	if string(updatedContent) != expectedContent {
+		t.Errorf("Unexpected content:\n%s", updatedContent)
+	}
+
+	// Setting the same value again is a no-op
+	result, err = SetHeaderFieldInFile(testFile, "Language", "sv", false)
+	if err != nil {
+		t.Fatalf("SetHeaderFieldInFile failed: %v", err)
+	}
+	if result.Updated || result.EntriesFound != 0 {
+		t.Error("Expected no update when the value is unchanged")
+	}
+}
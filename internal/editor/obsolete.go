@@ -0,0 +1,32 @@
+package editor
+
+// PurgeObsoleteInFile removes all obsolete (#~) entries from a single .po file
+func PurgeObsoleteInFile(filePath string, dryRun bool) (*UpdateResult, error) {
+	result := &UpdateResult{FilePath: filePath}
+
+	catalog, err := readCatalog(filePath)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	for _, entry := range catalog.Entries() {
+		if entry.Obsolete {
+			result.EntriesFound++
+		}
+	}
+
+	// Nothing to remove, or just reporting
+	if result.EntriesFound == 0 || dryRun {
+		return result, nil
+	}
+
+	catalog.PurgeObsolete()
+	if err := catalog.WriteFile(filePath); err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Updated = true
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPurgeObsoleteInFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.po")
+
+	originalContent := `# HEADER
+msgid ""
+msgstr ""
+
+#: lib/file.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+#~ msgid "Sign In"
+#~ msgstr "Logga in"
+
+#~ msgid "Sign Out"
+#~ msgstr "Logga ut"
+
+`
+
+	expectedContent := `# HEADER
+msgid ""
+msgstr ""
+
+#: lib/file.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+`
+
+	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Dry run reports without touching the file
+	result, err := PurgeObsoleteInFile(testFile, true)
+	if err != nil {
+		t.Fatalf("PurgeObsoleteInFile failed: %v", err)
+	}
+	if result.EntriesFound != 2 || result.Updated {
+		t.Errorf("Expected 2 entries found and no update on dry run, got %d (updated=%v)", result.EntriesFound, result.Updated)
+	}
+
+	result, err = PurgeObsoleteInFile(testFile, false)
+	if err != nil {
+		t.Fatalf("PurgeObsoleteInFile failed: %v", err)
+	}
+	if !result.Updated {
+		t.Error("Expected file to be updated")
+	}
+
+	updatedContent, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+
+	if string(updatedContent) != expectedContent {
+		t.Errorf("Unexpected content after purge:\n%s", updatedContent)
+	}
+}
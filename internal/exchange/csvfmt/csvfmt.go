@@ -0,0 +1,315 @@
+// Package csvfmt reads and writes multilingual spreadsheets (CSV or TSV) of
+// a catalog domain, with a column per language, and applies edited cells
+// back to the catalogs
+package csvfmt
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// Columns before and after the language columns
+const (
+	columnContext     = "msgctxt"
+	columnMsgID       = "msgid"
+	columnMsgIDPlural = "msgid_plural"
+	columnPlural      = "plural"
+	columnReferences  = "references"
+	columnComments    = "comments"
+	columnBase        = "base"
+)
+
+// fixedColumns are the columns that are not languages
+var fixedColumns = []string{columnContext, columnMsgID, columnMsgIDPlural, columnPlural, columnReferences, columnComments, columnBase}
+
+// byteOrderMark starts written files, so spreadsheet applications read them as UTF-8
+const byteOrderMark = "\uFEFF"
+
+// Table is a spreadsheet of translations: a row per singular entry or plural
+// form, and a column per language
+type Table struct {
+	Languages []string
+	Rows      []*Row
+}
+
+// Row is a row of a Table
+type Row struct {
+	Line        int // Line of the row in the file read, for messages
+	MsgCtxt     string
+	MsgID       string
+	MsgIDPlural string
+	PluralIndex int               // Plural form index, -1 for singular entries
+	Values      map[string]string // msgstr by language
+	References  string
+	Comments    string            // Developer comments
+	Base        map[string]string // Hash of each language's msgstr when exported
+}
+
+// Key returns the model.Key of the row's entry
+func (r *Row) Key() string {
+	return model.Key(r.MsgCtxt, r.MsgID)
+}
+
+// Describe formats the row for messages: its msgctxt::msgid and plural form
+func (r *Row) Describe() string {
+	s := parser.DisplayKey(r.Key())
+	if r.PluralIndex >= 0 {
+		s += fmt.Sprintf("[%d]", r.PluralIndex)
+	}
+	return s
+}
+
+// Build makes a table of the catalogs of one domain, by language, with
+// languages in the given order. Rows follow the first catalog's entry
+// order, followed by entries only other catalogs have; references and
+// comments are taken from the first catalog that has them.
+func Build(catalogs map[string]*po.Catalog, languages []string) *Table {
+	table := &Table{Languages: languages}
+	rows := make(map[string]*Row)
+
+	for _, lang := range languages {
+		for _, unit := range exchange.Units(catalogs[lang]) {
+			id := exchange.UnitID(unit.MsgCtxt, unit.MsgID, unit.PluralIndex)
+			row, ok := rows[id]
+			if !ok {
+				row = &Row{
+					MsgCtxt:     unit.MsgCtxt,
+					MsgID:       unit.MsgID,
+					MsgIDPlural: unit.MsgIDPlural,
+					PluralIndex: unit.PluralIndex,
+					Values:      make(map[string]string),
+					Base:        make(map[string]string),
+				}
+				rows[id] = row
+				table.Rows = append(table.Rows, row)
+			}
+			if row.References == "" {
+				row.References = strings.Join(unit.References, " ")
+			}
+			if row.Comments == "" {
+				row.Comments = strings.Join(unit.ExtractedComments, "\n")
+			}
+			row.Values[lang] = unit.Target
+			row.Base[lang] = hash(unit.Target)
+		}
+	}
+	return table
+}
+
+// hash returns a short hash of a msgstr, to detect changes since export
+func hash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:4])
+}
+
+// Write writes the table as CSV, or with another separator (e.g. '\t' for TSV)
+func Write(w io.Writer, table *Table, comma rune) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(byteOrderMark)
+
+	writer := csv.NewWriter(bw)
+	writer.Comma = comma
+
+	header := []string{columnContext, columnMsgID, columnMsgIDPlural, columnPlural}
+	header = append(header, table.Languages...)
+	header = append(header, columnReferences, columnComments, columnBase)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		plural := ""
+		if row.PluralIndex >= 0 {
+			plural = strconv.Itoa(row.PluralIndex)
+		}
+		record := []string{row.MsgCtxt, row.MsgID, row.MsgIDPlural, plural}
+		var base []string
+		for _, lang := range table.Languages {
+			record = append(record, row.Values[lang])
+			if h, ok := row.Base[lang]; ok {
+				base = append(base, lang+"="+h)
+			}
+		}
+		record = append(record, row.References, row.Comments, strings.Join(base, " "))
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Read reads a table written by Write, possibly edited in a spreadsheet
+// application. Columns are found by their header, so they may be
+// reordered, and any column that is not a known one is a language. The
+// base column may be missing, which disables conflict detection.
+func Read(r io.Reader, comma rune) (*Table, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(len(byteOrderMark)); err == nil && string(bom) == byteOrderMark {
+		br.Discard(len(byteOrderMark))
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header row: %w", err)
+	}
+
+	columns := make(map[string]int)
+	table := &Table{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		columns[name] = i
+		if name != "" && !slices.Contains(fixedColumns, name) {
+			table.Languages = append(table.Languages, name)
+		}
+	}
+	if _, ok := columns[columnMsgID]; !ok {
+		return nil, fmt.Errorf("missing %s column", columnMsgID)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		row := &Row{
+			Line:        line,
+			MsgCtxt:     cell(columnContext),
+			MsgID:       cell(columnMsgID),
+			MsgIDPlural: cell(columnMsgIDPlural),
+			PluralIndex: -1,
+			Values:      make(map[string]string),
+			References:  cell(columnReferences),
+			Comments:    cell(columnComments),
+			Base:        make(map[string]string),
+		}
+		if plural := strings.TrimSpace(cell(columnPlural)); plural != "" {
+			index, err := strconv.Atoi(plural)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("line %d: invalid plural form %q", line, plural)
+			}
+			row.PluralIndex = index
+		}
+		for _, lang := range table.Languages {
+			row.Values[lang] = cell(lang)
+		}
+		for _, field := range strings.Fields(cell(columnBase)) {
+			if lang, h, ok := strings.Cut(field, "="); ok {
+				row.Base[lang] = h
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// Result is the outcome of applying a table to one language's catalog
+type Result struct {
+	Updated   []string // Display keys of the entries updated
+	Conflicts []*Row   // Rows edited in the table whose msgstr also changed in the catalog since export
+	Missing   []*Row   // Rows whose entry is no longer in the catalog
+}
+
+// Apply merges the language's column of the table into its catalog, like
+// translate. A cell is applied if it differs from the msgstr it was exported
+// with (its base); if the catalog's msgstr changed since export as well, the
+// row is a conflict and neither is changed. Empty cells are skipped, so
+// translations cannot be removed through the table. A plural form the
+// catalog does not have is an error.
+func Apply(table *Table, lang string, catalog *po.Catalog) (*Result, error) {
+	result := &Result{}
+	translations := make(map[string]*parser.Translation)
+
+	for _, row := range table.Rows {
+		value := row.Values[lang]
+		entry := catalog.Get(row.MsgCtxt, row.MsgID)
+		if entry == nil || (row.PluralIndex >= 0) != entry.IsPlural() {
+			// Only rows the catalog had at export (or, without a base, that
+			// have a value to apply) are missing; others were never in it
+			if _, ok := row.Base[lang]; ok || len(row.Base) == 0 && value != "" {
+				result.Missing = append(result.Missing, row)
+			}
+			continue
+		}
+
+		current := entry.MsgStr
+		if row.PluralIndex >= 0 {
+			current = ""
+			if row.PluralIndex < len(entry.MsgStrPlural) {
+				current = entry.MsgStrPlural[row.PluralIndex]
+			}
+		}
+
+		if value == "" || value == current {
+			continue
+		}
+		if base, ok := row.Base[lang]; ok {
+			if hash(value) == base {
+				continue // Not edited in the table; the catalog is newer
+			}
+			if hash(current) != base {
+				result.Conflicts = append(result.Conflicts, row)
+				continue
+			}
+		}
+
+		t, ok := translations[row.Key()]
+		if !ok {
+			t = &parser.Translation{MsgCtxt: row.MsgCtxt, MsgID: row.MsgID}
+			translations[row.Key()] = t
+		}
+		if row.PluralIndex >= 0 {
+			if t.MsgStrPlural == nil {
+				t.MsgStrPlural = make(map[int]string)
+				t.PluralLines = make(map[int]int)
+			}
+			t.MsgStrPlural[row.PluralIndex] = value
+			t.PluralLines[row.PluralIndex] = row.Line
+		} else {
+			t.MsgStr = value
+		}
+	}
+
+	updated, err := exchange.Apply(catalog, translations)
+	if err != nil {
+		return nil, err
+	}
+	result.Updated = updated
+	return result, nil
+}
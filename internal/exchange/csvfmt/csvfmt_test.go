@@ -0,0 +1,161 @@
+package csvfmt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/testutil"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+func testCatalogs(t *testing.T) map[string]*po.Catalog {
+	return map[string]*po.Catalog{
+		"sv": testutil.ReadCatalog(t, `#. Login page
+#: lib/login.ex:12
+msgid "Sign In"
+msgstr "Logga in"
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] "%d filer"
+`),
+		"de": testutil.ReadCatalog(t, `msgid "Sign In"
+msgstr "Anmelden"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] ""
+`),
+	}
+}
+
+// roundTrip writes table and reads it back
+func roundTrip(t *testing.T, table *Table, comma rune) *Table {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, table, comma); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got, err := Read(&buf, comma)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return got
+}
+
+func TestBuildAndRoundTrip(t *testing.T) {
+	table := Build(testCatalogs(t), []string{"de", "sv"})
+
+	var buf bytes.Buffer
+	if err := Write(&buf, table, ','); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimPrefix(buf.String(), byteOrderMark), "\n")
+	if lines[0] != "msgctxt,msgid,msgid_plural,plural,de,sv,references,comments,base" {
+		t.Errorf("unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], ",Sign In,,,Anmelden,Logga in,lib/login.ex:12,Login page,de=") {
+		t.Errorf("unexpected first row: %s", lines[1])
+	}
+
+	for _, comma := range []rune{',', '\t'} {
+		got := roundTrip(t, table, comma)
+		if strings.Join(got.Languages, ",") != "de,sv" || len(got.Rows) != len(table.Rows) {
+			t.Fatalf("unexpected table: %v, %d rows", got.Languages, len(got.Rows))
+		}
+		for i, row := range got.Rows {
+			want := table.Rows[i]
+			if row.Key() != want.Key() || row.PluralIndex != want.PluralIndex || row.Values["sv"] != want.Values["sv"] || row.Values["de"] != want.Values["de"] {
+				t.Errorf("row %d: got %+v, expected %+v", i, row, want)
+			}
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	catalogs := testCatalogs(t)
+	table := roundTrip(t, Build(catalogs, []string{"de", "sv"}), ',')
+
+	// Edit the spreadsheet
+	for _, row := range table.Rows {
+		switch row.Describe() {
+		case "Sign In":
+			row.Values["sv"] = "Logga in nu"
+		case "%d file[1]":
+			row.Values["de"] = "%d Dateien"
+		case "button::Open":
+			row.Values["sv"] = "Öppna?"
+		}
+	}
+	table.Rows = append(table.Rows, &Row{MsgID: "Removed", PluralIndex: -1, Values: map[string]string{"sv": "x"}, Base: map[string]string{"sv": hash("")}})
+
+	// ... while the catalog changes too
+	catalogs["sv"].Get("button", "Open").MsgStr = "Öppna!"
+
+	result, err := Apply(table, "sv", catalogs["sv"])
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(result.Updated, ",") != "Sign In" {
+		t.Errorf("unexpected updates: %v", result.Updated)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Describe() != "button::Open" {
+		t.Errorf("expected a conflict for button::Open, got %v", result.Conflicts)
+	}
+	if len(result.Missing) != 1 || result.Missing[0].MsgID != "Removed" {
+		t.Errorf("expected the removed msgid to be missing, got %v", result.Missing)
+	}
+	if got := catalogs["sv"].Get("button", "Open").MsgStr; got != "Öppna!" {
+		t.Errorf("expected the conflicting entry to be left alone, got %q", got)
+	}
+
+	result, err = Apply(table, "de", catalogs["de"])
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(result.Updated, ",") != "%d file" || len(result.Conflicts) != 0 {
+		t.Errorf("unexpected de result: %+v", result)
+	}
+	// Rows the de catalog never had are not missing
+	if len(result.Missing) != 0 {
+		t.Errorf("expected no missing rows for de, got %v", result.Missing)
+	}
+	if got := catalogs["de"].Get("", "%d file").MsgStrPlural; got[0] != "%d Datei" || got[1] != "%d Dateien" {
+		t.Errorf("unexpected plural forms: %q", got)
+	}
+}
+
+func TestApply_WithoutBase(t *testing.T) {
+	catalogs := testCatalogs(t)
+	table, err := Read(strings.NewReader("msgid,sv\nSign In,Logga in nu\nOpen,Öppna\n"), ',')
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	result, err := Apply(table, "sv", catalogs["sv"])
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(result.Updated, ",") != "Sign In" {
+		t.Errorf("unexpected updates: %v", result.Updated)
+	}
+	// "Open" without its msgctxt column matches no entry
+	if len(result.Missing) != 1 || result.Missing[0].Line != 3 {
+		t.Errorf("expected line 3 to be missing, got %v", result.Missing)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	for _, input := range []string{"", "msgctxt,sv\n", "msgid,plural\nA,x\n", "msgid,sv,sv\n"} {
+		if _, err := Read(strings.NewReader(input), ','); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
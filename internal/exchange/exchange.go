@@ -0,0 +1,205 @@
+// Package exchange converts catalogs to and from the units used by
+// translation exchange formats (XLIFF and others): one unit per singular
+// entry and one per plural form, matched back to entries by ID on import
+package exchange
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// Unit is a single translatable string: a singular entry, or one plural form
+// of a plural entry
+type Unit struct {
+	ID                string
+	MsgCtxt           string
+	MsgID             string
+	MsgIDPlural       string
+	PluralIndex       int    // Plural form index, -1 for singular entries
+	Source            string // msgid, or msgid_plural for plural forms after the first
+	Target            string // msgstr, or msgstr[N]
+	Fuzzy             bool
+	Comments          []string // Translator comments
+	ExtractedComments []string // Developer comments
+	References        []string // Source references
+}
+
+// IsPlural returns true if the unit is a plural form
+func (u *Unit) IsPlural() bool {
+	return u.PluralIndex >= 0
+}
+
+// UnitID returns a stable identifier for an entry, or for one of its plural
+// forms (pluralIndex >= 0). It is derived from msgctxt and msgid only, so it
+// survives changes to the translation, and is valid as an XML NMTOKEN.
+func UnitID(msgctxt, msgid string, pluralIndex int) string {
+	sum := sha1.Sum([]byte(model.Key(msgctxt, msgid)))
+	id := "m" + hex.EncodeToString(sum[:8])
+	if pluralIndex >= 0 {
+		id += "-" + strconv.Itoa(pluralIndex)
+	}
+	return id
+}
+
+// Units returns the units for the live entries of a catalog, in file order.
+// Plural entries get one unit per form: as many as the header's nplurals, or
+// as the entry already has, and at least two.
+func Units(catalog *po.Catalog) []*Unit {
+	nplurals := catalog.Header().NPlurals()
+
+	var units []*Unit
+	for _, entry := range catalog.Entries() {
+		if entry.Obsolete {
+			continue
+		}
+		units = append(units, EntryUnits(entry, nplurals)...)
+	}
+	return units
+}
+
+// EntryUnits returns the units of a single entry
+func EntryUnits(entry *model.MsgEntry, nplurals int) []*Unit {
+	unit := func(index int, source, target string) *Unit {
+		return &Unit{
+			ID:                UnitID(entry.MsgCtxt, entry.MsgID, index),
+			MsgCtxt:           entry.MsgCtxt,
+			MsgID:             entry.MsgID,
+			MsgIDPlural:       entry.MsgIDPlural,
+			PluralIndex:       index,
+			Source:            source,
+			Target:            target,
+			Fuzzy:             entry.IsFuzzy(),
+			Comments:          entry.Comments,
+			ExtractedComments: entry.ExtractedComments,
+			References:        entry.References,
+		}
+	}
+
+	if !entry.IsPlural() {
+		return []*Unit{unit(-1, entry.MsgID, entry.MsgStr)}
+	}
+
+	forms := max(nplurals, len(entry.MsgStrPlural), 2)
+	units := make([]*Unit, 0, forms)
+	for i := 0; i < forms; i++ {
+		source := entry.MsgIDPlural
+		if i == 0 {
+			source = entry.MsgID
+		}
+		target := ""
+		if i < len(entry.MsgStrPlural) {
+			target = entry.MsgStrPlural[i]
+		}
+		units = append(units, unit(i, source, target))
+	}
+	return units
+}
+
+// Group splits units into the units of each entry: a single unit for
+// singular entries, all plural forms for plural entries
+func Group(units []*Unit) [][]*Unit {
+	var groups [][]*Unit
+	for i := 0; i < len(units); {
+		n := 1
+		if units[i].IsPlural() {
+			for i+n < len(units) && units[i+n].PluralIndex > 0 &&
+				units[i+n].MsgCtxt == units[i].MsgCtxt && units[i+n].MsgID == units[i].MsgID {
+				n++
+			}
+		}
+		groups = append(groups, units[i:i+n])
+		i += n
+	}
+	return groups
+}
+
+// Match maps imported units to the live entries of catalog by ID and returns
+// the translations to merge (keyed like parser.ParseTranslationSet, for
+// parser.Merge) and the units that match no entry. id computes the ID of the
+// catalog's own units; nil uses their default UnitID. Units without a target,
+// or marked Fuzzy (not yet reviewed), are skipped, and so are units whose
+// target is already the entry's translation. reviewed tells whether the
+// format records review state (XLIFF): only then does an unchanged target
+// of a fuzzy entry count as reviewed and clear its fuzzy flag. Formats
+// without it (JSON) cannot tell an unedited export from a review.
+func Match(catalog *po.Catalog, units []*Unit, id func(*Unit) string, reviewed bool) (map[string]*parser.Translation, []*Unit) {
+	if id == nil {
+		id = func(u *Unit) string { return u.ID }
+	}
+	known := make(map[string]*Unit)
+	for _, unit := range Units(catalog) {
+		known[id(unit)] = unit
+	}
+
+	translations := make(map[string]*parser.Translation)
+	var unmatched []*Unit
+	for _, imported := range units {
+		unit, ok := known[imported.ID]
+		if !ok {
+			unmatched = append(unmatched, imported)
+			continue
+		}
+		if imported.Target == "" || imported.Fuzzy {
+			continue
+		}
+		if imported.Target == unit.Target && (!unit.Fuzzy || !reviewed) {
+			continue // Already translated like this
+		}
+
+		key := model.Key(unit.MsgCtxt, unit.MsgID)
+		t, ok := translations[key]
+		if !ok {
+			t = &parser.Translation{MsgCtxt: unit.MsgCtxt, MsgID: unit.MsgID}
+			translations[key] = t
+		}
+		if unit.IsPlural() {
+			if t.MsgStrPlural == nil {
+				t.MsgStrPlural = make(map[int]string)
+			}
+			t.MsgStrPlural[unit.PluralIndex] = imported.Target
+		} else {
+			t.MsgStr = imported.Target
+		}
+	}
+	return translations, unmatched
+}
+
+// Apply merges translations into the catalog with parser.Merge, like the
+// translate command, and returns the display keys of the entries updated.
+// It fails on a plural form the catalog does not have, having applied the
+// translations before it.
+func Apply(catalog *po.Catalog, translations map[string]*parser.Translation) ([]string, error) {
+	nplurals := catalog.Header().NPlurals()
+	var updated []string
+	for _, entry := range catalog.Entries() {
+		merged, err := parser.Merge(entry, translations, nplurals)
+		if err != nil {
+			return updated, err
+		}
+		if merged {
+			updated = append(updated, parser.DisplayKey(entry.Key()))
+		}
+	}
+	return updated, nil
+}
+
+// Describe formats a unit for messages: its msgctxt::msgid and plural form
+func Describe(u *Unit) string {
+	s := parser.DisplayKey(model.Key(u.MsgCtxt, u.MsgID))
+	if s == "" {
+		s = u.Source
+	}
+	if u.IsPlural() {
+		s += fmt.Sprintf("[%d]", u.PluralIndex)
+	}
+	if s == "" {
+		s = u.ID
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+package exchange
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/testutil"
+)
+
+const testCatalog = `msgid ""
+msgstr ""
+"Language: sv\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#. Shown on the login page
+#: lib/web/login.ex:12
+msgid "Sign In"
+msgstr ""
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#~ msgid "Old"
+#~ msgstr "Gammal"
+`
+
+func TestUnits(t *testing.T) {
+	units := Units(testutil.ReadCatalog(t, testCatalog))
+
+	// Obsolete entries are left out; the plural entry has a unit per form
+	if len(units) != 4 {
+		t.Fatalf("expected 4 units, got %d", len(units))
+	}
+	if units[0].Source != "Sign In" || units[0].IsPlural() || units[0].ExtractedComments[0] != "Shown on the login page" {
+		t.Errorf("unexpected first unit: %+v", units[0])
+	}
+	if units[1].MsgCtxt != "button" || units[1].Target != "Öppna" {
+		t.Errorf("unexpected context unit: %+v", units[1])
+	}
+	if units[2].Source != "%d file" || units[3].Source != "%d files" || units[3].PluralIndex != 1 {
+		t.Errorf("unexpected plural units: %+v %+v", units[2], units[3])
+	}
+	if units[2].ID == units[3].ID || units[0].ID != UnitID("", "Sign In", -1) {
+		t.Error("expected stable, distinct unit IDs")
+	}
+}
+
+func TestUnitID_Context(t *testing.T) {
+	if UnitID("button", "Open", -1) == UnitID("", "Open", -1) {
+		t.Error("expected msgctxt to be part of the unit ID")
+	}
+}
+
+func TestMatchAndApply(t *testing.T) {
+	catalog := testutil.ReadCatalog(t, testCatalog)
+
+	imported := []*Unit{
+		{ID: UnitID("", "Sign In", -1), Target: "Logga in"},
+		{ID: UnitID("button", "Open", -1), Target: "Öppna"},     // Unchanged
+		{ID: UnitID("", "%d file", 1), Target: "%d filer"},      // One plural form
+		{ID: UnitID("", "%d file", 0), Target: "", Fuzzy: true}, // Not translated
+		{ID: UnitID("", "Old", -1), Target: "Gammal"},           // Obsolete entry
+		{ID: "mbogus", Target: "x"},
+	}
+
+	translations, unmatched := Match(catalog, imported, nil, true)
+	if len(unmatched) != 2 || unmatched[0].ID != UnitID("", "Old", -1) || unmatched[1].ID != "mbogus" {
+		t.Errorf("unexpected unmatched units: %+v", unmatched)
+	}
+
+	updated, err := Apply(catalog, translations)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if strings.Join(updated, ",") != "Sign In,%d file" {
+		t.Errorf("unexpected updated entries: %v", updated)
+	}
+	if got := catalog.Get("", "Sign In").MsgStr; got != "Logga in" {
+		t.Errorf("expected Sign In to be translated, got %q", got)
+	}
+	if got := catalog.Get("", "%d file").MsgStrPlural; got[0] != "" || got[1] != "%d filer" {
+		t.Errorf("expected only msgstr[1] to be set, got %q", got)
+	}
+}
+
+func TestMatch_FuzzyWithoutReviewState(t *testing.T) {
+	catalog := testutil.ReadCatalog(t, testCatalog)
+	catalog.Get("button", "Open").AddFlag("fuzzy")
+	imported := []*Unit{{ID: UnitID("button", "Open", -1), Target: "Öppna"}}
+
+	// An unedited JSON export leaves the entry fuzzy
+	translations, _ := Match(catalog, imported, nil, false)
+	if len(translations) != 0 {
+		t.Errorf("expected unchanged target not to be applied, got %v", translations)
+	}
+
+	// An XLIFF unit without the fuzzy state has been reviewed
+	translations, _ = Match(catalog, imported, nil, true)
+	if updated, _ := Apply(catalog, translations); len(updated) != 1 || catalog.Get("button", "Open").IsFuzzy() {
+		t.Error("expected reviewed unit to clear the fuzzy flag")
+	}
+}
+
+func TestApply_PartialFuzzyPlural(t *testing.T) {
+	catalog := testutil.ReadCatalog(t, testCatalog)
+	catalog.Get("", "%d file").AddFlag("fuzzy")
+	imported := []*Unit{{ID: UnitID("", "%d file", 1), Target: "%d filer"}}
+
+	translations, _ := Match(catalog, imported, nil, true)
+	if updated, err := Apply(catalog, translations); err != nil || len(updated) != 1 {
+		t.Fatalf("expected the entry to be updated, got %v, %v", updated, err)
+	}
+	if !catalog.Get("", "%d file").IsFuzzy() {
+		t.Error("expected the entry to stay fuzzy with msgstr[0] not reviewed")
+	}
+}
+
+func TestPluralCategories(t *testing.T) {
+	tests := []struct {
+		lang     string
+		nplurals int
+		expected []string
+	}{
+		{"sv", 2, []string{"one", "other"}},
+		{"ja", 1, []string{"other"}},
+		{"ru", 3, []string{"one", "few", "many"}},
+		{"cs_CZ", 3, []string{"one", "few", "other"}},
+		{"ar", 6, []string{"zero", "one", "two", "few", "many", "other"}},
+		{"xx", 7, []string{"form0", "form1", "form2", "form3", "form4", "form5", "form6"}},
+	}
+	for _, tt := range tests {
+		if got := PluralCategories(tt.lang, tt.nplurals); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("PluralCategories(%q, %d) = %v, expected %v", tt.lang, tt.nplurals, got, tt.expected)
+		}
+	}
+}
+
+func TestKeys_Camel(t *testing.T) {
+	units := []*Unit{
+		{MsgID: "Sign In"},
+		{MsgCtxt: "button", MsgID: "Open file..."},
+		{MsgID: "%d files in %{folder}"},
+		{MsgID: "Hello!"},
+		{MsgID: "Hello?"},
+		{MsgID: "Välkommen"},
+	}
+	keys := Keys(units, KeyCamel)
+
+	for key, expected := range map[string]string{
+		"Sign In":                "signIn",
+		"button\x04Open file...": "buttonOpenFile",
+		"%d files in %{folder}":  "filesInFolder",
+	} {
+		if keys[key] != expected {
+			t.Errorf("expected %q for %q, got %q", expected, key, keys[key])
+		}
+	}
+
+	// Colliding and word-less msgids get the unit ID appended
+	if keys["Hello!"] == keys["Hello?"] || !strings.HasPrefix(keys["Hello!"], "hello_") {
+		t.Errorf("expected distinct keys for colliding msgids, got %q and %q", keys["Hello!"], keys["Hello?"])
+	}
+	if keys["Välkommen"] != "valkommen" {
+		t.Errorf("expected accents to be removed, got %q", keys["Välkommen"])
+	}
+}
+
+func TestKeys_Strategies(t *testing.T) {
+	units := []*Unit{{MsgCtxt: "button", MsgID: "Open file..."}}
+	key := "button\x04Open file..."
+
+	for strategy, expected := range map[KeyStrategy]string{
+		KeySnake: "button_open_file",
+		KeyMsgID: "button::Open file...",
+		KeyHash:  UnitID("button", "Open file...", -1),
+	} {
+		if got := Keys(units, strategy)[key]; got != expected {
+			t.Errorf("%s: expected %q, got %q", strategy, expected, got)
+		}
+	}
+
+	if _, err := ParseKeyStrategy("msgid", KeySnake, KeyHash); err == nil {
+		t.Error("expected error for a strategy the format does not allow")
+	}
+	if strategy, err := ParseKeyStrategy("", KeySnake, KeyHash); err != nil || strategy != KeySnake {
+		t.Errorf("expected the first strategy as default, got %q, %v", strategy, err)
+	}
+}
@@ -0,0 +1,200 @@
+package jsonfmt
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// arbCountPlaceholder is the ICU plural argument, matching Elixir's %{count}
+const arbCountPlaceholder = "count"
+
+// arbPlaceholder matches ICU placeholders like {name}
+var arbPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// ARBKeyStrategies are the key strategies allowed for ARB files, whose keys
+// become Dart method names; the first is the default
+var ARBKeyStrategies = []exchange.KeyStrategy{exchange.KeyCamel, exchange.KeySnake, exchange.KeyHash}
+
+// ARBKey returns the function giving the key of a unit in an ARB file, from
+// keys by model.Key (see exchange.Keys), which must be computed over the same
+// entries as on export for keys to match. Plural forms get "#category"
+// appended; in the file all forms share one ICU plural message under the
+// entry's key.
+func ARBKey(keys map[string]string, categories []string) func(*exchange.Unit) string {
+	return func(unit *exchange.Unit) string {
+		key := keys[model.Key(unit.MsgCtxt, unit.MsgID)]
+		if unit.IsPlural() {
+			key += "#" + exchange.PluralCategory(categories, unit.PluralIndex)
+		}
+		return key
+	}
+}
+
+// WriteARB writes the translated units as an ARB file for locale lang, with
+// keys by model.Key (see exchange.Keys).
+// Gettext interpolations (%{name}) become ICU placeholders ({name}), plural
+// entries become ICU plural messages on {count}, and each message gets an
+// "@key" entry with the developer comments as description, the msgctxt as
+// context, and its placeholders. Plural entries are only written when all
+// their forms are translated.
+func WriteARB(w io.Writer, units []*exchange.Unit, lang string, categories []string, keys map[string]string) error {
+	members := []member{{"@@locale", lang}}
+
+	for _, forms := range exportedEntries(units) {
+		unit := forms[0]
+		key := keys[model.Key(unit.MsgCtxt, unit.MsgID)]
+
+		var message string
+		if unit.IsPlural() {
+			message = icuPlural(forms, categories)
+		} else {
+			message = gettextPlaceholder.ReplaceAllString(unit.Target, "{$1}")
+		}
+		members = append(members, member{key, message}, member{"@" + key, arbMetadata(unit, message)})
+	}
+	return writeObject(w, members)
+}
+
+// icuPlural builds an ICU plural message from the plural forms of an entry
+func icuPlural(forms []*exchange.Unit, categories []string) string {
+	var b strings.Builder
+	b.WriteString("{" + arbCountPlaceholder + ", plural,")
+	for _, form := range forms {
+		b.WriteString(" " + exchange.PluralCategory(categories, form.PluralIndex) + "{")
+		b.WriteString(gettextPlaceholder.ReplaceAllString(form.Target, "{$1}"))
+		b.WriteString("}")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// arbMetadata returns the "@key" attributes of a message
+func arbMetadata(unit *exchange.Unit, message string) map[string]any {
+	metadata := make(map[string]any)
+	if len(unit.ExtractedComments) > 0 {
+		metadata["description"] = strings.Join(unit.ExtractedComments, "\n")
+	}
+	if unit.MsgCtxt != "" {
+		metadata["context"] = unit.MsgCtxt
+	}
+
+	placeholders := make(map[string]any)
+	if unit.IsPlural() {
+		placeholders[arbCountPlaceholder] = map[string]string{"type": "int"}
+	}
+	for _, m := range arbPlaceholder.FindAllStringSubmatch(message, -1) {
+		if _, ok := placeholders[m[1]]; !ok {
+			placeholders[m[1]] = map[string]string{}
+		}
+	}
+	if len(placeholders) > 0 {
+		metadata["placeholders"] = placeholders
+	}
+	return metadata
+}
+
+// ReadARB reads an ARB file. Unit IDs are the message keys, with "#category"
+// for each form of ICU plural messages (see ARBKey); ICU placeholders are
+// turned back into gettext interpolations. Attributes ("@key") are ignored.
+func ReadARB(r io.Reader) (*File, error) {
+	members, err := readObject(r)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{}
+	for _, m := range members {
+		if m.key == "@@locale" {
+			file.Language, _ = m.value.(string)
+			continue
+		}
+		if strings.HasPrefix(m.key, "@") {
+			continue
+		}
+
+		message, ok := m.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string value for %q", m.key)
+		}
+		forms, ok := parseICUPlural(message)
+		if !ok {
+			file.Units = append(file.Units, &exchange.Unit{ID: m.key, PluralIndex: -1, Target: fromICU(message)})
+			continue
+		}
+		for _, form := range forms {
+			file.Units = append(file.Units, &exchange.Unit{ID: m.key + "#" + form.selector, PluralIndex: -1, Target: fromICU(form.text)})
+		}
+	}
+	return file, nil
+}
+
+// fromICU turns ICU placeholders back into gettext interpolations
+func fromICU(s string) string {
+	return arbPlaceholder.ReplaceAllString(s, "%{$1}")
+}
+
+// icuForm is a form of an ICU plural message
+type icuForm struct {
+	selector string // Plural category, or an exact match like "=0"
+	text     string
+}
+
+// parseICUPlural parses a message of the form "{count, plural, one{...}
+// other{...}}" into its forms; ok is false if the message is not a plural
+// message. An "offset:N" is skipped.
+func parseICUPlural(message string) (forms []icuForm, ok bool) {
+	s := strings.TrimSpace(message)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, false
+	}
+	parts := strings.SplitN(s[1:len(s)-1], ",", 3)
+	if len(parts) != 3 || strings.TrimSpace(parts[1]) != "plural" {
+		return nil, false
+	}
+
+	rest := parts[2]
+	for {
+		rest = strings.TrimLeft(rest, " \t\n")
+		if rest == "" {
+			return forms, len(forms) > 0
+		}
+
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			return nil, false
+		}
+		selector := strings.TrimSpace(rest[:open])
+		if strings.HasPrefix(selector, "offset:") {
+			selector = strings.TrimSpace(selector[strings.IndexAny(selector, " \t\n")+1:])
+		}
+		if selector == "" || strings.ContainsAny(selector, " \t\n") {
+			return nil, false
+		}
+
+		// Find the matching closing brace
+		depth := 0
+		end := -1
+		for i := open; i < len(rest) && end < 0; i++ {
+			switch rest[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			return nil, false
+		}
+
+		forms = append(forms, icuForm{selector, rest[open+1 : end]})
+		rest = rest[end+1:]
+	}
+}
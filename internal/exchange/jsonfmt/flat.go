@@ -0,0 +1,45 @@
+package jsonfmt
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+// FlatKey returns the key of a unit in flat JSON: the msgid, prefixed with
+// "msgctxt::" for entries with a context and suffixed with "[N]" for plural
+// forms, as in translate input
+func FlatKey(unit *exchange.Unit) string {
+	key := parser.DisplayKey(model.Key(unit.MsgCtxt, unit.MsgID))
+	if unit.IsPlural() {
+		key += "[" + strconv.Itoa(unit.PluralIndex) + "]"
+	}
+	return key
+}
+
+// WriteFlat writes units as a flat JSON object of key to msgstr. Unlike the
+// runtime formats, untranslated units are included with an empty string, so
+// the file can be filled in and imported.
+func WriteFlat(w io.Writer, units []*exchange.Unit) error {
+	members := make([]member, 0, len(units))
+	for _, unit := range units {
+		members = append(members, member{FlatKey(unit), unit.Target})
+	}
+	return writeObject(w, members)
+}
+
+// ReadFlat reads a flat JSON object; unit IDs are the keys (see FlatKey)
+func ReadFlat(r io.Reader) (*File, error) {
+	members, err := readObject(r)
+	if err != nil {
+		return nil, err
+	}
+	units, err := stringUnits(members, func(s string) string { return s })
+	if err != nil {
+		return nil, err
+	}
+	return &File{Units: units}, nil
+}
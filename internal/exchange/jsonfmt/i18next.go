@@ -0,0 +1,61 @@
+package jsonfmt
+
+import (
+	"io"
+	"regexp"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+// i18nextPlaceholder matches i18next interpolations like {{name}}
+var i18nextPlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// I18nextKey returns the function giving the key of a unit in i18next JSON
+// (v4 format): the msgid, with "_msgctxt" for entries with a context and
+// "_category" (e.g. "_one", "_few") for plural forms, where categories are
+// the catalog's plural categories. The msgid is used as the key as is, so
+// i18next must be set up with keySeparator and nsSeparator false.
+func I18nextKey(categories []string) func(*exchange.Unit) string {
+	return func(unit *exchange.Unit) string {
+		key := unit.MsgID
+		if unit.MsgCtxt != "" {
+			key += "_" + unit.MsgCtxt
+		}
+		if unit.IsPlural() {
+			key += "_" + exchange.PluralCategory(categories, unit.PluralIndex)
+		}
+		return key
+	}
+}
+
+// WriteI18next writes the translated units as an i18next JSON resource.
+// Gettext interpolations (%{name}) become i18next ones ({{name}}); plural
+// entries use %{count} in gettext and {{count}} in i18next alike. Plural
+// entries are only written when all their forms are translated.
+func WriteI18next(w io.Writer, units []*exchange.Unit, categories []string) error {
+	key := I18nextKey(categories)
+
+	var members []member
+	for _, forms := range exportedEntries(units) {
+		for _, unit := range forms {
+			members = append(members, member{key(unit), gettextPlaceholder.ReplaceAllString(unit.Target, "{{$1}}")})
+		}
+	}
+	return writeObject(w, members)
+}
+
+// ReadI18next reads a flat i18next JSON resource; unit IDs are the keys (see
+// I18nextKey) and interpolations are turned back into gettext ones
+func ReadI18next(r io.Reader) (*File, error) {
+	members, err := readObject(r)
+	if err != nil {
+		return nil, err
+	}
+	units, err := stringUnits(members, func(s string) string {
+		return i18nextPlaceholder.ReplaceAllString(s, "%{$1}")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &File{Units: units}, nil
+}
@@ -0,0 +1,150 @@
+// Package jsonfmt reads and writes the JSON formats used by front-end i18n
+// libraries: i18next, Flutter's ARB and flat key/value JSON
+package jsonfmt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+// File is a JSON translation file read for import
+type File struct {
+	Language string // Locale declared in the file, if the format has one
+	Units    []*exchange.Unit
+}
+
+// member is a member of a JSON object, kept in order
+type member struct {
+	key   string
+	value any
+}
+
+// writeObject writes members as an indented JSON object, in order
+func writeObject(w io.Writer, members []member) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("{")
+	for i, m := range members {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		key, err := marshal(m.key)
+		if err != nil {
+			return err
+		}
+		value, err := marshal(m.value)
+		if err != nil {
+			return err
+		}
+		bw.WriteString("\n  ")
+		bw.Write(key)
+		bw.WriteString(": ")
+		bw.Write(value)
+	}
+	if len(members) > 0 {
+		bw.WriteString("\n")
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// marshal encodes a value as JSON without escaping <, > and &, indented as a
+// member of an object written by writeObject
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("  ", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// readObject reads a JSON object, keeping the order of its members. Values
+// are decoded as by encoding/json.
+func readObject(r io.Reader) ([]member, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var members []member
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		key := token.(string)
+
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid JSON value for %q: %w", key, err)
+		}
+		members = append(members, member{key, value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return members, nil
+}
+
+// stringUnits turns members with string values into units with the member
+// key as ID; other values are an error
+func stringUnits(members []member, convert func(string) string) ([]*exchange.Unit, error) {
+	var units []*exchange.Unit
+	for _, m := range members {
+		s, ok := m.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string value for %q", m.key)
+		}
+		units = append(units, &exchange.Unit{ID: m.key, PluralIndex: -1, Target: convert(s)})
+	}
+	return units, nil
+}
+
+// gettextPlaceholder matches Elixir gettext interpolations like %{name}
+var gettextPlaceholder = regexp.MustCompile(`%\{(\w+)\}`)
+
+// exported reports whether a unit is written to runtime formats, which fall
+// back to the source string for missing keys: untranslated and fuzzy units
+// are left out
+func exported(unit *exchange.Unit) bool {
+	return unit.Target != "" && !unit.Fuzzy
+}
+
+// exportedEntries groups units by entry, the forms of a plural entry
+// together, and returns the entries whose forms are all exported: a plural
+// entry with a missing form is left out as a whole, so that the app falls
+// back to the source string for every count
+func exportedEntries(units []*exchange.Unit) [][]*exchange.Unit {
+	var entries [][]*exchange.Unit
+	for i := 0; i < len(units); {
+		unit := units[i]
+		forms := []*exchange.Unit{unit}
+		if unit.IsPlural() {
+			for i+len(forms) < len(units) && units[i+len(forms)].PluralIndex > 0 &&
+				units[i+len(forms)].MsgCtxt == unit.MsgCtxt && units[i+len(forms)].MsgID == unit.MsgID {
+				forms = append(forms, units[i+len(forms)])
+			}
+		}
+		i += len(forms)
+
+		if !slices.ContainsFunc(forms, func(form *exchange.Unit) bool { return !exported(form) }) {
+			entries = append(entries, forms)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,182 @@
+package jsonfmt
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+var categories = []string{"one", "other"}
+
+func testUnits() []*exchange.Unit {
+	return []*exchange.Unit{
+		{PluralIndex: -1, MsgID: "Sign In", Source: "Sign In", Target: "Logga in", ExtractedComments: []string{"Login page"}},
+		{PluralIndex: -1, MsgCtxt: "button", MsgID: "Open", Source: "Open", Target: "Öppna"},
+		{PluralIndex: -1, MsgID: "Hello %{name}", Source: "Hello %{name}", Target: "Hej %{name}"},
+		{PluralIndex: -1, MsgID: "Fuzzy", Source: "Fuzzy", Target: "Luddig", Fuzzy: true},
+		{PluralIndex: -1, MsgID: "Empty", Source: "Empty"},
+		{PluralIndex: 0, MsgID: "%{count} file", MsgIDPlural: "%{count} files", Source: "%{count} file", Target: "%{count} fil"},
+		{PluralIndex: 1, MsgID: "%{count} file", MsgIDPlural: "%{count} files", Source: "%{count} files", Target: "%{count} filer"},
+	}
+}
+
+// targets returns the targets of units by ID
+func targets(units []*exchange.Unit) map[string]string {
+	m := make(map[string]string)
+	for _, unit := range units {
+		m[unit.ID] = unit.Target
+	}
+	return m
+}
+
+func TestI18next(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteI18next(&buf, testUnits(), categories); err != nil {
+		t.Fatalf("WriteI18next failed: %v", err)
+	}
+
+	expected := `{
+  "Sign In": "Logga in",
+  "Open_button": "Öppna",
+  "Hello %{name}": "Hej {{name}}",
+  "%{count} file_one": "{{count}} fil",
+  "%{count} file_other": "{{count}} filer"
+}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	file, err := ReadI18next(&buf)
+	if err != nil {
+		t.Fatalf("ReadI18next failed: %v", err)
+	}
+	key := I18nextKey(categories)
+	got := targets(file.Units)
+	for _, unit := range testUnits() {
+		if exported(unit) && got[key(unit)] != unit.Target {
+			t.Errorf("%s: expected %q, got %q", key(unit), unit.Target, got[key(unit)])
+		}
+	}
+}
+
+func TestI18next_PartialPlural(t *testing.T) {
+	units := testUnits()
+	units[len(units)-1].Target = ""
+
+	var buf bytes.Buffer
+	if err := WriteI18next(&buf, units, categories); err != nil {
+		t.Fatalf("WriteI18next failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "%{count} file_") {
+		t.Errorf("expected a partially translated plural to be left out\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"Sign In": "Logga in"`) {
+		t.Errorf("expected other entries to be written\n%s", buf.String())
+	}
+}
+
+func TestARB(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteARB(&buf, testUnits(), "sv", categories, exchange.Keys(testUnits(), exchange.KeyCamel)); err != nil {
+		t.Fatalf("WriteARB failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`"@@locale": "sv"`,
+		`"signIn": "Logga in"`,
+		`"description": "Login page"`,
+		`"buttonOpen": "Öppna"`,
+		`"context": "button"`,
+		`"helloName": "Hej {name}"`,
+		`"countFile": "{count, plural, one{{count} fil} other{{count} filer}}"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %s\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Luddig") || strings.Contains(out, "empty") {
+		t.Errorf("expected fuzzy and untranslated entries to be left out\n%s", out)
+	}
+
+	file, err := ReadARB(&buf)
+	if err != nil {
+		t.Fatalf("ReadARB failed: %v", err)
+	}
+	if file.Language != "sv" {
+		t.Errorf("expected locale sv, got %q", file.Language)
+	}
+	key := ARBKey(exchange.Keys(testUnits(), exchange.KeyCamel), categories)
+	got := targets(file.Units)
+	for _, unit := range testUnits() {
+		if exported(unit) && got[key(unit)] != unit.Target {
+			t.Errorf("%s: expected %q, got %q", key(unit), unit.Target, got[key(unit)])
+		}
+	}
+}
+
+func TestFlat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFlat(&buf, testUnits()); err != nil {
+		t.Fatalf("WriteFlat failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"button::Open": "Öppna"`) || !strings.Contains(buf.String(), `"%{count} file[1]": "%{count} filer"`) {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	file, err := ReadFlat(&buf)
+	if err != nil {
+		t.Fatalf("ReadFlat failed: %v", err)
+	}
+	got := targets(file.Units)
+	for _, unit := range testUnits() {
+		if got[FlatKey(unit)] != unit.Target {
+			t.Errorf("%s: expected %q, got %q", FlatKey(unit), unit.Target, got[FlatKey(unit)])
+		}
+	}
+}
+
+func TestReadFlat_Invalid(t *testing.T) {
+	for _, input := range []string{`[]`, `{"a": 1}`, `{"a": "b"`} {
+		if _, err := ReadFlat(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+func TestParseICUPlural(t *testing.T) {
+	forms, ok := parseICUPlural("{n, plural, offset:1 =0{none} one{{n} {thing}} other{many}}")
+	expected := []icuForm{{"=0", "none"}, {"one", "{n} {thing}"}, {"other", "many"}}
+	if !ok || !reflect.DeepEqual(forms, expected) {
+		t.Errorf("unexpected forms: %v, %v", forms, ok)
+	}
+
+	for _, message := range []string{"Hello {name}", "{gender, select, male{he} other{they}}", "{n, plural, one{x}"} {
+		if _, ok := parseICUPlural(message); ok {
+			t.Errorf("expected %q not to be a plural message", message)
+		}
+	}
+}
+
+func TestWriteObject_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	members := []member{{"<b>Bold</b> & more", "<b>Fet</b> & mer"}, {"@meta", map[string]any{"description": "<i>"}}}
+	if err := writeObject(&buf, members); err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+
+	expected := `{
+  "<b>Bold</b> & more": "<b>Fet</b> & mer",
+  "@meta": {
+    "description": "<i>"
+  }
+}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
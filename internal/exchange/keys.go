@@ -0,0 +1,141 @@
+package exchange
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+	"golang.org/x/text/unicode/norm"
+)
+
+// KeyStrategy selects how keys are generated for formats that do not use the
+// msgid as key
+type KeyStrategy string
+
+// Key strategies
+const (
+	KeyCamel KeyStrategy = "camel" // lowerCamelCase words of the msgctxt and msgid: "buttonOpenFile"
+	KeySnake KeyStrategy = "snake" // snake_case words of the msgctxt and msgid: "button_open_file"
+	KeyMsgID KeyStrategy = "msgid" // The msgid itself, "msgctxt::msgid" with a context
+	KeyHash  KeyStrategy = "hash"  // The unit ID: "m" and 16 hex digits of a hash of msgctxt and msgid
+)
+
+// maxIdentifierWords limits the number of msgid words used in an identifier key
+const maxIdentifierWords = 6
+
+// printfDirective matches printf-style directives (%d, %5.2f, %s), which
+// would otherwise leave stray letters in identifiers
+var printfDirective = regexp.MustCompile(`%[-+ #0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// ParseKeyStrategy checks a key strategy name against the strategies a
+// format allows; "" selects the first of them
+func ParseKeyStrategy(name string, allowed ...KeyStrategy) (KeyStrategy, error) {
+	if name == "" {
+		return allowed[0], nil
+	}
+	names := make([]string, len(allowed))
+	for i, strategy := range allowed {
+		if string(strategy) == name {
+			return strategy, nil
+		}
+		names[i] = string(strategy)
+	}
+	return "", fmt.Errorf("unsupported key strategy %q (use %s)", name, strings.Join(names, ", "))
+}
+
+// Keys returns a key for every entry of units (by model.Key) following
+// strategy. Identifier keys (camel, snake) start with a letter and use the
+// first words of the msgctxt and msgid, with accents removed; entries whose
+// identifiers collide, or that have no usable words, get part of the unit ID
+// appended. Keys only depend on the set of entries, not on their order, so
+// the same catalog always gets the same keys; pass the units of every
+// catalog of a domain for the keys to agree between languages.
+func Keys(units []*Unit, strategy KeyStrategy) map[string]string {
+	keys := make(map[string]string)
+	count := make(map[string]int)
+	for _, unit := range units {
+		key := model.Key(unit.MsgCtxt, unit.MsgID)
+		if _, ok := keys[key]; ok {
+			continue
+		}
+
+		var id string
+		switch strategy {
+		case KeyMsgID:
+			id = parser.DisplayKey(key)
+		case KeyHash:
+			id = UnitID(unit.MsgCtxt, unit.MsgID, -1)
+		default:
+			id = identifier(unit.MsgCtxt+" "+unit.MsgID, strategy)
+		}
+		keys[key] = id
+		count[id]++
+	}
+
+	if strategy == KeyMsgID || strategy == KeyHash {
+		return keys
+	}
+
+	for key, id := range keys {
+		if id == "" || count[id] > 1 {
+			msgctxt, msgid := splitKey(key)
+			suffix := UnitID(msgctxt, msgid, -1)[1:7]
+			if id == "" {
+				keys[key] = "msg" + suffix
+			} else {
+				keys[key] = id + "_" + suffix
+			}
+		}
+	}
+	return keys
+}
+
+// identifier turns text into an identifier of its first ASCII words, in
+// lowerCamelCase or snake_case, with accents removed ("Välkommen" →
+// "valkommen"); it starts with a letter, or is empty if there are no words
+func identifier(text string, strategy KeyStrategy) string {
+	text = printfDirective.ReplaceAllString(text, " ")
+	text = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(text))
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	n := 0
+	for _, word := range words {
+		if n == maxIdentifierWords {
+			break
+		}
+		word = strings.ToLower(word)
+		if b.Len() == 0 && unicode.IsDigit(rune(word[0])) {
+			continue
+		}
+		if b.Len() > 0 {
+			if strategy == KeySnake {
+				b.WriteString("_")
+			} else {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		b.WriteString(word)
+		n++
+	}
+	return b.String()
+}
+
+// splitKey splits a model.Key into msgctxt and msgid
+func splitKey(key string) (string, string) {
+	if msgctxt, msgid, ok := strings.Cut(key, "\x04"); ok {
+		return msgctxt, msgid
+	}
+	return "", key
+}
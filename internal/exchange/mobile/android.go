@@ -0,0 +1,106 @@
+package mobile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// AndroidKeyStrategies are the key strategies allowed for Android resource
+// names, which must be lowercase identifiers; the first is the default
+var AndroidKeyStrategies = []exchange.KeyStrategy{exchange.KeySnake, exchange.KeyHash}
+
+// AndroidQualifier returns the resource directory qualifier for a gettext
+// language code: "sv" → "sv", "pt_BR" → "pt-rBR", "zh_Hans" → "b+zh+Hans"
+func AndroidQualifier(lang string) string {
+	parts := strings.FieldsFunc(lang, func(r rune) bool { return r == '_' || r == '-' })
+	switch {
+	case len(parts) == 1:
+		return parts[0]
+	case len(parts) == 2 && len(parts[1]) == 2:
+		return parts[0] + "-r" + strings.ToUpper(parts[1])
+	default:
+		return "b+" + strings.Join(parts, "+")
+	}
+}
+
+// WriteAndroid writes the translated units as an Android string resource
+// file (res/values-<lang>/strings.xml), with names by model.Key (see
+// exchange.Keys).
+// Plural entries become <plurals> resources with one item per quantity, and
+// interpolations (%{name}) become format arguments: %d for the count, %s
+// for others, positional (%1$s) when there are several. Developer comments
+// are written as XML comments.
+func WriteAndroid(w io.Writer, units []*exchange.Unit, categories []string, keys map[string]string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n")
+	bw.WriteString("<resources>\n")
+
+	for _, entry := range exchange.Group(units) {
+		if !translated(entry) {
+			continue
+		}
+		unit := entry[0]
+		name := keys[model.Key(unit.MsgCtxt, unit.MsgID)]
+		args := arguments(unit)
+		value := func(s string) string {
+			return androidEscape(formatString(s, args, androidSpec))
+		}
+
+		for _, comment := range unit.ExtractedComments {
+			fmt.Fprintf(bw, "    <!-- %s -->\n", xmlComment(comment))
+		}
+		if !unit.IsPlural() {
+			fmt.Fprintf(bw, "    <string name=\"%s\">%s</string>\n", name, value(unit.Target))
+			continue
+		}
+
+		fmt.Fprintf(bw, "    <plurals name=\"%s\">\n", name)
+		for _, q := range quantities(entry, categories) {
+			fmt.Fprintf(bw, "        <item quantity=\"%s\">%s</item>\n", q[0], value(q[1]))
+		}
+		bw.WriteString("    </plurals>\n")
+	}
+
+	bw.WriteString("</resources>\n")
+	return bw.Flush()
+}
+
+// androidSpec returns the format conversion for an argument
+func androidSpec(name string) string {
+	if name == countArgument {
+		return "d"
+	}
+	return "s"
+}
+
+// androidEscaper escapes text for Android string resources: XML markup,
+// and the quotes, backslashes and control characters aapt interprets
+var androidEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+	"\\", "\\\\", "'", "\\'", "\"", "\\\"",
+	"\n", "\\n", "\t", "\\t", "\r", "",
+)
+
+// androidEscape escapes a string resource value. Values that start with @ or
+// ? would be read as references; values with leading, trailing or repeated
+// spaces are quoted, as aapt collapses whitespace otherwise.
+func androidEscape(s string) string {
+	escaped := androidEscaper.Replace(s)
+	if strings.HasPrefix(escaped, "@") || strings.HasPrefix(escaped, "?") {
+		escaped = "\\" + escaped
+	}
+	if strings.Trim(s, " ") != s || strings.Contains(s, "  ") {
+		escaped = "\"" + escaped + "\""
+	}
+	return escaped
+}
+
+// xmlComment makes text safe to put in an XML comment
+func xmlComment(text string) string {
+	return strings.ReplaceAll(text, "--", "- -")
+}
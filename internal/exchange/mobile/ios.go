@@ -0,0 +1,105 @@
+package mobile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// IOSKeyStrategies are the key strategies allowed for Apple .strings keys;
+// the first is the default
+var IOSKeyStrategies = []exchange.KeyStrategy{exchange.KeyMsgID, exchange.KeySnake, exchange.KeyCamel, exchange.KeyHash}
+
+// LprojName returns the .lproj directory name for a gettext language code:
+// "sv" → "sv.lproj", "pt_BR" → "pt-BR.lproj"
+func LprojName(lang string) string {
+	return strings.ReplaceAll(lang, "_", "-") + ".lproj"
+}
+
+// HasPlurals reports whether units include plural entries, which go in a
+// .stringsdict file
+func HasPlurals(units []*exchange.Unit) bool {
+	for _, unit := range units {
+		if unit.IsPlural() {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteStrings writes the translated singular units as an Apple .strings
+// file (Localizable.strings), with keys by model.Key (see exchange.Keys). Interpolations
+// (%{name}) become format arguments: %@, positional (%1$@) when there are
+// several. Developer comments are written as comments.
+func WriteStrings(w io.Writer, units []*exchange.Unit, keys map[string]string) error {
+	bw := bufio.NewWriter(w)
+	first := true
+	for _, entry := range exchange.Group(units) {
+		unit := entry[0]
+		if unit.IsPlural() || !translated(entry) {
+			continue
+		}
+
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+		if len(unit.ExtractedComments) > 0 {
+			fmt.Fprintf(bw, "/* %s */\n", strings.ReplaceAll(strings.Join(unit.ExtractedComments, "\n"), "*/", "* /"))
+		}
+		value := formatString(unit.Target, arguments(unit), iosSpec)
+		fmt.Fprintf(bw, "\"%s\" = \"%s\";\n", stringsEscaper.Replace(keys[model.Key(unit.MsgCtxt, unit.MsgID)]), stringsEscaper.Replace(value))
+	}
+	return bw.Flush()
+}
+
+// WriteStringsdict writes the translated plural units as an Apple
+// .stringsdict property list (Localizable.stringsdict), with keys by
+// model.Key (see exchange.Keys). Each entry has a plural rule on the count (the first
+// argument), with a string per CLDR category.
+func WriteStringsdict(w io.Writer, units []*exchange.Unit, categories []string, keys map[string]string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	bw.WriteString("<!DOCTYPE plist PUBLIC \"-//Apple//DTD PLIST 1.0//EN\" \"http://www.apple.com/DTDs/PropertyList-1.0.dtd\">\n")
+	bw.WriteString("<plist version=\"1.0\">\n<dict>\n")
+
+	for _, entry := range exchange.Group(units) {
+		unit := entry[0]
+		if !unit.IsPlural() || !translated(entry) {
+			continue
+		}
+		args := arguments(unit)
+
+		fmt.Fprintf(bw, "\t<key>%s</key>\n\t<dict>\n", plistEscaper.Replace(keys[model.Key(unit.MsgCtxt, unit.MsgID)]))
+		fmt.Fprintf(bw, "\t\t<key>NSStringLocalizedFormatKey</key>\n\t\t<string>%%1$#@%s@</string>\n", countArgument)
+		fmt.Fprintf(bw, "\t\t<key>%s</key>\n\t\t<dict>\n", countArgument)
+		bw.WriteString("\t\t\t<key>NSStringFormatSpecTypeKey</key>\n\t\t\t<string>NSStringPluralRuleType</string>\n")
+		bw.WriteString("\t\t\t<key>NSStringFormatValueTypeKey</key>\n\t\t\t<string>ld</string>\n")
+		for _, q := range quantities(entry, categories) {
+			value := formatString(q[1], args, iosSpec)
+			fmt.Fprintf(bw, "\t\t\t<key>%s</key>\n\t\t\t<string>%s</string>\n", q[0], plistEscaper.Replace(value))
+		}
+		bw.WriteString("\t\t</dict>\n\t</dict>\n")
+	}
+
+	bw.WriteString("</dict>\n</plist>\n")
+	return bw.Flush()
+}
+
+// iosSpec returns the format conversion for an argument
+func iosSpec(name string) string {
+	if name == countArgument {
+		return "ld"
+	}
+	return "@"
+}
+
+// stringsEscaper escapes keys and values in .strings files
+var stringsEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n", "\t", "\\t", "\r", "\\r")
+
+// plistEscaper escapes text in property lists
+var plistEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
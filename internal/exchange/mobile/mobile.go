@@ -0,0 +1,90 @@
+// Package mobile writes catalogs as Android string resources and Apple
+// .strings/.stringsdict files for mobile apps
+package mobile
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+// countArgument is the interpolation that holds the count in plural entries
+const countArgument = "count"
+
+// interpolation matches Elixir gettext interpolations like %{name}, and
+// other percent signs
+var interpolation = regexp.MustCompile(`%(\{\w+\})?`)
+
+// arguments returns the interpolation names of an entry in argument order:
+// count first for plural entries, then the others in order of appearance in
+// the msgid and msgid_plural. Translations use the same argument positions
+// whatever order they put them in.
+func arguments(unit *exchange.Unit) []string {
+	var args []string
+	if unit.IsPlural() {
+		args = append(args, countArgument)
+	}
+	for _, m := range interpolation.FindAllStringSubmatch(unit.MsgID+" "+unit.MsgIDPlural, -1) {
+		name := strings.Trim(m[1], "{}")
+		if name != "" && !slices.Contains(args, name) {
+			args = append(args, name)
+		}
+	}
+	return args
+}
+
+// formatString turns interpolations in s into printf-style specifiers for
+// args: spec(name) for a single argument, positional ones (%1$s) for several.
+// Other percent signs are doubled, as the string is used as a format. Strings
+// without arguments are returned unchanged.
+func formatString(s string, args []string, spec func(name string) string) string {
+	if len(args) == 0 {
+		return s
+	}
+	return interpolation.ReplaceAllStringFunc(s, func(m string) string {
+		name := strings.Trim(m[1:], "{}")
+		if name == "" {
+			return "%%"
+		}
+		for i, arg := range args {
+			if arg == name {
+				if len(args) == 1 {
+					return "%" + spec(name)
+				}
+				return "%" + strconv.Itoa(i+1) + "$" + spec(name)
+			}
+		}
+		return m // Not in the msgid; left for the translator to notice
+	})
+}
+
+// quantities returns the plural categories of a complete plural entry's forms
+// paired with their text. Mobile platforms require an "other" quantity, so
+// if the language's categories have none, the last form is used for it too.
+func quantities(forms []*exchange.Unit, categories []string) [][2]string {
+	var q [][2]string
+	hasOther := false
+	for _, form := range forms {
+		category := exchange.PluralCategory(categories, form.PluralIndex)
+		hasOther = hasOther || category == "other"
+		q = append(q, [2]string{category, form.Target})
+	}
+	if !hasOther && len(forms) > 0 {
+		q = append(q, [2]string{"other", forms[len(forms)-1].Target})
+	}
+	return q
+}
+
+// translated reports whether all units of an entry are translated and not
+// fuzzy; apps fall back to their default resources for other entries
+func translated(units []*exchange.Unit) bool {
+	for _, unit := range units {
+		if unit.Target == "" || unit.Fuzzy {
+			return false
+		}
+	}
+	return true
+}
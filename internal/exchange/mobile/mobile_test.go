@@ -0,0 +1,103 @@
+package mobile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+var categories = []string{"one", "other"}
+
+func testUnits() []*exchange.Unit {
+	return []*exchange.Unit{
+		{PluralIndex: -1, MsgID: "Sign In", Target: "Logga in", ExtractedComments: []string{"Login -- page"}},
+		{PluralIndex: -1, MsgCtxt: "menu", MsgID: "Sign In", Target: " Logga in "},
+		{PluralIndex: -1, MsgID: "It's @home", Target: "Det är \"hemma\" & <b>'s\n"},
+		{PluralIndex: -1, MsgID: "Hi %{name}, %{count} new", Target: "Hej %{name}, %{count} nya (100%)"},
+		{PluralIndex: -1, MsgID: "Fuzzy", Target: "Luddig", Fuzzy: true},
+		{PluralIndex: 0, MsgID: "%{count} file", MsgIDPlural: "%{count} files", Target: "%{count} fil"},
+		{PluralIndex: 1, MsgID: "%{count} file", MsgIDPlural: "%{count} files", Target: "%{count} filer"},
+	}
+}
+
+func TestWriteAndroid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAndroid(&buf, testUnits(), categories, exchange.Keys(testUnits(), exchange.KeySnake)); err != nil {
+		t.Fatalf("WriteAndroid failed: %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Login - - page -->
+    <string name="sign_in">Logga in</string>
+    <string name="menu_sign_in">" Logga in "</string>
+    <string name="it_s_home">Det är \"hemma\" &amp; &lt;b&gt;\'s\n</string>
+    <string name="hi_name_count_new">Hej %1$s, %2$d nya (100%%)</string>
+    <plurals name="count_file">
+        <item quantity="one">%d fil</item>
+        <item quantity="other">%d filer</item>
+    </plurals>
+</resources>
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestWriteStrings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStrings(&buf, testUnits(), exchange.Keys(testUnits(), exchange.KeyMsgID)); err != nil {
+		t.Fatalf("WriteStrings failed: %v", err)
+	}
+
+	expected := `/* Login -- page */
+"Sign In" = "Logga in";
+
+"menu::Sign In" = " Logga in ";
+
+"It's @home" = "Det är \"hemma\" & <b>'s\n";
+
+"Hi %{name}, %{count} new" = "Hej %1$@, %2$ld nya (100%%)";
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestWriteStringsdict(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStringsdict(&buf, testUnits(), []string{"one", "few", "many"}, exchange.Keys(testUnits(), exchange.KeyMsgID)); err != nil {
+		t.Fatalf("WriteStringsdict failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<key>%{count} file</key>",
+		"<string>%1$#@count@</string>",
+		"<string>NSStringPluralRuleType</string>",
+		"<key>one</key>\n\t\t\t<string>%ld fil</string>",
+		"<key>few</key>\n\t\t\t<string>%ld filer</string>",
+		// Without an "other" category, the last form is used for it
+		"<key>other</key>\n\t\t\t<string>%ld filer</string>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Sign In") {
+		t.Errorf("expected only plural entries\n%s", out)
+	}
+}
+
+func TestQualifiers(t *testing.T) {
+	for lang, expected := range map[string]string{"sv": "sv", "pt_BR": "pt-rBR", "zh_Hans": "b+zh+Hans"} {
+		if got := AndroidQualifier(lang); got != expected {
+			t.Errorf("AndroidQualifier(%q) = %q, expected %q", lang, got, expected)
+		}
+	}
+	if got := LprojName("pt_BR"); got != "pt-BR.lproj" {
+		t.Errorf("expected pt-BR.lproj, got %q", got)
+	}
+}
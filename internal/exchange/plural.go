@@ -0,0 +1,74 @@
+package exchange
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// pluralCategories lists the CLDR plural categories of languages whose
+// gettext plural forms are not the usual ones for their count, in gettext
+// form order
+var pluralCategories = map[string][]string{
+	"cs": {"one", "few", "other"},
+	"sk": {"one", "few", "other"},
+	"lt": {"one", "few", "other"},
+	"ro": {"one", "few", "other"},
+	"hr": {"one", "few", "other"},
+	"sr": {"one", "few", "other"},
+	"bs": {"one", "few", "other"},
+	"lv": {"zero", "one", "other"},
+	"sl": {"one", "two", "few", "other"},
+	"ga": {"one", "two", "few", "many", "other"},
+}
+
+// defaultPluralCategories are the CLDR plural categories for a number of
+// gettext plural forms, used for languages not in pluralCategories
+var defaultPluralCategories = map[int][]string{
+	1: {"other"},
+	2: {"one", "other"},
+	3: {"one", "few", "many"},
+	4: {"one", "two", "few", "other"},
+	5: {"one", "two", "few", "many", "other"},
+	6: {"zero", "one", "two", "few", "many", "other"},
+}
+
+// PluralCategories returns the CLDR plural category ("one", "few", "other",
+// ...) of each of a language's nplurals gettext plural forms, in form order.
+// Formats like i18next and ICU messages name plural forms by category rather
+// than by index. Form counts without a known mapping get "formN" names.
+func PluralCategories(lang string, nplurals int) []string {
+	if nplurals <= 0 {
+		nplurals = 2
+	}
+
+	base, _, _ := strings.Cut(strings.ReplaceAll(lang, "-", "_"), "_")
+	if categories := pluralCategories[strings.ToLower(base)]; len(categories) == nplurals {
+		return categories
+	}
+	if categories, ok := defaultPluralCategories[nplurals]; ok {
+		return categories
+	}
+
+	categories := make([]string, nplurals)
+	for i := range categories {
+		categories[i] = "form" + strconv.Itoa(i)
+	}
+	return categories
+}
+
+// CatalogPluralCategories returns the plural categories for a catalog in lang,
+// using the header's nplurals
+func CatalogPluralCategories(catalog *po.Catalog, lang string) []string {
+	return PluralCategories(lang, catalog.Header().NPlurals())
+}
+
+// PluralCategory returns the category of a plural form index, or "formN" for
+// forms beyond the known categories
+func PluralCategory(categories []string, index int) string {
+	if index >= 0 && index < len(categories) {
+		return categories[index]
+	}
+	return "form" + strconv.Itoa(index)
+}
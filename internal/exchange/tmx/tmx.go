@@ -0,0 +1,361 @@
+// Package tmx reads and writes TMX 1.4 translation memories, to hand
+// accumulated translations between tools and vendors
+package tmx
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// allLanguages is the srclang of documents whose units may each have their
+// source in any language
+const allLanguages = "*all*"
+
+// Properties recording gettext keys on translation units
+const (
+	propContext    = "x-context"     // msgctxt
+	propPluralForm = "x-plural-form" // Plural form index; the source is msgid_plural for forms > 0
+)
+
+// Memory is a multilingual translation memory
+type Memory struct {
+	SourceLanguage string
+	Units          []*Unit
+	index          map[string]*Unit
+}
+
+// Unit is a translation unit: a source string, keyed like a gettext entry
+// or plural form, with its translation in each language
+type Unit struct {
+	ID           string
+	MsgCtxt      string
+	PluralIndex  int // Plural form index, -1 for singular entries
+	Source       string
+	Translations map[string]string // By normalized language code (see NormalizeLanguage)
+}
+
+// NewMemory creates an empty translation memory
+func NewMemory(sourceLanguage string) *Memory {
+	return &Memory{SourceLanguage: sourceLanguage, index: make(map[string]*Unit)}
+}
+
+// NormalizeLanguage normalizes a language code for comparison: "pt_BR" and
+// "pt-br" are both "pt-BR"
+func NormalizeLanguage(lang string) string {
+	parts := strings.FieldsFunc(lang, func(r rune) bool { return r == '_' || r == '-' })
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// unitKey identifies a unit by msgctxt, plural form and source text
+func unitKey(msgctxt string, pluralIndex int, source string) string {
+	return model.Key(msgctxt, source) + "\x00" + strconv.Itoa(pluralIndex)
+}
+
+// Add records the translated, non-fuzzy entries of a catalog in lang. The
+// first translation recorded for a source and language is kept.
+func (m *Memory) Add(catalog *po.Catalog, lang string) {
+	lang = NormalizeLanguage(lang)
+	for _, unit := range exchange.Units(catalog) {
+		if unit.Target == "" || unit.Fuzzy {
+			continue
+		}
+		tu := m.unit(unit.MsgCtxt, unit.PluralIndex, unit.Source)
+		if tu.ID == "" {
+			tu.ID = exchange.UnitID(unit.MsgCtxt, unit.MsgID, unit.PluralIndex)
+		}
+		if _, ok := tu.Translations[lang]; !ok {
+			tu.Translations[lang] = unit.Target
+		}
+	}
+}
+
+// unit returns the unit for a key, adding it if there is none
+func (m *Memory) unit(msgctxt string, pluralIndex int, source string) *Unit {
+	key := unitKey(msgctxt, pluralIndex, source)
+	if tu, ok := m.index[key]; ok {
+		return tu
+	}
+	tu := &Unit{MsgCtxt: msgctxt, PluralIndex: pluralIndex, Source: source, Translations: make(map[string]string)}
+	m.index[key] = tu
+	m.Units = append(m.Units, tu)
+	return tu
+}
+
+// Lookup returns the translation in lang of the source with the given
+// msgctxt and plural form (-1 for singular), if the memory has an exact match.
+// Without a translation in lang itself, one in a variant of the same language
+// is used: "de-DE" for "de", or "pt" for "pt-BR".
+func (m *Memory) Lookup(msgctxt string, pluralIndex int, source, lang string) (string, bool) {
+	tu, ok := m.index[unitKey(msgctxt, pluralIndex, source)]
+	if !ok {
+		return "", false
+	}
+	lang = NormalizeLanguage(lang)
+	if translation := tu.Translations[lang]; translation != "" {
+		return translation, true
+	}
+
+	base, _, _ := strings.Cut(lang, "-")
+	if translation := tu.Translations[base]; translation != "" {
+		return translation, true
+	}
+	variants := make([]string, 0, len(tu.Translations))
+	for variant := range tu.Translations {
+		if strings.HasPrefix(variant, base+"-") {
+			variants = append(variants, variant)
+		}
+	}
+	slices.Sort(variants)
+	for _, variant := range variants {
+		if translation := tu.Translations[variant]; translation != "" {
+			return translation, true
+		}
+	}
+	return "", false
+}
+
+// Languages returns the target languages in the memory, sorted
+func (m *Memory) Languages() []string {
+	var languages []string
+	for _, tu := range m.Units {
+		for lang := range tu.Translations {
+			if !slices.Contains(languages, lang) {
+				languages = append(languages, lang)
+			}
+		}
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// Fill sets the empty msgstrs (and msgstr[N]) of catalog's live entries to
+// exact matches from the memory in lang, and returns the entries filled.
+// Filled entries are flagged fuzzy if fuzzy is set.
+func (m *Memory) Fill(catalog *po.Catalog, lang string, fuzzy bool) []*model.MsgEntry {
+	var filled []*model.MsgEntry
+	for _, entry := range catalog.Entries() {
+		if entry.Obsolete {
+			continue
+		}
+
+		changed := false
+		if !entry.IsPlural() {
+			if translation, ok := m.Lookup(entry.MsgCtxt, -1, entry.MsgID, lang); ok && entry.MsgStr == "" {
+				entry.MsgStr = translation
+				changed = true
+			}
+		} else {
+			forms := max(catalog.Header().NPlurals(), len(entry.MsgStrPlural), 2)
+			for i := 0; i < forms; i++ {
+				if i < len(entry.MsgStrPlural) && entry.MsgStrPlural[i] != "" {
+					continue
+				}
+				source := entry.MsgIDPlural
+				if i == 0 {
+					source = entry.MsgID
+				}
+				translation, ok := m.Lookup(entry.MsgCtxt, i, source, lang)
+				if !ok {
+					continue
+				}
+				for len(entry.MsgStrPlural) <= i {
+					entry.MsgStrPlural = append(entry.MsgStrPlural, "")
+				}
+				entry.MsgStrPlural[i] = translation
+				changed = true
+			}
+		}
+
+		if changed {
+			if fuzzy {
+				entry.AddFlag(model.FlagFuzzy)
+			}
+			filled = append(filled, entry)
+		}
+	}
+	return filled
+}
+
+// Write writes the memory as a TMX 1.4 document. Units without any
+// translation are left out.
+func Write(w io.Writer, m *Memory, creationToolVersion string) error {
+	bw := bufio.NewWriter(w)
+	srclang := exchange.EscapeXMLAttr(m.SourceLanguage)
+
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	bw.WriteString("<tmx version=\"1.4\">\n")
+	fmt.Fprintf(bw, "  <header creationtool=\"poflow\" creationtoolversion=\"%s\" segtype=\"sentence\" o-tmf=\"PO\" adminlang=\"en\" srclang=\"%s\" datatype=\"plaintext\"/>\n",
+		exchange.EscapeXMLAttr(creationToolVersion), srclang)
+	bw.WriteString("  <body>\n")
+
+	for _, tu := range m.Units {
+		if len(tu.Translations) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "    <tu tuid=\"%s\">\n", exchange.EscapeXMLAttr(tu.ID))
+		if tu.MsgCtxt != "" {
+			fmt.Fprintf(bw, "      <prop type=\"%s\">%s</prop>\n", propContext, exchange.EscapeXMLText(tu.MsgCtxt))
+		}
+		if tu.PluralIndex >= 0 {
+			fmt.Fprintf(bw, "      <prop type=\"%s\">%d</prop>\n", propPluralForm, tu.PluralIndex)
+		}
+		fmt.Fprintf(bw, "      <tuv xml:lang=\"%s\"><seg>%s</seg></tuv>\n", srclang, exchange.EscapeXMLText(tu.Source))
+
+		languages := make([]string, 0, len(tu.Translations))
+		for lang := range tu.Translations {
+			languages = append(languages, lang)
+		}
+		slices.Sort(languages)
+		for _, lang := range languages {
+			fmt.Fprintf(bw, "      <tuv xml:lang=\"%s\"><seg>%s</seg></tuv>\n", exchange.EscapeXMLAttr(lang), exchange.EscapeXMLText(tu.Translations[lang]))
+		}
+		bw.WriteString("    </tu>\n")
+	}
+
+	bw.WriteString("  </body>\n")
+	bw.WriteString("</tmx>\n")
+	return bw.Flush()
+}
+
+// Read reads a TMX document. The source language of a unit is its own
+// srclang, or the header's; for "*all*" it is the language of its first tuv.
+// Units without a tuv in their source language are skipped, and a document
+// where every unit is skipped is an error. The text of inline markup in
+// segments is kept, the markup itself dropped.
+func Read(r io.Reader) (*Memory, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		m       *Memory
+		tu      *Unit
+		tuvs    map[string]string
+		srclang string // Source language of the current tu
+		prop    string // Type of the current prop
+		lang    string // Language of the current tuv
+		content *strings.Builder
+		read    int // Units read, including those skipped
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid TMX: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tmx":
+				if version := exchange.XMLAttr(t, "version"); !strings.HasPrefix(version, "1.") {
+					return nil, fmt.Errorf("unsupported TMX version %q", version)
+				}
+			case "header":
+				m = NewMemory(exchange.XMLAttr(t, "srclang"))
+			case "tu":
+				if m == nil {
+					return nil, fmt.Errorf("invalid TMX: <tu> before <header>")
+				}
+				tu = &Unit{ID: exchange.XMLAttr(t, "tuid"), PluralIndex: -1}
+				tuvs = make(map[string]string)
+				srclang = exchange.XMLAttr(t, "srclang")
+				if srclang == "" {
+					srclang = m.SourceLanguage
+				}
+			case "prop":
+				prop = exchange.XMLAttr(t, "type")
+				content = &strings.Builder{}
+			case "tuv":
+				lang = exchange.XMLAttr(t, "lang") // xml:lang, or lang in TMX 1.1
+				if tu != nil && srclang == allLanguages {
+					srclang = lang
+				}
+			case "seg":
+				content = &strings.Builder{}
+			}
+
+		case xml.CharData:
+			if content != nil {
+				content.Write(t)
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "prop":
+				if tu != nil && content != nil {
+					switch prop {
+					case propContext:
+						tu.MsgCtxt = content.String()
+					case propPluralForm:
+						if index, err := strconv.Atoi(strings.TrimSpace(content.String())); err == nil && index >= 0 {
+							tu.PluralIndex = index
+						}
+					}
+				}
+				content = nil
+			case "seg":
+				if tu != nil && content != nil {
+					tuvs[NormalizeLanguage(lang)] = content.String()
+				}
+				content = nil
+			case "tu":
+				if tu != nil {
+					read++
+					m.addUnit(tu, tuvs, srclang)
+				}
+				tu = nil
+			}
+		}
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("not a TMX document")
+	}
+	if read > 0 && len(m.Units) == 0 {
+		return nil, fmt.Errorf("none of the %d translation unit(s) has a tuv in its source language (srclang %q)", read, m.SourceLanguage)
+	}
+	return m, nil
+}
+
+// addUnit adds a unit read from a document, given the segments of its tuvs
+// by language and its source language
+func (m *Memory) addUnit(read *Unit, tuvs map[string]string, srclang string) {
+	srclang = NormalizeLanguage(srclang)
+	source, ok := tuvs[srclang]
+	if !ok {
+		return
+	}
+	tu := m.unit(read.MsgCtxt, read.PluralIndex, source)
+	if tu.ID == "" {
+		tu.ID = read.ID
+	}
+	for lang, seg := range tuvs {
+		if lang == srclang {
+			continue
+		}
+		if _, ok := tu.Translations[lang]; !ok {
+			tu.Translations[lang] = seg
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package tmx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/testutil"
+)
+
+func testMemory(t *testing.T) *Memory {
+	memory := NewMemory("en")
+	memory.Add(testutil.ReadCatalog(t, `msgid "Sign In"
+msgstr "Logga in"
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+#, fuzzy
+msgid "Sign Out"
+msgstr "Logga ut"
+
+msgid "Save & <close>"
+msgstr "Spara & <stäng>"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] "%d filer"
+`), "sv")
+	memory.Add(testutil.ReadCatalog(t, `msgid "Sign In"
+msgstr "Anmelden"
+
+msgid "Untranslated"
+msgstr ""
+`), "de_DE")
+	return memory
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testMemory(t), "1.0"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		`<tmx version="1.4">`,
+		`srclang="en"`,
+		`<prop type="x-context">button</prop>`,
+		`<prop type="x-plural-form">1</prop>`,
+		`<tuv xml:lang="en"><seg>%d files</seg></tuv>`,
+		`<tuv xml:lang="de-DE"><seg>Anmelden</seg></tuv>`,
+		`<seg>Spara &amp; &lt;stäng&gt;</seg>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"Sign Out", "Untranslated"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("output contains %q, which has no translation:\n%s", unwanted, output)
+		}
+	}
+
+	memory, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	tests := []struct {
+		msgctxt     string
+		pluralIndex int
+		source      string
+		lang        string
+		want        string
+	}{
+		{"", -1, "Sign In", "sv", "Logga in"},
+		{"", -1, "Sign In", "de_DE", "Anmelden"},
+		{"", -1, "Sign In", "de", "Anmelden"},
+		{"", -1, "Sign In", "sv-FI", "Logga in"},
+		{"button", -1, "Open", "sv", "Öppna"},
+		{"", -1, "Save & <close>", "sv", "Spara & <stäng>"},
+		{"", 0, "%d file", "sv", "%d fil"},
+		{"", 1, "%d files", "sv", "%d filer"},
+	}
+	for _, tt := range tests {
+		got, ok := memory.Lookup(tt.msgctxt, tt.pluralIndex, tt.source, tt.lang)
+		if !ok || got != tt.want {
+			t.Errorf("Lookup(%q, %d, %q, %q) = %q, %v, want %q", tt.msgctxt, tt.pluralIndex, tt.source, tt.lang, got, ok, tt.want)
+		}
+	}
+	if _, ok := memory.Lookup("", -1, "Open", "sv"); ok {
+		t.Errorf("Lookup without the context matched the button::Open unit")
+	}
+	if got := strings.Join(memory.Languages(), ","); got != "de-DE,sv" {
+		t.Errorf("Languages() = %s, want de-DE,sv", got)
+	}
+}
+
+func TestReadForeign(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="Other" segtype="sentence" o-tmf="x" adminlang="en-US" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu>
+      <tuv xml:lang="en-us"><seg>Welcome <bpt i="1">&lt;b&gt;</bpt>home<ept i="1">&lt;/b&gt;</ept></seg></tuv>
+      <tuv xml:lang="sv-SE"><seg>Välkommen hem</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="fr"><seg>Bonjour</seg></tuv>
+    </tu>
+  </body>
+</tmx>
+`
+	memory, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(memory.Units) != 1 {
+		t.Fatalf("got %d units, want 1 (the unit without a source is skipped)", len(memory.Units))
+	}
+	if got, ok := memory.Lookup("", -1, "Welcome <b>home</b>", "sv_SE"); !ok || got != "Välkommen hem" {
+		t.Errorf("Lookup = %q, %v, want Välkommen hem", got, ok)
+	}
+
+	if _, err := Read(strings.NewReader(`<xliff version="1.2"></xliff>`)); err == nil {
+		t.Errorf("Read of a non-TMX document succeeded")
+	}
+}
+
+func TestReadAllLanguages(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="Vendor" segtype="sentence" o-tmf="x" adminlang="en" srclang="*all*" datatype="plaintext"/>
+  <body>
+    <tu>
+      <tuv xml:lang="en"><seg>Welcome</seg></tuv>
+      <tuv xml:lang="sv"><seg>Välkommen</seg></tuv>
+    </tu>
+    <tu srclang="en">
+      <tuv xml:lang="sv"><seg>Öppna</seg></tuv>
+      <tuv xml:lang="en"><seg>Open</seg></tuv>
+    </tu>
+  </body>
+</tmx>
+`
+	memory, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got, ok := memory.Lookup("", -1, "Welcome", "sv"); !ok || got != "Välkommen" {
+		t.Errorf("Lookup(Welcome) = %q, %v, want the first tuv as source", got, ok)
+	}
+	if got, ok := memory.Lookup("", -1, "Open", "sv"); !ok || got != "Öppna" {
+		t.Errorf("Lookup(Open) = %q, %v, want the tu's srclang as source", got, ok)
+	}
+
+	// A document none of whose units can be read is an error
+	noSource := strings.Replace(input, `srclang="*all*"`, `srclang="de"`, 1)
+	noSource = strings.Replace(noSource, `<tu srclang="en">`, `<tu>`, 1)
+	if _, err := Read(strings.NewReader(noSource)); err == nil {
+		t.Error("expected an error when no unit has a tuv in the source language")
+	}
+}
+
+func TestFill(t *testing.T) {
+	catalog := testutil.ReadCatalog(t, `msgid ""
+msgstr ""
+"Language: sv\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgid "Sign In"
+msgstr ""
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna nu"
+
+msgid "Save & <close>"
+msgstr ""
+
+msgid "Unknown"
+msgstr ""
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] ""
+
+#~ msgid "Sign In"
+#~ msgstr ""
+`)
+
+	filled := testMemory(t).Fill(catalog, "sv", true)
+	if len(filled) != 3 {
+		t.Fatalf("filled %d entries, want 3", len(filled))
+	}
+
+	if got := catalog.Get("", "Sign In").MsgStr; got != "Logga in" {
+		t.Errorf("Sign In = %q, want Logga in", got)
+	}
+	if !catalog.Get("", "Sign In").IsFuzzy() {
+		t.Errorf("filled entry not marked fuzzy")
+	}
+	if got := catalog.Get("button", "Open").MsgStr; got != "Öppna nu" {
+		t.Errorf("existing translation changed to %q", got)
+	}
+	if got := catalog.Get("", "Unknown").MsgStr; got != "" {
+		t.Errorf("Unknown = %q, want it left empty", got)
+	}
+	plural := catalog.Get("", "%d file")
+	if plural.MsgStrPlural[0] != "%d fil" || plural.MsgStrPlural[1] != "%d filer" {
+		t.Errorf("plural forms = %q, want [%%d fil %%d filer]", plural.MsgStrPlural)
+	}
+}
@@ -0,0 +1,295 @@
+// Package xliff reads and writes XLIFF 1.2 and 2.0 documents for exchanging
+// catalogs with translation agencies and CAT tools
+package xliff
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+// Supported XLIFF versions
+const (
+	Version12 = "1.2"
+	Version20 = "2.0"
+)
+
+const (
+	namespace12 = "urn:oasis:names:tc:xliff:document:1.2"
+	namespace20 = "urn:oasis:names:tc:xliff:document:2.0"
+
+	// pluralGroup is the restype of the group holding an entry's plural forms
+	pluralGroup = "x-gettext-plurals"
+)
+
+// Document is a single-file XLIFF document for one target language
+type Document struct {
+	Version        string
+	Original       string // Path of the .po file the units were exported from
+	SourceLanguage string
+	TargetLanguage string
+	Units          []*exchange.Unit
+}
+
+// Write writes doc to w as XLIFF 1.2 or 2.0, according to doc.Version
+func Write(w io.Writer, doc *Document) error {
+	bw := bufio.NewWriter(w)
+	x := &xmlWriter{w: bw}
+
+	x.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	switch doc.Version {
+	case Version12:
+		writeVersion12(x, doc)
+	case Version20:
+		writeVersion20(x, doc)
+	default:
+		return fmt.Errorf("unsupported XLIFF version %q (use %s or %s)", doc.Version, Version12, Version20)
+	}
+
+	if x.err != nil {
+		return x.err
+	}
+	return bw.Flush()
+}
+
+// writeVersion12 writes doc as XLIFF 1.2; plural forms are grouped per entry
+func writeVersion12(x *xmlWriter, doc *Document) {
+	x.printf("<xliff version=\"1.2\" xmlns=\"%s\">\n", namespace12)
+	x.printf("  <file original=\"%s\" source-language=\"%s\" target-language=\"%s\" datatype=\"po\">\n",
+		attr(doc.Original), attr(doc.SourceLanguage), attr(doc.TargetLanguage))
+	x.printf("    <body>\n")
+
+	for i := 0; i < len(doc.Units); {
+		unit := doc.Units[i]
+		if !unit.IsPlural() {
+			writeTransUnit(x, unit, "      ")
+			i++
+			continue
+		}
+
+		forms := pluralForms(doc.Units[i:])
+		x.printf("      <group id=\"%s\" restype=\"%s\">\n", attr(exchange.UnitID(unit.MsgCtxt, unit.MsgID, -1)), pluralGroup)
+		for _, form := range forms {
+			writeTransUnit(x, form, "        ")
+		}
+		x.printf("      </group>\n")
+		i += len(forms)
+	}
+
+	x.printf("    </body>\n")
+	x.printf("  </file>\n")
+	x.printf("</xliff>\n")
+}
+
+// writeTransUnit writes a single XLIFF 1.2 trans-unit
+func writeTransUnit(x *xmlWriter, unit *exchange.Unit, indent string) {
+	state := "translated"
+	switch {
+	case unit.Target == "":
+		state = "needs-translation"
+	case unit.Fuzzy:
+		state = "needs-review-translation"
+	}
+
+	x.printf("%s<trans-unit id=\"%s\" xml:space=\"preserve\">\n", indent, attr(unit.ID))
+	x.printf("%s  <source>%s</source>\n", indent, text(unit.Source))
+	x.printf("%s  <target state=\"%s\">%s</target>\n", indent, state, text(unit.Target))
+	for _, note := range notes(unit) {
+		x.printf("%s  <note from=\"%s\">%s</note>\n", indent, note.category, text(note.text))
+	}
+	x.printf("%s</trans-unit>\n", indent)
+}
+
+// writeVersion20 writes doc as XLIFF 2.0; every plural form is its own unit
+func writeVersion20(x *xmlWriter, doc *Document) {
+	x.printf("<xliff version=\"2.0\" xmlns=\"%s\" srcLang=\"%s\" trgLang=\"%s\">\n",
+		namespace20, attr(doc.SourceLanguage), attr(doc.TargetLanguage))
+	x.printf("  <file id=\"f1\" original=\"%s\">\n", attr(doc.Original))
+
+	for _, unit := range doc.Units {
+		state := "translated"
+		if unit.Target == "" || unit.Fuzzy {
+			state = "initial"
+		}
+
+		x.printf("    <unit id=\"%s\">\n", attr(unit.ID))
+		if n := notes(unit); len(n) > 0 {
+			x.printf("      <notes>\n")
+			for _, note := range n {
+				x.printf("        <note category=\"%s\">%s</note>\n", note.category, text(note.text))
+			}
+			x.printf("      </notes>\n")
+		}
+		x.printf("      <segment state=\"%s\">\n", state)
+		x.printf("        <source xml:space=\"preserve\">%s</source>\n", text(unit.Source))
+		x.printf("        <target xml:space=\"preserve\">%s</target>\n", text(unit.Target))
+		x.printf("      </segment>\n")
+		x.printf("    </unit>\n")
+	}
+
+	x.printf("  </file>\n")
+	x.printf("</xliff>\n")
+}
+
+// pluralForms returns the leading units that are plural forms of the same entry as units[0]
+func pluralForms(units []*exchange.Unit) []*exchange.Unit {
+	first := units[0]
+	n := 1
+	for n < len(units) && units[n].IsPlural() && units[n].PluralIndex > 0 &&
+		units[n].MsgCtxt == first.MsgCtxt && units[n].MsgID == first.MsgID {
+		n++
+	}
+	return units[:n]
+}
+
+// note is an XLIFF note with its from (1.2) or category (2.0) attribute
+type note struct {
+	category string
+	text     string
+}
+
+// notes returns the notes for a unit: context, references, developer
+// (extracted) comments and translator comments
+func notes(unit *exchange.Unit) []note {
+	var n []note
+	if unit.MsgCtxt != "" {
+		n = append(n, note{"context", unit.MsgCtxt})
+	}
+	if unit.IsPlural() {
+		n = append(n, note{"plural", fmt.Sprintf("Plural form %d of %q / %q", unit.PluralIndex, unit.MsgID, unit.MsgIDPlural)})
+	}
+	if len(unit.References) > 0 {
+		n = append(n, note{"reference", strings.Join(unit.References, " ")})
+	}
+	for _, comment := range unit.ExtractedComments {
+		n = append(n, note{"developer", comment})
+	}
+	for _, comment := range unit.Comments {
+		n = append(n, note{"translator", comment})
+	}
+	return n
+}
+
+// xmlWriter writes formatted output, keeping the first error
+type xmlWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (x *xmlWriter) printf(format string, args ...any) {
+	if x.err == nil {
+		_, x.err = fmt.Fprintf(x.w, format, args...)
+	}
+}
+
+func text(s string) string {
+	return exchange.EscapeXMLText(s)
+}
+
+func attr(s string) string {
+	return exchange.EscapeXMLAttr(s)
+}
+
+// reviewedStates are the target states whose translations are imported. Units
+// without a state are imported too; all other states (new, needs-*, initial)
+// mark translations that are not ready.
+var reviewedStates = map[string]bool{
+	"translated": true,
+	"signed-off": true,
+	"final":      true,
+	"reviewed":   true,
+}
+
+// Read reads an XLIFF 1.2 or 2.0 document. Units whose target is not ready
+// (see reviewedStates) are returned with Fuzzy set.
+func Read(r io.Reader) (*Document, error) {
+	decoder := xml.NewDecoder(r)
+	doc := &Document{}
+
+	var (
+		unit    *exchange.Unit
+		state   string // State of the current unit's target (1.2) or segment (2.0)
+		content *strings.Builder
+		files   int
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLIFF: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "xliff":
+				doc.Version = exchange.XMLAttr(t, "version")
+				if doc.Version != Version12 && !strings.HasPrefix(doc.Version, "2.") {
+					return nil, fmt.Errorf("unsupported XLIFF version %q", doc.Version)
+				}
+				doc.SourceLanguage = exchange.XMLAttr(t, "srcLang")
+				doc.TargetLanguage = exchange.XMLAttr(t, "trgLang")
+			case "file":
+				files++
+				if files > 1 {
+					return nil, fmt.Errorf("XLIFF documents with more than one file are not supported")
+				}
+				doc.Original = exchange.XMLAttr(t, "original")
+				if doc.Version == Version12 {
+					doc.SourceLanguage = exchange.XMLAttr(t, "source-language")
+					doc.TargetLanguage = exchange.XMLAttr(t, "target-language")
+				}
+			case "trans-unit", "unit":
+				unit = &exchange.Unit{ID: exchange.XMLAttr(t, "id"), PluralIndex: -1}
+				state = ""
+			case "segment":
+				state = exchange.XMLAttr(t, "state")
+			case "source", "target":
+				if unit == nil {
+					return nil, fmt.Errorf("invalid XLIFF: <%s> outside a unit", t.Name.Local)
+				}
+				if t.Name.Local == "target" && doc.Version == Version12 {
+					state = exchange.XMLAttr(t, "state")
+				}
+				content = &strings.Builder{}
+			}
+
+		case xml.CharData:
+			if content != nil {
+				content.Write(t)
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "source":
+				if unit != nil && content != nil {
+					unit.Source += content.String()
+				}
+				content = nil
+			case "target":
+				if unit != nil && content != nil {
+					unit.Target += content.String()
+				}
+				content = nil
+			case "trans-unit", "unit":
+				if unit != nil {
+					unit.Fuzzy = state != "" && !reviewedStates[state]
+					doc.Units = append(doc.Units, unit)
+				}
+				unit = nil
+			}
+		}
+	}
+
+	if doc.Version == "" {
+		return nil, fmt.Errorf("not an XLIFF document")
+	}
+	return doc, nil
+}
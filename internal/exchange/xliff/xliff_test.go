@@ -0,0 +1,124 @@
+package xliff
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/exchange"
+)
+
+func testDocument(version string) *Document {
+	return &Document{
+		Version:        version,
+		Original:       "priv/gettext/sv/LC_MESSAGES/default.po",
+		SourceLanguage: "en",
+		TargetLanguage: "sv",
+		Units: []*exchange.Unit{
+			{ID: "m1", PluralIndex: -1, MsgID: "Sign In", Source: "Sign In", References: []string{"lib/login.ex:12"}, ExtractedComments: []string{"Login page"}},
+			{ID: "m2", PluralIndex: -1, MsgCtxt: "button", MsgID: "Open & <go>", Source: "Open & <go>", Target: "Öppna\n\"nu\""},
+			{ID: "m3", PluralIndex: -1, MsgID: "Hello", Source: "Hello", Target: "Hej", Fuzzy: true},
+			{ID: "m4-0", PluralIndex: 0, MsgID: "%d file", MsgIDPlural: "%d files", Source: "%d file", Target: "%d fil"},
+			{ID: "m4-1", PluralIndex: 1, MsgID: "%d file", MsgIDPlural: "%d files", Source: "%d files", Target: "%d filer"},
+		},
+	}
+}
+
+// readBack writes doc and reads it back
+func readBack(t *testing.T, doc *Document) *Document {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v\n%s", err, buf.String())
+	}
+	return got
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, version := range []string{Version12, Version20} {
+		t.Run(version, func(t *testing.T) {
+			doc := testDocument(version)
+			got := readBack(t, doc)
+
+			if got.Version != version || got.Original != doc.Original || got.SourceLanguage != "en" || got.TargetLanguage != "sv" {
+				t.Errorf("unexpected document: %+v", got)
+			}
+			if len(got.Units) != len(doc.Units) {
+				t.Fatalf("expected %d units, got %d", len(doc.Units), len(got.Units))
+			}
+			for i, unit := range got.Units {
+				want := doc.Units[i]
+				if unit.ID != want.ID || unit.Source != want.Source || unit.Target != want.Target {
+					t.Errorf("unit %d: got %+v, expected %+v", i, unit, want)
+				}
+				// Untranslated and fuzzy units are exported as not ready
+				if notReady := want.Target == "" || want.Fuzzy; unit.Fuzzy != notReady {
+					t.Errorf("unit %d: expected Fuzzy %v, got %v", i, notReady, unit.Fuzzy)
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_Notes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testDocument(Version12)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<note from="reference">lib/login.ex:12</note>`,
+		`<note from="developer">Login page</note>`,
+		`<note from="context">button</note>`,
+		`<group id="`,
+		`restype="x-gettext-plurals"`,
+		`<target state="needs-review-translation">Hej</target>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %s\n%s", want, out)
+		}
+	}
+}
+
+func TestRead_States(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="default.po" source-language="en" target-language="de" datatype="po">
+    <body>
+      <trans-unit id="a"><source>A</source><target state="final">Ä</target></trans-unit>
+      <trans-unit id="b"><source>B</source><target state="needs-review-translation">Bb</target></trans-unit>
+      <trans-unit id="c"><source>C</source><target>Cc <g id="1">bold</g></target></trans-unit>
+    </body>
+  </file>
+</xliff>`
+
+	doc, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	var fuzzy []bool
+	for _, unit := range doc.Units {
+		fuzzy = append(fuzzy, unit.Fuzzy)
+	}
+	if !reflect.DeepEqual(fuzzy, []bool{false, true, false}) {
+		t.Errorf("unexpected review states: %v", fuzzy)
+	}
+	if doc.Units[2].Target != "Cc bold" {
+		t.Errorf("expected inline markup text to be kept, got %q", doc.Units[2].Target)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	for _, input := range []string{`<html></html>`, `<xliff version="1.1"></xliff>`, `<xliff version="1.2"><file>`} {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package exchange
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// xmlTextEscaper escapes element content; newlines and tabs are kept as they are
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+// xmlAttrEscaper escapes attribute values, including whitespace that
+// attribute normalization would otherwise turn into spaces
+var xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;",
+	"\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+
+// EscapeXMLText escapes s for use as XML element content
+func EscapeXMLText(s string) string {
+	return xmlTextEscaper.Replace(s)
+}
+
+// EscapeXMLAttr escapes s for use as an XML attribute value
+func EscapeXMLAttr(s string) string {
+	return xmlAttrEscaper.Replace(s)
+}
+
+// XMLAttr returns the value of the attribute with the given local name
+func XMLAttr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
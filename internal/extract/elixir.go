@@ -0,0 +1,283 @@
+package extract
+
+import (
+	"strings"
+)
+
+// lexElixir lexes Elixir source
+func lexElixir(src string) []token {
+	l := newLexer(src)
+	l.elixir("")
+	return l.tokens
+}
+
+// lexHEEx lexes a HEEx (or EEx) template
+func lexHEEx(src string) []token {
+	l := newLexer(src)
+	l.heex("")
+	return l.tokens
+}
+
+// elixir lexes Elixir code up to stop ("}" ending an interpolation or HEEx
+// expression, "%>" ending an EEx tag), or to the end of input if stop is ""
+func (l *lexer) elixir(stop string) {
+	depth := 0
+	for l.pos < len(l.src) {
+		if stop != "" && depth == 0 && l.hasPrefix(stop) {
+			l.advance(len(stop))
+			return
+		}
+
+		c := l.src[l.pos]
+		line := l.line
+		switch {
+		case c == '\n' || c == ' ' || c == '\t' || c == '\r':
+			l.advance(1)
+
+		case c == '#':
+			l.advance(1)
+			text := l.src[l.pos:]
+			if i := strings.IndexByte(text, '\n'); i >= 0 {
+				text = text[:i]
+			}
+			l.advance(len(text))
+			l.emit(tokenComment, strings.TrimSpace(text), line)
+
+		case c == '"' || c == '\'':
+			quote := string(c)
+			triple := l.hasPrefix(strings.Repeat(quote, 3))
+			value, literal := l.elixirString(c, c, triple, true)
+			// Single-quoted strings are charlists, which gettext does not take
+			l.emitString(value, literal && c == '"', line)
+
+		case c == '~' && l.pos+1 < len(l.src) && isIdentStart(l.src[l.pos+1]):
+			l.sigil()
+
+		case c == '?' && l.pos+1 < len(l.src):
+			// Character literal such as ?" or ?\n
+			if l.src[l.pos+1] == '\\' {
+				l.advance(3)
+			} else {
+				l.advance(2)
+			}
+
+		case isIdentStart(c):
+			name := l.ident(func(byte) bool { return false })
+			if l.pos < len(l.src) && (l.src[l.pos] == '?' || l.src[l.pos] == '!') {
+				name += l.src[l.pos : l.pos+1]
+				l.pos++
+			}
+			l.emit(tokenIdent, name, line)
+
+		case c >= '0' && c <= '9':
+			l.ident(func(c byte) bool { return c == '.' })
+
+		case c == '<' && l.hasPrefix("<>"):
+			l.advance(2)
+			l.emit(tokenPunct, "<>", line)
+
+		default:
+			if c == '{' {
+				depth++
+			} else if c == '}' {
+				depth--
+			}
+			l.advance(1)
+			l.emit(tokenPunct, string(c), line)
+		}
+	}
+}
+
+// elixirString reads a string or sigil body starting at its opening delimiter
+// and returns its value and whether it is a plain literal. Heredocs (triple)
+// lose the indentation of their closing delimiter. Interpolations are lexed
+// as code.
+func (l *lexer) elixirString(open, close byte, triple, escapes bool) (string, bool) {
+	if triple {
+		return l.elixirHeredoc(open, escapes)
+	}
+
+	l.advance(1)
+	var sb strings.Builder
+	literal := true
+	depth := 0
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == close && depth == 0:
+			l.advance(1)
+			return sb.String(), literal
+		case c == close:
+			depth--
+		case c == open && open != close:
+			depth++
+		case c == '\\' && escapes:
+			text, n := unescape(l.src[l.pos:])
+			sb.WriteString(text)
+			l.advance(n)
+			continue
+		case c == '\\':
+			// Without escapes, a backslash still keeps the delimiter from closing
+			end := min(l.pos+2, len(l.src))
+			sb.WriteString(l.src[l.pos:end])
+			l.advance(end - l.pos)
+			continue
+		case c == '#' && escapes && l.hasPrefix("#{"):
+			l.advance(2)
+			l.elixir("}")
+			literal = false
+			continue
+		}
+		sb.WriteByte(c)
+		l.advance(1)
+	}
+	return sb.String(), false
+}
+
+// elixirHeredoc reads a heredoc (a triple-quoted string or charlist)
+// starting at its opening delimiter
+func (l *lexer) elixirHeredoc(quote byte, escapes bool) (string, bool) {
+	delimiter := strings.Repeat(string(quote), 3)
+	l.advance(3)
+	l.skipTo("\n")
+
+	var lines []string
+	var sb strings.Builder
+	literal := true
+	indent := ""
+	for l.pos < len(l.src) {
+		// A line starting with the delimiter closes the heredoc
+		if sb.Len() == 0 {
+			rest := l.src[l.pos:]
+			trimmed := strings.TrimLeft(rest, " \t")
+			if strings.HasPrefix(trimmed, delimiter) {
+				indent = rest[:len(rest)-len(trimmed)]
+				l.advance(len(rest) - len(trimmed) + 3)
+				break
+			}
+		}
+
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			lines = append(lines, sb.String())
+			sb.Reset()
+			l.advance(1)
+		case c == '\\' && escapes:
+			// Escapes are decoded after removing the indentation
+			end := min(l.pos+2, len(l.src))
+			sb.WriteString(l.src[l.pos:end])
+			l.advance(end - l.pos)
+		case c == '#' && escapes && l.hasPrefix("#{"):
+			l.advance(2)
+			l.elixir("}")
+			sb.WriteString("#{}")
+			literal = false
+		default:
+			sb.WriteByte(c)
+			l.advance(1)
+		}
+	}
+
+	var value strings.Builder
+	for _, line := range lines {
+		line = strings.TrimPrefix(line, indent)
+		if escapes {
+			for i := 0; i < len(line); {
+				if line[i] == '\\' {
+					text, n := unescape(line[i:])
+					value.WriteString(text)
+					i += n
+					continue
+				}
+				value.WriteByte(line[i])
+				i++
+			}
+		} else {
+			value.WriteString(line)
+		}
+		value.WriteByte('\n')
+	}
+	return value.String(), literal
+}
+
+// sigilDelimiters maps the opening delimiters of sigils to their closing ones
+var sigilDelimiters = map[byte]byte{
+	'"': '"', '\'': '\'', '/': '/', '|': '|',
+	'(': ')', '[': ']', '{': '}', '<': '>',
+}
+
+// sigil reads a sigil such as ~s(...) or ~H"""...""". HEEx templates (~H)
+// are lexed as templates; other sigils are skipped.
+func (l *lexer) sigil() {
+	line := l.line
+	l.advance(1)
+	name := l.ident(func(byte) bool { return false })
+	if l.pos >= len(l.src) {
+		return
+	}
+
+	open := l.src[l.pos]
+	close, ok := sigilDelimiters[open]
+	if !ok {
+		return
+	}
+	triple := (open == '"' || open == '\'') && l.hasPrefix(strings.Repeat(string(open), 3))
+	lowercase := name[0] >= 'a' && name[0] <= 'z'
+
+	if name == "H" {
+		l.advance(1)
+		stop := string(close)
+		if triple {
+			l.advance(2)
+			stop = strings.Repeat(stop, 3)
+		}
+		l.heex(stop)
+	} else {
+		value, literal := l.elixirString(open, close, triple, lowercase)
+		// ~s is a string, unless it has modifiers
+		if name == "s" && (l.pos >= len(l.src) || !isIdentStart(l.src[l.pos])) {
+			l.emitString(value, literal, line)
+		}
+	}
+
+	// Modifiers
+	l.ident(func(byte) bool { return false })
+}
+
+// heex lexes a HEEx or EEx template up to stop (the end of a ~H sigil), or to
+// the end of input if stop is "". Text is skipped; expressions in braces and
+// EEx tags are lexed as Elixir code, and <%!-- --%> comments are kept.
+func (l *lexer) heex(stop string) {
+	for l.pos < len(l.src) {
+		if stop != "" && l.hasPrefix(stop) {
+			l.advance(len(stop))
+			return
+		}
+
+		line := l.line
+		switch {
+		case l.hasPrefix("<%!--"):
+			l.advance(5)
+			text := l.skipTo("--%>")
+			l.emit(tokenComment, strings.TrimSpace(text), line)
+		case l.hasPrefix("<%#"):
+			l.advance(3)
+			l.skipTo("%>")
+		case l.hasPrefix("<!--"):
+			l.advance(4)
+			l.skipTo("-->")
+		case l.hasPrefix("<%"):
+			l.advance(2)
+			if l.pos < len(l.src) && l.src[l.pos] == '=' {
+				l.advance(1)
+			}
+			l.elixir("%>")
+		case l.src[l.pos] == '{':
+			l.advance(1)
+			l.elixir("}")
+		default:
+			l.advance(1)
+		}
+	}
+}
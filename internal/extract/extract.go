@@ -0,0 +1,320 @@
+// Package extract finds translatable strings in source code, like xgettext,
+// and builds .pot templates from them
+package extract
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// DefaultDomain is the domain of calls that do not name one
+const DefaultDomain = "default"
+
+// DefaultCommentTag starts the source comments that are copied into the
+// template as extracted comments (#.)
+const DefaultCommentTag = "TRANSLATORS:"
+
+// Language is a source language extract can read
+type Language string
+
+const (
+	Elixir     Language = "elixir"
+	HEEx       Language = "heex"
+	JavaScript Language = "javascript"
+)
+
+// languageExtensions maps file extensions to source languages
+var languageExtensions = map[string]Language{
+	".ex":     Elixir,
+	".exs":    Elixir,
+	".heex":   HEEx,
+	".eex":    HEEx,
+	".leex":   HEEx,
+	".js":     JavaScript,
+	".jsx":    JavaScript,
+	".mjs":    JavaScript,
+	".cjs":    JavaScript,
+	".ts":     JavaScript,
+	".tsx":    JavaScript,
+	".vue":    JavaScript,
+	".svelte": JavaScript,
+}
+
+// LanguageOf returns the source language of a file by its extension, or ""
+// if extract cannot read it
+func LanguageOf(path string) Language {
+	return languageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Message is a translatable string found in source code
+type Message struct {
+	Domain      string
+	MsgCtxt     string
+	MsgID       string
+	MsgIDPlural string
+	Reference   string   // path:line of the call
+	Comments    []string // Tagged source comments before the call
+	Flags       []string // Format flags, e.g. elixir-format
+}
+
+// Extractor finds translatable strings in source files
+type Extractor struct {
+	keywords   map[Language]keywordSet
+	commentTag string
+}
+
+// New creates an Extractor. Keywords (in xgettext --keyword syntax, see
+// ParseKeyword) are recognized in every language on top of the built-in
+// ones; commentTag starts the comments to extract ("" for DefaultCommentTag).
+func New(keywords []string, commentTag string) (*Extractor, error) {
+	elixir, err := newKeywordSet(elixirKeywords, keywords)
+	if err != nil {
+		return nil, err
+	}
+	js, err := newKeywordSet(jsKeywords, keywords)
+	if err != nil {
+		return nil, err
+	}
+	if commentTag == "" {
+		commentTag = DefaultCommentTag
+	}
+	return &Extractor{
+		keywords:   map[Language]keywordSet{Elixir: elixir, HEEx: elixir, JavaScript: js},
+		commentTag: commentTag,
+	}, nil
+}
+
+// Extract returns the translatable strings in the source of the file at
+// path, in order. References use path as given. Calls whose arguments are
+// not string literals are skipped.
+func (x *Extractor) Extract(path string, src []byte) ([]*Message, error) {
+	language := LanguageOf(path)
+
+	var tokens []token
+	concat := "+"
+	var flags []string
+	switch language {
+	case Elixir:
+		tokens = lexElixir(string(src))
+		concat = "<>"
+		flags = []string{"elixir-format"}
+	case HEEx:
+		tokens = lexHEEx(string(src))
+		concat = "<>"
+		flags = []string{"elixir-format"}
+	case JavaScript:
+		tokens = lexJS(string(src))
+	default:
+		return nil, fmt.Errorf("unsupported source file %s", path)
+	}
+	keywords := x.keywords[language]
+	reference := filepath.ToSlash(path)
+
+	var messages []*Message
+	var comment *commentBlock
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind == tokenComment {
+			comment = x.addComment(comment, tok)
+			continue
+		}
+
+		kw, ok := keywords[tok.text]
+		if tok.kind != tokenIdent || !ok || i+1 >= len(tokens) || tokens[i+1].text != "(" {
+			continue
+		}
+		args, _ := callArgs(tokens, i+2)
+
+		// Gettext.gettext(MyApp.Gettext, "msgid") takes the backend first
+		if language != JavaScript && i >= 2 && tokens[i-1].text == "." && tokens[i-2].text == "Gettext" && len(args) > 0 {
+			args = args[1:]
+		}
+
+		msg, ok := message(kw, args, concat)
+		if !ok {
+			continue
+		}
+		msg.Reference = fmt.Sprintf("%s:%d", reference, tok.line)
+		msg.Flags = flags
+		if comment != nil && tok.line >= comment.start && tok.line <= comment.end+1 {
+			msg.Comments = comment.lines
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// commentBlock is a run of comment lines starting with the comment tag
+type commentBlock struct {
+	lines      []string
+	start, end int
+}
+
+// addComment adds a comment token to the current comment block: a tagged
+// comment starts a new block, and comments on the lines right after it
+// continue the block
+func (x *Extractor) addComment(block *commentBlock, tok token) *commentBlock {
+	lines := strings.Split(tok.text, "\n")
+	if strings.HasPrefix(tok.text, x.commentTag) {
+		return &commentBlock{lines: lines, start: tok.line, end: tok.endLine}
+	}
+	if block != nil && tok.line == block.end+1 {
+		block.lines = append(slices.Clone(block.lines), lines...)
+		block.end = tok.endLine
+	}
+	return block
+}
+
+// callArgs splits the arguments of a call, starting after its opening
+// parenthesis, and returns them with the index after the closing one.
+// Comments are left out.
+func callArgs(tokens []token, start int) ([][]token, int) {
+	var args [][]token
+	var current []token
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind == tokenComment {
+			continue
+		}
+		if tok.kind == tokenPunct {
+			switch tok.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				if depth == 0 {
+					if len(current) > 0 || len(args) > 0 {
+						args = append(args, current)
+					}
+					return args, i + 1
+				}
+				depth--
+			case ",":
+				if depth == 0 {
+					args = append(args, current)
+					current = nil
+					continue
+				}
+			}
+		}
+		current = append(current, tok)
+	}
+	return args, len(tokens)
+}
+
+// message builds the message of a keyword call from its arguments, if the
+// arguments it needs are string literals
+func message(kw Keyword, args [][]token, concat string) (*Message, bool) {
+	if len(args) < kw.args() {
+		return nil, false
+	}
+
+	msg := &Message{Domain: DefaultDomain}
+	for _, field := range []struct {
+		position int
+		value    *string
+	}{
+		{kw.MsgID, &msg.MsgID},
+		{kw.Plural, &msg.MsgIDPlural},
+		{kw.Context, &msg.MsgCtxt},
+		{kw.Domain, &msg.Domain},
+	} {
+		if field.position == 0 {
+			continue
+		}
+		value, ok := stringArg(args[field.position-1], concat)
+		if !ok {
+			return nil, false
+		}
+		*field.value = value
+	}
+	if msg.MsgID == "" || msg.Domain == "" {
+		return nil, false
+	}
+	return msg, true
+}
+
+// stringArg returns the value of an argument made of string literals joined
+// by the concatenation operator
+func stringArg(arg []token, concat string) (string, bool) {
+	if len(arg) == 0 || len(arg)%2 == 0 {
+		return "", false
+	}
+	var sb strings.Builder
+	for i, tok := range arg {
+		if i%2 == 1 {
+			if tok.kind != tokenPunct || tok.text != concat {
+				return "", false
+			}
+			continue
+		}
+		if tok.kind != tokenString || !tok.literal {
+			return "", false
+		}
+		sb.WriteString(tok.text)
+	}
+	return sb.String(), true
+}
+
+// Template collects messages into a .pot catalog per domain. Messages with
+// the same msgctxt and msgid are merged: their references and comments are
+// combined.
+type Template struct {
+	catalogs map[string]*po.Catalog
+}
+
+// NewTemplate creates an empty template collection
+func NewTemplate() *Template {
+	return &Template{catalogs: make(map[string]*po.Catalog)}
+}
+
+// Add adds messages to the templates of their domains
+func (t *Template) Add(messages ...*Message) {
+	for _, msg := range messages {
+		catalog, ok := t.catalogs[msg.Domain]
+		if !ok {
+			catalog = po.NewCatalog()
+			t.catalogs[msg.Domain] = catalog
+		}
+
+		entry := catalog.Get(msg.MsgCtxt, msg.MsgID)
+		if entry == nil {
+			entry = &po.Entry{MsgCtxt: msg.MsgCtxt, MsgID: msg.MsgID, MsgIDPlural: msg.MsgIDPlural}
+			catalog.Add(entry)
+		}
+		if entry.MsgIDPlural == "" {
+			entry.MsgIDPlural = msg.MsgIDPlural
+		}
+		if !slices.Contains(entry.References, msg.Reference) {
+			entry.References = append(entry.References, msg.Reference)
+		}
+		for _, comment := range msg.Comments {
+			if !slices.Contains(entry.ExtractedComments, comment) {
+				entry.ExtractedComments = append(entry.ExtractedComments, comment)
+			}
+		}
+		for _, flag := range msg.Flags {
+			entry.AddFlag(flag)
+		}
+	}
+}
+
+// Domains returns the domains with messages, sorted
+func (t *Template) Domains() []string {
+	domains := make([]string, 0, len(t.catalogs))
+	for domain := range t.catalogs {
+		domains = append(domains, domain)
+	}
+	slices.Sort(domains)
+	return domains
+}
+
+// Catalog returns the template of a domain, or nil if it has no messages.
+// Its header is left empty for the caller to fill in.
+func (t *Template) Catalog(domain string) *po.Catalog {
+	return t.catalogs[domain]
+}
@@ -0,0 +1,252 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func extract(t *testing.T, path, src string, keywords ...string) []*Message {
+	t.Helper()
+	x, err := New(keywords, "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	messages, err := x.Extract(path, []byte(src))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	return messages
+}
+
+// summary describes messages as domain|ctxt|msgid[|plural]@line
+func summary(messages []*Message) []string {
+	var got []string
+	for _, m := range messages {
+		s := m.Domain + "|" + m.MsgCtxt + "|" + m.MsgID
+		if m.MsgIDPlural != "" {
+			s += "|" + m.MsgIDPlural
+		}
+		got = append(got, s+"@"+m.Reference[strings.LastIndexByte(m.Reference, ':')+1:])
+	}
+	return got
+}
+
+func TestExtract_Elixir(t *testing.T) {
+	src := `defmodule MyAppWeb.PageLive do
+  use MyAppWeb, :live_view
+
+  # TRANSLATORS: Title of the front page
+  # (keep it short)
+  def title, do: gettext("Welcome")
+
+  # A plain comment
+  def errors, do: dgettext("errors", "can't be blank")
+
+  def files(n), do: ngettext("%{count} file", "%{count} files", n)
+
+  def open, do: pgettext("button", "Open")
+
+  def long do
+    gettext("Hello " <>
+      "world\n")
+  end
+
+  def dynamic(name), do: gettext("Hi #{name}")
+  def charlist, do: gettext('Nope')
+  def escaped, do: gettext("Say \"hi\" #{"now"}")
+  def char, do: ?"
+  def backend, do: Gettext.gettext(MyApp.Gettext, "Backend")
+
+  def doc do
+    """
+    gettext("Not a call")
+    """
+  end
+
+  def heredoc, do: gettext("""
+    Line one
+      Line two
+    """)
+
+  def render(assigns) do
+    ~H"""
+    <%!-- TRANSLATORS: Link to the sign in page --%>
+    <.link href={~p"/login"}>{gettext("Sign in")}</.link>
+    <p title={pgettext("tooltip", "Help")}>Don't <%= gettext("Panic") %></p>
+    """
+  end
+
+  def custom, do: translate("Custom")
+end
+`
+	messages := extract(t, "lib/page_live.ex", src, "translate")
+
+	want := []string{
+		"default||Welcome@6",
+		"errors||can't be blank@9",
+		"default||%{count} file|%{count} files@11",
+		"default|button|Open@13",
+		"default||Hello world\n@16",
+		"default||Backend@24",
+		"default||Line one\n  Line two\n@32",
+		"default||Sign in@40",
+		"default|tooltip|Help@41",
+		"default||Panic@41",
+		"default||Custom@45",
+	}
+	if got := summary(messages); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected messages:\n got %q\nwant %q", got, want)
+	}
+
+	if got := messages[0].Comments; !reflect.DeepEqual(got, []string{"TRANSLATORS: Title of the front page", "(keep it short)"}) {
+		t.Errorf("unexpected comments for Welcome: %q", got)
+	}
+	if messages[1].Comments != nil {
+		t.Errorf("untagged comment extracted: %q", messages[1].Comments)
+	}
+	if got := messages[7].Comments; !reflect.DeepEqual(got, []string{"TRANSLATORS: Link to the sign in page"}) {
+		t.Errorf("unexpected comments for Sign in: %q", got)
+	}
+	if messages[0].Reference != "lib/page_live.ex:6" || !reflect.DeepEqual(messages[0].Flags, []string{"elixir-format"}) {
+		t.Errorf("unexpected reference or flags: %s %q", messages[0].Reference, messages[0].Flags)
+	}
+}
+
+func TestExtract_HEEx(t *testing.T) {
+	src := `<h1>{gettext("Title")}</h1>
+<%# gettext("Commented out") %>
+<!-- gettext("Also commented out") -->
+<p>gettext("Plain text")</p>
+<%= ngettext("One", "Many", @count) %>
+`
+	want := []string{"default||Title@1", "default||One|Many@5"}
+	if got := summary(extract(t, "page.html.heex", src)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExtract_JavaScript(t *testing.T) {
+	src := `import { t } from "./i18n";
+
+// TRANSLATORS: Shown after saving
+const saved = t("Saved");
+const pattern = /["']/g;
+const label = i18n.t('Label with \'quotes\'');
+const joined = _("Hello, " + "world");
+const templ = t(` + "`Template`" + `);
+const dynamic = t(` + "`Hi ${name}`" + `);
+const variable = t(key);
+
+/*
+ * TRANSLATORS: Button text
+ */
+export const Button = () => <button>{t("Don't go")}</button>;
+const Other = () => <p>Don't {t("Stay")}</p>;
+`
+	messages := extract(t, "assets/js/app.tsx", src)
+	want := []string{
+		"default||Saved@4",
+		"default||Label with 'quotes'@6",
+		"default||Hello, world@7",
+		"default||Template@8",
+		"default||Don't go@15",
+	}
+	if got := summary(messages); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected messages:\n got %q\nwant %q", got, want)
+	}
+	if got := messages[0].Comments; !reflect.DeepEqual(got, []string{"TRANSLATORS: Shown after saving"}) {
+		t.Errorf("unexpected comments: %q", got)
+	}
+	if got := messages[4].Comments; !reflect.DeepEqual(got, []string{"TRANSLATORS: Button text"}) {
+		t.Errorf("unexpected block comments: %q", got)
+	}
+	if messages[0].Flags != nil {
+		t.Errorf("unexpected flags for JavaScript: %q", messages[0].Flags)
+	}
+}
+
+func TestParseKeyword(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Keyword
+		wantErr bool
+	}{
+		{"tr", Keyword{Name: "tr", MsgID: 1}, false},
+		{"tr:2", Keyword{Name: "tr", MsgID: 2}, false},
+		{"ntr:1,2", Keyword{Name: "ntr", MsgID: 1, Plural: 2}, false},
+		{"ptr:1c,2", Keyword{Name: "ptr", MsgID: 2, Context: 1}, false},
+		{"dtr:1d,2", Keyword{Name: "dtr", MsgID: 2, Domain: 1}, false},
+		{":1", Keyword{}, true},
+		{"tr:x", Keyword{}, true},
+		{"tr:1c", Keyword{}, true},
+		{"tr:1,2,3", Keyword{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseKeyword(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseKeyword(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseKeyword(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	template := NewTemplate()
+	template.Add(extract(t, "lib/a.ex", `gettext("Hello")
+dgettext("errors", "Oops")
+`)...)
+	template.Add(extract(t, "lib/b.ex", `# TRANSLATORS: Greeting
+gettext("Hello")
+`)...)
+
+	if got := template.Domains(); !reflect.DeepEqual(got, []string{"default", "errors"}) {
+		t.Fatalf("unexpected domains %q", got)
+	}
+	entry := template.Catalog("default").Get("", "Hello")
+	if !reflect.DeepEqual(entry.References, []string{"lib/a.ex:1", "lib/b.ex:2"}) {
+		t.Errorf("unexpected references %q", entry.References)
+	}
+	if !reflect.DeepEqual(entry.ExtractedComments, []string{"TRANSLATORS: Greeting"}) {
+		t.Errorf("unexpected comments %q", entry.ExtractedComments)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"lib/a.ex", "lib/web/b.heex", "lib/web/c.txt",
+		"assets/js/app.js", "assets/node_modules/x/index.js", "deps/d.ex",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	got, err := Glob([]string{"lib/**/*.{ex,heex}", "assets/**/*.js", "lib/a.ex", "missing/*.ex"})
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	want := []string{
+		filepath.FromSlash("assets/js/app.js"),
+		filepath.FromSlash("lib/a.ex"),
+		filepath.FromSlash("lib/web/b.heex"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Glob = %q, want %q", got, want)
+	}
+}
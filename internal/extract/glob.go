@@ -0,0 +1,125 @@
+package extract
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Glob returns the files matching any of the patterns, sorted and without
+// duplicates. Patterns use forward slashes and support *, ?, [...], {a,b}
+// alternatives and ** for any number of directories, as in
+// "lib/**/*.{ex,heex}". Hidden directories, deps, node_modules and _build
+// are not searched by **.
+func Glob(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		re, err := globRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		root := globRoot(pattern)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			slashed := filepath.ToSlash(path)
+			if d.IsDir() {
+				if path != root && skipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if re.MatchString(slashed) && !slices.Contains(files, path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// skipDir reports whether a directory is left out of ** searches
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "deps" || name == "node_modules" || name == "_build"
+}
+
+// globRoot returns the directory a pattern is searched from: its leading
+// path elements without wildcards
+func globRoot(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	var root []string
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, "*?[{") {
+			break
+		}
+		root = append(root, part)
+	}
+	if len(root) == 0 {
+		if strings.HasPrefix(pattern, "/") {
+			return "/"
+		}
+		return "."
+	}
+	return filepath.FromSlash(strings.Join(root, "/"))
+}
+
+// globRegexp translates a glob pattern to a regular expression matching
+// slash-separated paths
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	var sb strings.Builder
+	sb.WriteString(`^(\./)?`)
+	braces := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString(`(.*/)?`)
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(`.*`)
+			i++
+		case c == '*':
+			sb.WriteString(`[^/]*`)
+		case c == '?':
+			sb.WriteString(`[^/]`)
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		case c == '{':
+			braces++
+			sb.WriteString(`(?:`)
+		case c == '}' && braces > 0:
+			braces--
+			sb.WriteString(`)`)
+		case c == ',' && braces > 0:
+			sb.WriteString(`|`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString(`$`)
+	return regexp.Compile(sb.String())
+}
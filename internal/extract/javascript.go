@@ -0,0 +1,190 @@
+package extract
+
+import (
+	"strings"
+)
+
+// lexJS lexes JavaScript or TypeScript source, including JSX
+func lexJS(src string) []token {
+	l := newLexer(src)
+	l.js("")
+	return l.tokens
+}
+
+// regexPrecedingWords are the keywords after which a slash starts a regular
+// expression rather than a division
+var regexPrecedingWords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true,
+	"in": true, "of": true, "new": true, "delete": true, "void": true,
+	"throw": true, "yield": true, "await": true,
+}
+
+// js lexes JavaScript code up to stop ("}" ending a template literal
+// substitution), or to the end of input if stop is ""
+func (l *lexer) js(stop string) {
+	depth := 0
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if stop != "" && depth == 0 && l.hasPrefix(stop) {
+			l.advance(len(stop))
+			return
+		}
+
+		line := l.line
+		switch {
+		case c == '\n' || c == ' ' || c == '\t' || c == '\r':
+			l.advance(1)
+
+		case l.hasPrefix("//"):
+			l.advance(2)
+			text := l.src[l.pos:]
+			if i := strings.IndexByte(text, '\n'); i >= 0 {
+				text = text[:i]
+			}
+			l.advance(len(text))
+			l.emit(tokenComment, strings.TrimSpace(text), line)
+
+		case l.hasPrefix("/*"):
+			l.advance(2)
+			l.emit(tokenComment, blockComment(l.skipTo("*/")), line)
+
+		case c == '"' || c == '\'':
+			value, literal := l.jsString(c)
+			l.emitString(value, literal, line)
+
+		case c == '`':
+			value, literal := l.jsTemplate()
+			l.emitString(value, literal, line)
+
+		case c == '/' && l.regexAllowed():
+			l.jsRegex()
+
+		case isIdentStart(c) || c == '$':
+			l.emit(tokenIdent, l.ident(func(c byte) bool { return c == '$' }), line)
+
+		case c >= '0' && c <= '9':
+			l.ident(func(c byte) bool { return c == '.' })
+
+		default:
+			if c == '{' {
+				depth++
+			} else if c == '}' {
+				depth--
+			}
+			l.advance(1)
+			l.emit(tokenPunct, string(c), line)
+		}
+	}
+}
+
+// blockComment returns the text of a /* */ comment, without the leading
+// asterisks of its lines
+func blockComment(text string) string {
+	lines := strings.Split(text, "\n")
+	var kept []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "*"))
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// jsString reads a quoted string. Strings cannot span lines, so quotes in
+// JSX text (as in <p>Don't</p>) only affect the rest of their line.
+func (l *lexer) jsString(quote byte) (string, bool) {
+	l.advance(1)
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case quote:
+			l.advance(1)
+			return sb.String(), true
+		case '\n':
+			return sb.String(), false
+		case '\\':
+			text, n := unescape(l.src[l.pos:])
+			sb.WriteString(text)
+			l.advance(n)
+			continue
+		}
+		sb.WriteByte(c)
+		l.advance(1)
+	}
+	return sb.String(), false
+}
+
+// jsTemplate reads a template literal; substitutions are lexed as code
+func (l *lexer) jsTemplate() (string, bool) {
+	l.advance(1)
+	var sb strings.Builder
+	literal := true
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '`':
+			l.advance(1)
+			return sb.String(), literal
+		case c == '\\':
+			text, n := unescape(l.src[l.pos:])
+			sb.WriteString(text)
+			l.advance(n)
+			continue
+		case l.hasPrefix("${"):
+			l.advance(2)
+			l.js("}")
+			literal = false
+			continue
+		}
+		sb.WriteByte(c)
+		l.advance(1)
+	}
+	return sb.String(), false
+}
+
+// regexAllowed reports whether a slash at the current position starts a
+// regular expression, judging by the token before it
+func (l *lexer) regexAllowed() bool {
+	for i := len(l.tokens) - 1; i >= 0; i-- {
+		previous := l.tokens[i]
+		switch previous.kind {
+		case tokenComment:
+			continue
+		case tokenIdent:
+			return regexPrecedingWords[previous.text]
+		case tokenString:
+			return false
+		case tokenPunct:
+			return !strings.Contains(")]}", previous.text)
+		}
+	}
+	return true
+}
+
+// jsRegex skips a regular expression literal and its flags
+func (l *lexer) jsRegex() {
+	l.advance(1)
+	inClass := false
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			return
+		case c == '\\':
+			l.advance(2)
+			continue
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			l.advance(1)
+			l.ident(func(byte) bool { return false })
+			return
+		}
+		l.advance(1)
+	}
+}
@@ -0,0 +1,112 @@
+package extract
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Keyword describes a function whose calls mark translatable strings, with
+// the 1-based positions of its arguments (0 for arguments it does not take)
+type Keyword struct {
+	Name    string
+	MsgID   int
+	Plural  int
+	Context int
+	Domain  int
+}
+
+// ParseKeyword parses a keyword spec in xgettext --keyword syntax: the
+// function name, optionally followed by a colon and argument positions, the
+// msgid first and then the msgid_plural, with "c" marking the msgctxt and
+// "d" the domain. "t" is t:1; "npgettext:1c,2,3" takes the context, msgid
+// and plural from its first three arguments.
+func ParseKeyword(spec string) (Keyword, error) {
+	name, args, hasArgs := strings.Cut(strings.TrimSpace(spec), ":")
+	if name == "" {
+		return Keyword{}, fmt.Errorf("invalid keyword %q: missing function name", spec)
+	}
+
+	kw := Keyword{Name: name, MsgID: 1}
+	if !hasArgs {
+		return kw, nil
+	}
+
+	kw.MsgID = 0
+	for _, arg := range strings.Split(args, ",") {
+		arg = strings.TrimSpace(arg)
+		suffix := ""
+		if strings.HasSuffix(arg, "c") || strings.HasSuffix(arg, "d") {
+			arg, suffix = arg[:len(arg)-1], arg[len(arg)-1:]
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return Keyword{}, fmt.Errorf("invalid keyword %q: bad argument position %q", spec, arg+suffix)
+		}
+
+		switch {
+		case suffix == "c" && kw.Context == 0:
+			kw.Context = n
+		case suffix == "d" && kw.Domain == 0:
+			kw.Domain = n
+		case suffix == "" && kw.MsgID == 0:
+			kw.MsgID = n
+		case suffix == "" && kw.Plural == 0:
+			kw.Plural = n
+		default:
+			return Keyword{}, fmt.Errorf("invalid keyword %q: too many arguments", spec)
+		}
+	}
+	if kw.MsgID == 0 {
+		return Keyword{}, fmt.Errorf("invalid keyword %q: no msgid argument", spec)
+	}
+	return kw, nil
+}
+
+// args returns the number of arguments a call needs for the keyword
+func (kw Keyword) args() int {
+	return max(kw.MsgID, kw.Plural, kw.Context, kw.Domain)
+}
+
+// elixirKeywords are the Gettext macros of Elixir and HEEx templates
+var elixirKeywords = []string{
+	"gettext:1",
+	"dgettext:1d,2",
+	"ngettext:1,2",
+	"dngettext:1d,2,3",
+	"pgettext:1c,2",
+	"dpgettext:1d,2c,3",
+	"pngettext:1c,2,3",
+	"dpngettext:1d,2c,3,4",
+	"gettext_noop:1",
+	"dgettext_noop:1d,2",
+	"ngettext_noop:1,2",
+	"dngettext_noop:1d,2,3",
+	"pgettext_noop:1c,2",
+	"dpgettext_noop:1d,2c,3",
+}
+
+// jsKeywords are the translation functions of JavaScript and TypeScript
+var jsKeywords = []string{
+	"_:1",
+	"t:1",
+}
+
+// keywordSet maps function names to keywords
+type keywordSet map[string]Keyword
+
+// newKeywordSet parses keyword specs into a set; later specs replace earlier
+// ones with the same name
+func newKeywordSet(specs ...[]string) (keywordSet, error) {
+	set := make(keywordSet)
+	for _, list := range specs {
+		for _, spec := range list {
+			kw, err := ParseKeyword(spec)
+			if err != nil {
+				return nil, err
+			}
+			set[kw.Name] = kw
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,144 @@
+package extract
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a source token
+type tokenKind int
+
+const (
+	tokenIdent   tokenKind = iota // Identifier
+	tokenString                   // String literal
+	tokenPunct                    // Operator or bracket
+	tokenComment                  // Comment, without its markers
+)
+
+// token is a lexical token of a source file. Only what extraction needs is
+// kept: identifiers, strings, brackets and operators, and comments.
+type token struct {
+	kind    tokenKind
+	text    string // Identifier, decoded string value, operator or comment text
+	literal bool   // Strings: a plain literal, without interpolation
+	line    int
+	endLine int // Last line of the token (multi-line comments)
+}
+
+// lexer splits source code into tokens. Code embedded in strings and
+// templates (interpolation, HEEx expressions) is lexed in place, so calls in
+// it are found too.
+type lexer struct {
+	src    string
+	pos    int
+	line   int
+	tokens []token
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1}
+}
+
+func (l *lexer) emit(kind tokenKind, text string, line int) {
+	l.tokens = append(l.tokens, token{kind: kind, text: text, literal: true, line: line, endLine: l.line})
+}
+
+func (l *lexer) emitString(value string, literal bool, line int) {
+	l.tokens = append(l.tokens, token{kind: tokenString, text: value, literal: literal, line: line, endLine: l.line})
+}
+
+// advance moves past n bytes, counting lines
+func (l *lexer) advance(n int) {
+	end := min(l.pos+n, len(l.src))
+	l.line += strings.Count(l.src[l.pos:end], "\n")
+	l.pos = end
+}
+
+func (l *lexer) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(l.src[l.pos:], prefix)
+}
+
+// skipTo moves past the next occurrence of end, or to the end of input, and
+// returns the text before it
+func (l *lexer) skipTo(end string) string {
+	i := strings.Index(l.src[l.pos:], end)
+	if i < 0 {
+		text := l.src[l.pos:]
+		l.advance(len(text))
+		return text
+	}
+	text := l.src[l.pos : l.pos+i]
+	l.advance(i + len(end))
+	return text
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// ident reads an identifier, including the characters extra allows
+func (l *lexer) ident(extra func(byte) bool) string {
+	start := l.pos
+	for l.pos < len(l.src) && (isIdentChar(l.src[l.pos]) || extra(l.src[l.pos])) {
+		l.pos++
+	}
+	return l.src[start:l.pos]
+}
+
+// unescape decodes a backslash escape at the start of s and returns the
+// decoded text and the number of bytes read. Unknown escapes stand for the
+// escaped character, as in both Elixir and JavaScript.
+func unescape(s string) (string, int) {
+	if len(s) < 2 {
+		return s, len(s)
+	}
+	switch c := s[1]; c {
+	case 'n':
+		return "\n", 2
+	case 't':
+		return "\t", 2
+	case 'r':
+		return "\r", 2
+	case 'a':
+		return "\a", 2
+	case 'b':
+		return "\b", 2
+	case 'f':
+		return "\f", 2
+	case 'v':
+		return "\v", 2
+	case 'e':
+		return "\x1b", 2
+	case '0':
+		return "\x00", 2
+	case 's':
+		return " ", 2
+	case '\n':
+		return "", 2 // Line continuation
+	case 'x':
+		if len(s) >= 4 {
+			if n, err := strconv.ParseUint(s[2:4], 16, 8); err == nil {
+				return string(rune(n)), 4
+			}
+		}
+	case 'u':
+		if len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end > 3 {
+				if n, err := strconv.ParseUint(s[3:end], 16, 32); err == nil {
+					return string(rune(n)), end + 1
+				}
+			}
+		} else if len(s) >= 6 {
+			if n, err := strconv.ParseUint(s[2:6], 16, 16); err == nil {
+				return string(rune(n)), 6
+			}
+		}
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	return s[1 : 1+size], 1 + size
+}
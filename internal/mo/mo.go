@@ -0,0 +1,128 @@
+// Package mo reads and writes GNU gettext binary message catalogs (.mo files)
+package mo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/charset"
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// Magic is the first word of a .mo file, as read in the file's byte order
+const Magic = 0x950412de
+
+// headerSize is the size of the fixed .mo header: magic, revision, number of
+// strings, offsets of the two string tables, hash table size and offset
+const headerSize = 28
+
+// Message is a single original/translation pair as stored in a .mo file.
+// ID is "msgctxt\x04msgid" (or just msgid), followed by "\x00msgid_plural"
+// for plural messages; Str holds the msgstr[N] forms separated by "\x00".
+type Message struct {
+	ID  string
+	Str string
+}
+
+// Options controls which entries are compiled
+type Options struct {
+	UseFuzzy bool // Include fuzzy entries, like msgfmt --use-fuzzy
+}
+
+// Messages converts catalog entries to .mo messages sorted by ID, like msgfmt:
+// the header entry comes first (ID ""), and obsolete, untranslated and (unless
+// opts.UseFuzzy) fuzzy entries are left out. A fuzzy header is kept.
+// Strings are encoded from UTF-8 to the charset declared in the header, the
+// one gettext reads them in.
+func Messages(header *model.MsgEntry, entries []*model.MsgEntry, opts Options) ([]Message, error) {
+	var messages []Message
+	if header != nil && header.MsgStr != "" {
+		messages = append(messages, Message{ID: "", Str: header.MsgStr})
+	}
+
+	for _, entry := range entries {
+		if entry.Obsolete || entry.IsEmpty() || (entry.IsFuzzy() && !opts.UseFuzzy) {
+			continue
+		}
+		messages = append(messages, entryMessage(entry))
+	}
+
+	if header != nil {
+		enc, err := charset.Lookup(model.ParseHeader(header.MsgStr).Charset())
+		if err != nil {
+			return nil, err
+		}
+		if enc != nil {
+			encoder := enc.NewEncoder()
+			for i, m := range messages {
+				if messages[i].ID, err = encoder.String(m.ID); err != nil {
+					return nil, fmt.Errorf("failed to encode %q: %w", m.ID, err)
+				}
+				if messages[i].Str, err = encoder.String(m.Str); err != nil {
+					return nil, fmt.Errorf("failed to encode translation of %q: %w", m.ID, err)
+				}
+			}
+		}
+	}
+
+	// Lookups binary search by original string, compared as bytes (after
+	// encoding, which can change their order)
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// entryMessage converts a single entry
+func entryMessage(entry *model.MsgEntry) Message {
+	id := entry.Key()
+	if entry.IsPlural() {
+		return Message{
+			ID:  id + "\x00" + entry.MsgIDPlural,
+			Str: strings.Join(entry.MsgStrPlural, "\x00"),
+		}
+	}
+	return Message{ID: id, Str: entry.MsgStr}
+}
+
+// hashString is the hashpjw function gettext uses for the .mo hash table.
+// It hashes the original string up to its first NUL, i.e. without the plural.
+func hashString(s string) uint32 {
+	var hval uint32
+	for i := 0; i < len(s) && s[i] != 0; i++ {
+		hval <<= 4
+		hval += uint32(s[i])
+		if g := hval & 0xf0000000; g != 0 {
+			hval ^= g >> 24
+			hval ^= g
+		}
+	}
+	return hval
+}
+
+// hashTableSize returns the hash table size msgfmt uses for n strings:
+// the smallest prime of at least 4n/3, and at least 3
+func hashTableSize(n int) uint32 {
+	size := uint32(n * 4 / 3)
+	if size < 3 {
+		size = 3
+	}
+	for !isPrime(size) {
+		size++
+	}
+	return size
+}
+
+// isPrime reports whether n is prime (n >= 3, odd numbers tested by trial division)
+func isPrime(n uint32) bool {
+	if n%2 == 0 {
+		return n == 2
+	}
+	for d := uint32(3); d*d <= n; d += 2 {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package mo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/charset"
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// IsMO reports whether data starts with the .mo magic number, in either byte order
+func IsMO(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	return binary.LittleEndian.Uint32(data) == Magic || binary.BigEndian.Uint32(data) == Magic
+}
+
+// Decode parses a .mo file of either byte order. It returns the header entry
+// (msgid ""), or nil if there is none, and the other messages in file order.
+// Strings are decoded to UTF-8 from the charset declared in the header.
+func Decode(data []byte) (*model.MsgEntry, []*model.MsgEntry, error) {
+	if !IsMO(data) || len(data) < headerSize {
+		return nil, nil, fmt.Errorf("not a .mo file")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(data) != Magic {
+		order = binary.BigEndian
+	}
+	if revision := order.Uint32(data[4:]); revision>>16 > 1 {
+		return nil, nil, fmt.Errorf("unsupported .mo revision %d.%d", revision>>16, revision&0xffff)
+	}
+
+	n := order.Uint32(data[8:])
+	origTable, transTable := order.Uint32(data[12:]), order.Uint32(data[16:])
+
+	// str returns the i-th string of a string table
+	str := func(table, i uint32) (string, error) {
+		entry := uint64(table) + 8*uint64(i)
+		if entry+8 > uint64(len(data)) {
+			return "", fmt.Errorf("string table entry %d out of range", i)
+		}
+		length, offset := uint64(order.Uint32(data[entry:])), uint64(order.Uint32(data[entry+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", fmt.Errorf("string %d out of range", i)
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	var messages []Message
+	for i := uint32(0); i < n; i++ {
+		id, err := str(origTable, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		translation, err := str(transTable, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		messages = append(messages, Message{ID: id, Str: translation})
+	}
+
+	// The header declares the charset of every string
+	var decode func(string) (string, error)
+	for _, m := range messages {
+		if m.ID != "" {
+			continue
+		}
+		enc, err := charset.Lookup(model.ParseHeader(m.Str).Charset())
+		if err != nil {
+			return nil, nil, err
+		}
+		if enc != nil {
+			decoder := enc.NewDecoder()
+			decode = decoder.String
+		}
+	}
+
+	var header *model.MsgEntry
+	var entries []*model.MsgEntry
+	for _, m := range messages {
+		if decode != nil {
+			var err error
+			if m.ID, err = decode(m.ID); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode message: %w", err)
+			}
+			if m.Str, err = decode(m.Str); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode message: %w", err)
+			}
+		}
+
+		entry := messageEntry(m)
+		if m.ID == "" {
+			header = entry
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return header, entries, nil
+}
+
+// messageEntry converts a .mo message back to an entry
+func messageEntry(m Message) *model.MsgEntry {
+	entry := &model.MsgEntry{}
+	id := m.ID
+	if ctxt, rest, ok := strings.Cut(id, model.ContextSeparator); ok {
+		entry.MsgCtxt, id = ctxt, rest
+	}
+	if msgid, plural, ok := strings.Cut(id, "\x00"); ok {
+		entry.MsgID, entry.MsgIDPlural = msgid, plural
+		entry.MsgStrPlural = strings.Split(m.Str, "\x00")
+		return entry
+	}
+	entry.MsgID, entry.MsgStr = id, m.Str
+	return entry
+}
@@ -0,0 +1,102 @@
+package mo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+func TestDecode_RoundTrip(t *testing.T) {
+	header := &model.MsgEntry{MsgStr: "Language: sv\nContent-Type: text/plain; charset=UTF-8\n"}
+	entries := []*model.MsgEntry{
+		{MsgID: "Welcome", MsgStr: "Välkommen"},
+		{MsgCtxt: "button", MsgID: "Open", MsgStr: "Öppna"},
+		{MsgID: "%d file", MsgIDPlural: "%d files", MsgStrPlural: []string{"%d fil", "%d filer"}},
+	}
+
+	messages, err := Messages(header, entries, Options{})
+	if err != nil {
+		t.Fatalf("Messages failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, messages); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	gotHeader, got, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if gotHeader == nil || gotHeader.MsgStr != header.MsgStr {
+		t.Errorf("unexpected header: %+v", gotHeader)
+	}
+
+	// Messages come back sorted by msgid
+	expected := []*model.MsgEntry{entries[2], entries[0], entries[1]}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected entries:\n%+v\nexpected:\n%+v", got, expected)
+	}
+}
+
+// bigEndianMO builds a .mo file in big-endian byte order without a hash table
+func bigEndianMO(messages []Message) []byte {
+	var buf bytes.Buffer
+	put := func(v uint32) { binary.Write(&buf, binary.BigEndian, v) }
+
+	n := uint32(len(messages))
+	offset := uint32(headerSize) + 16*n
+	for _, v := range []uint32{Magic, 0, n, headerSize, headerSize + 8*n, 0, 0} {
+		put(v)
+	}
+	for _, m := range messages {
+		put(uint32(len(m.ID)))
+		put(offset)
+		offset += uint32(len(m.ID)) + 1
+	}
+	for _, m := range messages {
+		put(uint32(len(m.Str)))
+		put(offset)
+		offset += uint32(len(m.Str)) + 1
+	}
+	for _, m := range messages {
+		buf.WriteString(m.ID + "\x00")
+	}
+	for _, m := range messages {
+		buf.WriteString(m.Str + "\x00")
+	}
+	return buf.Bytes()
+}
+
+func TestDecode_BigEndianLatin1(t *testing.T) {
+	data := bigEndianMO([]Message{
+		{ID: "", Str: "Content-Type: text/plain; charset=ISO-8859-1\n"},
+		{ID: "Welcome", Str: "V\xe4lkommen"},
+	})
+	if !IsMO(data) {
+		t.Fatal("expected big-endian magic to be recognized")
+	}
+
+	_, entries, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].MsgStr != "Välkommen" {
+		t.Errorf("expected msgstr decoded from ISO-8859-1, got %+v", entries)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"not a .mo file":  []byte("msgid \"\"\nmsgstr \"\"\n"),
+		"truncated":       {0xde, 0x12, 0x04, 0x95, 0, 0, 0, 0},
+		"table too short": bigEndianMO([]Message{{ID: "a", Str: "b"}})[:40],
+	}
+	for name, data := range tests {
+		if _, _, err := Decode(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package mo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/xnilsson/poflow/internal/util"
+)
+
+// Write encodes messages as a little-endian .mo file (revision 0) with a hash
+// table. Messages must be sorted by ID, as returned by Messages.
+func Write(w io.Writer, messages []Message) error {
+	n := uint32(len(messages))
+	hashSize := hashTableSize(len(messages))
+
+	// Layout: header, original table, translation table, hash table, strings
+	origTable := uint32(headerSize)
+	transTable := origTable + 8*n
+	hashTable := transTable + 8*n
+	offset := hashTable + 4*hashSize
+
+	var buf bytes.Buffer
+	put := func(values ...uint32) {
+		for _, v := range values {
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+	}
+
+	put(Magic, 0, n, origTable, transTable, hashSize, hashTable)
+
+	// String tables: length and offset of each NUL-terminated string
+	for _, m := range messages {
+		put(uint32(len(m.ID)), offset)
+		offset += uint32(len(m.ID)) + 1
+	}
+	for _, m := range messages {
+		put(uint32(len(m.Str)), offset)
+		offset += uint32(len(m.Str)) + 1
+	}
+
+	// Hash table with open addressing; slots hold the string index + 1
+	table := make([]uint32, hashSize)
+	for i, m := range messages {
+		hash := hashString(m.ID)
+		idx := hash % hashSize
+		incr := 1 + hash%(hashSize-2)
+		for table[idx] != 0 {
+			idx = (idx + incr) % hashSize
+		}
+		table[idx] = uint32(i) + 1
+	}
+	put(table...)
+
+	for _, m := range messages {
+		buf.WriteString(m.ID)
+		buf.WriteByte(0)
+	}
+	for _, m := range messages {
+		buf.WriteString(m.Str)
+		buf.WriteByte(0)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// WriteFile atomically replaces the file at path with a .mo file of messages
+func WriteFile(path string, messages []Message) error {
+	return util.WriteFileAtomic(path, func(w io.Writer) error {
+		return Write(w, messages)
+	})
+}
@@ -0,0 +1,134 @@
+package mo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// hashLookup finds id through the hash table of a .mo file, as gettext does
+func hashLookup(t *testing.T, data []byte, id string) (string, bool) {
+	t.Helper()
+	word := func(offset uint32) uint32 { return binary.LittleEndian.Uint32(data[offset:]) }
+	str := func(table, i uint32) string {
+		length, offset := word(table+8*i), word(table+8*i+4)
+		return string(data[offset : offset+length])
+	}
+
+	origTable, transTable := word(12), word(16)
+	hashSize, hashTable := word(20), word(24)
+
+	hash := hashString(id)
+	idx := hash % hashSize
+	incr := 1 + hash%(hashSize-2)
+	for {
+		slot := word(hashTable + 4*idx)
+		if slot == 0 {
+			return "", false
+		}
+		orig := str(origTable, slot-1)
+		// Plural messages are found by their msgid alone
+		if orig == id || (len(orig) > len(id) && orig[:len(id)] == id && orig[len(id)] == 0) {
+			return str(transTable, slot-1), true
+		}
+		idx = (idx + incr) % hashSize
+	}
+}
+
+func TestWrite(t *testing.T) {
+	header := &model.MsgEntry{MsgStr: "Language: sv\nPlural-Forms: nplurals=2; plural=(n != 1);\n"}
+	entries := []*model.MsgEntry{
+		{MsgID: "Welcome", MsgStr: "Välkommen"},
+		{MsgCtxt: "button", MsgID: "Open", MsgStr: "Öppna"},
+		{MsgCtxt: "state", MsgID: "Open", MsgStr: "Öppen"},
+		{MsgID: "%d file", MsgIDPlural: "%d files", MsgStrPlural: []string{"%d fil", "%d filer"}},
+		{MsgID: "Draft", MsgStr: "Utkast", Flags: []string{"fuzzy"}},
+		{MsgID: "Untranslated"},
+		{MsgID: "Gone", MsgStr: "Borta", Obsolete: true},
+	}
+
+	messages, err := Messages(header, entries, Options{})
+	if err != nil {
+		t.Fatalf("Messages failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, messages); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	if magic := binary.LittleEndian.Uint32(data); magic != Magic {
+		t.Fatalf("expected little-endian magic, got %#x", magic)
+	}
+	if n := binary.LittleEndian.Uint32(data[8:]); n != 5 {
+		t.Errorf("expected 5 messages (header, 3 singular, 1 plural), got %d", n)
+	}
+
+	expected := map[string]string{
+		"":               header.MsgStr,
+		"Welcome":        "Välkommen",
+		"button\x04Open": "Öppna",
+		"state\x04Open":  "Öppen",
+		"%d file":        "%d fil\x00%d filer",
+		"Draft":          "",
+		"Untranslated":   "",
+		"Gone":           "",
+	}
+	for id, want := range expected {
+		got, ok := hashLookup(t, data, id)
+		if want == "" && id != "" {
+			if ok {
+				t.Errorf("expected %q to be left out, found %q", id, got)
+			}
+			continue
+		}
+		if !ok || got != want {
+			t.Errorf("lookup %q = %q, %v; expected %q", id, got, ok, want)
+		}
+	}
+}
+
+func TestMessages_UseFuzzy(t *testing.T) {
+	entries := []*model.MsgEntry{
+		{MsgID: "b", MsgStr: "B", Flags: []string{"fuzzy"}},
+		{MsgID: "a", MsgStr: "A"},
+	}
+
+	messages, err := Messages(nil, entries, Options{UseFuzzy: true})
+	if err != nil {
+		t.Fatalf("Messages failed: %v", err)
+	}
+	if len(messages) != 2 || messages[0].ID != "a" || messages[1].ID != "b" {
+		t.Errorf("expected fuzzy entry included and messages sorted, got %+v", messages)
+	}
+}
+
+func TestHashTableSize(t *testing.T) {
+	tests := []struct{ n, size int }{{0, 3}, {1, 3}, {3, 5}, {10, 13}, {100, 137}}
+	for _, tt := range tests {
+		if got := hashTableSize(tt.n); got != uint32(tt.size) {
+			t.Errorf("hashTableSize(%d) = %d, expected %d", tt.n, got, tt.size)
+		}
+	}
+}
+
+func TestHashString(t *testing.T) {
+	tests := []struct {
+		s    string
+		hash uint32
+	}{
+		{"", 0},
+		{"a", 97},
+		{"ab", 97<<4 + 98},
+		{"id\x00plural", hashString("id")},
+		// Long enough for the high nibble to be folded back in
+		{"abcdefghij", 0x0abaa66a},
+	}
+	for _, tt := range tests {
+		if got := hashString(tt.s); got != tt.hash {
+			t.Errorf("hashString(%q) = %#x, expected %#x", tt.s, got, tt.hash)
+		}
+	}
+}
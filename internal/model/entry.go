@@ -1,15 +1,116 @@
 package model
 
+// ContextSeparator joins msgctxt and msgid in lookup keys (same convention as gettext .mo files)
+const ContextSeparator = "\x04"
+
+// FlagFuzzy marks a translation that needs review ("#, fuzzy")
+const FlagFuzzy = "fuzzy"
+
 // MsgEntry represents a single translation entry in a .po file
 type MsgEntry struct {
-	MsgID      string   `json:"msgid"`
-	MsgStr     string   `json:"msgstr"`
-	Comments   []string `json:"comments,omitempty"`
-	References []string `json:"references,omitempty"`
-	RawLines   []string `json:"-"` // Original raw lines from .po file (not included in JSON)
+	MsgCtxt           string   `json:"msgctxt,omitempty"`
+	MsgID             string   `json:"msgid"`
+	MsgIDPlural       string   `json:"msgid_plural,omitempty"`
+	MsgStr            string   `json:"msgstr"`
+	MsgStrPlural      []string `json:"msgstr_plural,omitempty"`      // msgstr[0], msgstr[1], ... for plural entries
+	Comments          []string `json:"comments,omitempty"`           // Translator comments ("# ")
+	ExtractedComments []string `json:"extracted_comments,omitempty"` // Developer comments extracted from source ("#.")
+	References        []string `json:"references,omitempty"`         // Source references ("#:")
+	Flags             []string `json:"flags,omitempty"`              // From "#," lines, e.g. fuzzy, elixir-format
+	Obsolete          bool     `json:"obsolete,omitempty"`           // Entry kept by msgmerge as "#~" lines
+
+	// Previous source strings of a fuzzy entry ("#| msgid ...")
+	PreviousMsgCtxt     string `json:"previous_msgctxt,omitempty"`
+	PreviousMsgID       string `json:"previous_msgid,omitempty"`
+	PreviousMsgIDPlural string `json:"previous_msgid_plural,omitempty"`
+
+	Position *Position `json:"position,omitempty"` // Where the entry was read from (nil for new entries)
+
+	RawLines []string `json:"-"` // Original raw lines from .po file (not included in JSON)
+	Trailer  []string `json:"-"` // Blank lines and stray comments following the entry in the file
+}
+
+// IsPlural returns true if the entry has a msgid_plural
+func (e *MsgEntry) IsPlural() bool {
+	return e.MsgIDPlural != ""
 }
 
-// IsEmpty returns true if the translation (msgstr) is empty
+// IsEmpty returns true if the translation (msgstr) is empty.
+// Plural entries are empty if any msgstr[N] slot is empty.
 func (e *MsgEntry) IsEmpty() bool {
+	if e.IsPlural() {
+		if len(e.MsgStrPlural) == 0 {
+			return true
+		}
+		for _, msgstr := range e.MsgStrPlural {
+			if msgstr == "" {
+				return true
+			}
+		}
+		return false
+	}
 	return e.MsgStr == ""
 }
+
+// IsFuzzy returns true if the entry is marked with the fuzzy flag
+func (e *MsgEntry) IsFuzzy() bool {
+	return e.HasFlag(FlagFuzzy)
+}
+
+// HasFlag returns true if the entry carries the given "#," flag
+func (e *MsgEntry) HasFlag(flag string) bool {
+	for _, f := range e.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFlag adds a "#," flag to the entry if it is not already present
+func (e *MsgEntry) AddFlag(flag string) {
+	if !e.HasFlag(flag) {
+		e.Flags = append(e.Flags, flag)
+	}
+}
+
+// RemoveFlag removes a "#," flag from the entry
+func (e *MsgEntry) RemoveFlag(flag string) {
+	var flags []string
+	for _, f := range e.Flags {
+		if f != flag {
+			flags = append(flags, f)
+		}
+	}
+	e.Flags = flags
+}
+
+// Sources returns the source strings: msgid, plus msgid_plural for plural entries
+func (e *MsgEntry) Sources() []string {
+	if e.IsPlural() {
+		return []string{e.MsgID, e.MsgIDPlural}
+	}
+	return []string{e.MsgID}
+}
+
+// Translations returns the translated strings: msgstr, or every msgstr[N] for plural entries
+func (e *MsgEntry) Translations() []string {
+	if e.IsPlural() {
+		return e.MsgStrPlural
+	}
+	return []string{e.MsgStr}
+}
+
+// Key returns the lookup key identifying this entry by (msgctxt, msgid)
+func (e *MsgEntry) Key() string {
+	return Key(e.MsgCtxt, e.MsgID)
+}
+
+// Key builds the lookup key for a (msgctxt, msgid) pair.
+// Entries without a context are keyed by msgid alone.
+func Key(msgctxt, msgid string) string {
+	if msgctxt == "" {
+		return msgid
+	}
+	return msgctxt + ContextSeparator + msgid
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HeaderField is a single "Key: Value" line of the .po header. A line that
+// is not a field (free text, no colon) has an empty Key and is kept as is in
+// Value, so that it is written back in place.
+type HeaderField struct {
+	Key   string
+	Value string
+}
+
+// String formats the field as a header line, without the newline
+func (f HeaderField) String() string {
+	if f.Key == "" {
+		return f.Value
+	}
+	return f.Key + ": " + f.Value
+}
+
+// Header is the parsed msgstr of the header entry (msgid ""), e.g.
+// Language, Plural-Forms and Content-Type. Field order is preserved.
+type Header struct {
+	Fields []HeaderField
+}
+
+// Well-known header keys
+const (
+	HeaderLanguage    = "Language"
+	HeaderPluralForms = "Plural-Forms"
+	HeaderContentType = "Content-Type"
+)
+
+var (
+	nplurals = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+	charset  = regexp.MustCompile(`charset\s*=\s*([^\s;]+)`)
+)
+
+// ParseHeader parses the msgstr of a header entry into its fields; lines
+// that are not fields are kept with an empty Key
+func ParseHeader(msgstr string) *Header {
+	h := &Header{}
+	if msgstr == "" {
+		return h
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(msgstr, "\n"), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			h.Fields = append(h.Fields, HeaderField{Value: line})
+			continue
+		}
+		h.Fields = append(h.Fields, HeaderField{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return h
+}
+
+// Get returns the value of a header field (case-insensitive key), or "" if missing
+func (h *Header) Get(key string) string {
+	for _, f := range h.Fields {
+		if f.Key != "" && strings.EqualFold(f.Key, key) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Has returns true if the header contains the given field
+func (h *Header) Has(key string) bool {
+	for _, f := range h.Fields {
+		if f.Key != "" && strings.EqualFold(f.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Set updates a header field in place, or appends it if missing
+func (h *Header) Set(key, value string) {
+	for i, f := range h.Fields {
+		if f.Key != "" && strings.EqualFold(f.Key, key) {
+			h.Fields[i].Value = value
+			return
+		}
+	}
+	h.Fields = append(h.Fields, HeaderField{Key: key, Value: value})
+}
+
+// Language returns the Language field
+func (h *Header) Language() string {
+	return h.Get(HeaderLanguage)
+}
+
+// PluralForms returns the Plural-Forms field
+func (h *Header) PluralForms() string {
+	return h.Get(HeaderPluralForms)
+}
+
+// NPlurals returns the number of plural forms declared in Plural-Forms, or 0 if unknown
+func (h *Header) NPlurals() int {
+	m := nplurals.FindStringSubmatch(h.PluralForms())
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// ContentType returns the Content-Type field
+func (h *Header) ContentType() string {
+	return h.Get(HeaderContentType)
+}
+
+// Charset returns the charset declared in Content-Type, or "" if missing
+func (h *Header) Charset() string {
+	m := charset.FindStringSubmatch(h.ContentType())
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// String formats the header as a header entry msgstr ("Key: Value\n" per field)
+func (h *Header) String() string {
+	var sb strings.Builder
+	for _, f := range h.Fields {
+		sb.WriteString(f.String() + "\n")
+	}
+	return sb.String()
+}
+
+// MarshalJSON encodes the header as a JSON object, keeping field order.
+// Lines that are not fields are left out.
+func (h *Header) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, f := range h.Fields {
+		if f.Key == "" {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
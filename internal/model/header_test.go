@@ -0,0 +1,74 @@
+package model
+
+import "testing"
+
+func TestParseHeader(t *testing.T) {
+	msgstr := "Language: sv\n" +
+		"Content-Type: text/plain; charset=ISO-8859-1\n" +
+		"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+	h := ParseHeader(msgstr)
+
+	if h.Language() != "sv" {
+		t.Errorf("expected language 'sv', got '%s'", h.Language())
+	}
+	if h.Charset() != "ISO-8859-1" {
+		t.Errorf("expected charset 'ISO-8859-1', got '%s'", h.Charset())
+	}
+	if h.NPlurals() != 2 {
+		t.Errorf("expected 2 plural forms, got %d", h.NPlurals())
+	}
+	if h.Get("language") != "sv" {
+		t.Error("expected case-insensitive lookup")
+	}
+
+	h.Set("Language", "de")
+	h.Set("Last-Translator", "Nille")
+
+	expected := "Language: de\n" +
+		"Content-Type: text/plain; charset=ISO-8859-1\n" +
+		"Plural-Forms: nplurals=2; plural=(n != 1);\n" +
+		"Last-Translator: Nille\n"
+	if h.String() != expected {
+		t.Errorf("unexpected header:\n%s\nexpected:\n%s", h.String(), expected)
+	}
+
+	data, err := h.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	expectedJSON := `{"Language":"de","Content-Type":"text/plain; charset=ISO-8859-1","Plural-Forms":"nplurals=2; plural=(n != 1);","Last-Translator":"Nille"}`
+	if string(data) != expectedJSON {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+func TestParseHeader_FreeText(t *testing.T) {
+	msgstr := "Language: sv\n" +
+		"some free text line\n" +
+		"Content-Type: text/plain; charset=UTF-8\n"
+
+	h := ParseHeader(msgstr)
+	if h.String() != msgstr {
+		t.Errorf("expected the header to be written back as is, got:\n%s", h.String())
+	}
+	if h.Has("some free text line") {
+		t.Error("expected a line without a colon not to be a field")
+	}
+
+	h.Set("Language", "de")
+	expected := "Language: de\n" +
+		"some free text line\n" +
+		"Content-Type: text/plain; charset=UTF-8\n"
+	if h.String() != expected {
+		t.Errorf("unexpected header:\n%s\nexpected:\n%s", h.String(), expected)
+	}
+
+	data, err := h.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	if string(data) != `{"Language":"de","Content-Type":"text/plain; charset=UTF-8"}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
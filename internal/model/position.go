@@ -0,0 +1,11 @@
+package model
+
+// Position records where an entry was read from, so that tools can jump to it or cite it
+type Position struct {
+	File        string `json:"file,omitempty"`     // Catalog path ("" when read from stdin)
+	Language    string `json:"language,omitempty"` // Catalog language, from the path or the header
+	StartLine   int    `json:"start_line"`         // 1-based line of the entry's first line
+	EndLine     int    `json:"end_line"`           // 1-based line of the entry's last line
+	StartOffset int64  `json:"start_offset"`       // Byte offset of the entry's first line
+	EndOffset   int64  `json:"end_offset"`         // Byte offset just past the entry's last line
+}
@@ -3,9 +3,11 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
 )
 
 // OutputEntry outputs a single entry in text or JSON format
@@ -26,93 +28,312 @@ func OutputEntryJSON(entry *model.MsgEntry) error {
 	return nil
 }
 
-// FormatEntry returns an entry formatted as .po text
+// FormatEntry returns an entry formatted as .po text, ready to be written back
+// to a file. Entries read from a file keep their original lines byte for byte,
+// including the blank lines and stray comments that followed them; only the
+// parts whose fields changed are re-encoded. Entries without raw lines are
+// reconstructed from their fields and end with a blank line.
 func FormatEntry(entry *model.MsgEntry) string {
+	if len(entry.RawLines) == 0 {
+		return formatFields(entry) + "\n"
+	}
+
 	var sb strings.Builder
+	sb.WriteString(formatRawLines(entry))
+	for _, line := range entry.Trailer {
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
 
-	// If we have raw lines, use them and only replace msgstr
-	if len(entry.RawLines) > 0 {
-		inMsgStr := false
-		for _, line := range entry.RawLines {
-			trimmed := strings.TrimSpace(line)
-
-			// Detect start of msgstr
-			if strings.HasPrefix(trimmed, "msgstr ") {
-				inMsgStr = true
-				// Replace msgstr line with new value
-				if strings.Contains(entry.MsgStr, "\n") {
-					sb.WriteString("msgstr \"\"\n")
-					for _, msgLine := range strings.Split(entry.MsgStr, "\n") {
-						sb.WriteString(fmt.Sprintf("\"%s\\n\"\n", escapeString(msgLine)))
-					}
-				} else {
-					sb.WriteString(fmt.Sprintf("msgstr \"%s\"\n", escapeString(entry.MsgStr)))
-				}
-				continue
-			}
+// part identifies a group of lines in an entry, in the order gettext writes them
+type part int
+
+const (
+	partOther     part = iota // Unrecognized lines, always kept as-is
+	partComment               // "# " translator comments
+	partExtracted             // "#." extracted comments
+	partReference             // "#:" references
+	partFlags                 // "#," flags
+	partPrevious              // "#|" previous strings
+	partMsgCtxt
+	partMsgID
+	partMsgIDPlural
+	partMsgStr // msgstr or msgstr[N]
+	partEnd
+)
+
+// formatRawLines formats an entry from its raw lines, replacing only the parts
+// whose fields differ from what the raw lines contain
+func formatRawLines(entry *model.MsgEntry) string {
+	original := parseRawLines(entry.RawLines)
 
-			// Skip continuation lines of msgstr (they start with ")
-			if inMsgStr && strings.HasPrefix(trimmed, "\"") {
-				continue
+	// Keep the line ending style of the entry for re-encoded parts
+	eol := "\n"
+	if strings.HasSuffix(entry.RawLines[0], "\r") {
+		eol = "\r\n"
+	}
+	withEOL := func(s string) string {
+		if eol == "\n" {
+			return s
+		}
+		return strings.ReplaceAll(s, "\n", eol)
+	}
+
+	// Turning an entry (non-)obsolete changes every line
+	if original.Obsolete != entry.Obsolete {
+		return withEOL(formatFields(entry))
+	}
+
+	changed := map[part]bool{
+		partComment:     !slices.Equal(original.Comments, entry.Comments),
+		partExtracted:   !slices.Equal(original.ExtractedComments, entry.ExtractedComments),
+		partReference:   !slices.Equal(original.References, entry.References),
+		partFlags:       !slices.Equal(original.Flags, entry.Flags),
+		partPrevious:    original.PreviousMsgCtxt != entry.PreviousMsgCtxt || original.PreviousMsgID != entry.PreviousMsgID || original.PreviousMsgIDPlural != entry.PreviousMsgIDPlural,
+		partMsgCtxt:     original.MsgCtxt != entry.MsgCtxt,
+		partMsgID:       original.MsgID != entry.MsgID,
+		partMsgIDPlural: original.MsgIDPlural != entry.MsgIDPlural,
+		partMsgStr:      original.MsgStr != entry.MsgStr || !slices.Equal(original.MsgStrPlural, entry.MsgStrPlural) || original.IsPlural() != entry.IsPlural(),
+	}
+
+	parts := classifyLines(entry.RawLines)
+	present := make(map[part]bool)
+	for _, p := range parts {
+		present[p] = true
+	}
+
+	var sb strings.Builder
+	written := make(map[part]bool)
+
+	// Changed parts the original lacks are inserted before the first later part
+	insertMissing := func(before part) {
+		for p := partComment; p < before; p++ {
+			if changed[p] && !present[p] && !written[p] {
+				sb.WriteString(withEOL(formatPart(entry, p)))
+				written[p] = true
 			}
+		}
+	}
 
-			// Stop skipping after msgstr is done
-			if inMsgStr && !strings.HasPrefix(trimmed, "\"") {
-				inMsgStr = false
+	for i, line := range entry.RawLines {
+		p := parts[i]
+		if p != partOther {
+			insertMissing(p)
+		}
+		if changed[p] {
+			// Write the new lines of a changed part in place of its first line
+			if !written[p] {
+				sb.WriteString(withEOL(formatPart(entry, p)))
+				written[p] = true
 			}
+			continue
+		}
+		sb.WriteString(line + "\n")
+	}
+	insertMissing(partEnd)
 
-			// Output original line
-			sb.WriteString(line + "\n")
+	return sb.String()
+}
+
+// classifyLines returns the part each raw line of an entry belongs to.
+// Continuation lines belong to the keyword before them.
+func classifyLines(lines []string) []part {
+	parts := make([]part, len(lines))
+	current := partOther
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#~") && !strings.HasPrefix(trimmed, "#~|") {
+			trimmed = strings.TrimSpace(trimmed[2:])
+		}
+
+		p := partOther
+		switch {
+		case strings.HasPrefix(trimmed, "#|"), strings.HasPrefix(trimmed, "#~|"):
+			p = partPrevious
+		case strings.HasPrefix(trimmed, "#:"):
+			p = partReference
+		case strings.HasPrefix(trimmed, "#,"):
+			p = partFlags
+		case strings.HasPrefix(trimmed, "#."):
+			p = partExtracted
+		case strings.HasPrefix(trimmed, "#"):
+			p = partComment
+		case strings.HasPrefix(trimmed, "msgctxt "):
+			p, current = partMsgCtxt, partMsgCtxt
+		case strings.HasPrefix(trimmed, "msgid_plural "):
+			p, current = partMsgIDPlural, partMsgIDPlural
+		case strings.HasPrefix(trimmed, "msgid "):
+			p, current = partMsgID, partMsgID
+		case strings.HasPrefix(trimmed, "msgstr"):
+			p, current = partMsgStr, partMsgStr
+		case strings.HasPrefix(trimmed, "\""):
+			p = current
 		}
-		sb.WriteString("\n") // Blank line between entries
-		return sb.String()
+		parts[i] = p
 	}
+	return parts
+}
 
-	// Fallback: reconstruct from fields (shouldn't happen in translate command)
-	// Output comments with # prefix
-	for _, comment := range entry.Comments {
-		sb.WriteString(fmt.Sprintf("# %s\n", comment))
+// formatFields reconstructs an entry from its fields, without a trailing blank line
+func formatFields(entry *model.MsgEntry) string {
+	var sb strings.Builder
+	for p := partComment; p < partEnd; p++ {
+		sb.WriteString(formatPart(entry, p))
 	}
+	return sb.String()
+}
+
+// formatPart formats one part of an entry from its fields. Keyword lines of
+// obsolete entries are prefixed with "#~".
+func formatPart(entry *model.MsgEntry, p part) string {
+	var sb strings.Builder
 
-	// Output references with #: prefix
-	for _, ref := range entry.References {
-		sb.WriteString(fmt.Sprintf("#: %s\n", ref))
+	prefix := ""
+	if entry.Obsolete {
+		prefix = "#~ "
 	}
+	width := entryWidth(entry)
 
-	// Output msgid (handle multi-line)
-	if strings.Contains(entry.MsgID, "\n") {
-		sb.WriteString("msgid \"\"\n")
-		for _, line := range strings.Split(entry.MsgID, "\n") {
-			sb.WriteString(fmt.Sprintf("\"%s\\n\"\n", escapeString(line)))
+	switch p {
+	case partComment:
+		for _, comment := range entry.Comments {
+			sb.WriteString(fmt.Sprintf("# %s\n", comment))
+		}
+	case partExtracted:
+		for _, comment := range entry.ExtractedComments {
+			sb.WriteString(fmt.Sprintf("#. %s\n", comment))
+		}
+	case partReference:
+		for _, ref := range entry.References {
+			sb.WriteString(fmt.Sprintf("#: %s\n", ref))
+		}
+	case partFlags:
+		if len(entry.Flags) > 0 {
+			sb.WriteString(formatFlags(entry.Flags))
+		}
+	case partPrevious:
+		sb.WriteString(formatPrevious(entry))
+	case partMsgCtxt:
+		if entry.MsgCtxt != "" {
+			sb.WriteString(formatString(prefix, "msgctxt", entry.MsgCtxt, width))
+		}
+	case partMsgID:
+		sb.WriteString(formatString(prefix, "msgid", entry.MsgID, width))
+	case partMsgIDPlural:
+		if entry.IsPlural() {
+			sb.WriteString(formatString(prefix, "msgid_plural", entry.MsgIDPlural, width))
+		}
+	case partMsgStr:
+		if entry.IsPlural() {
+			sb.WriteString(formatPluralMsgStr(entry, prefix, width))
+		} else {
+			sb.WriteString(formatString(prefix, "msgstr", entry.MsgStr, width))
 		}
-	} else {
-		sb.WriteString(fmt.Sprintf("msgid \"%s\"\n", escapeString(entry.MsgID)))
 	}
+	return sb.String()
+}
 
-	// Output msgstr (handle multi-line)
-	if strings.Contains(entry.MsgStr, "\n") {
-		sb.WriteString("msgstr \"\"\n")
-		for _, line := range strings.Split(entry.MsgStr, "\n") {
-			sb.WriteString(fmt.Sprintf("\"%s\\n\"\n", escapeString(line)))
-		}
-	} else {
-		sb.WriteString(fmt.Sprintf("msgstr \"%s\"\n", escapeString(entry.MsgStr)))
+// formatFlags formats flags as a single "#," comment line
+func formatFlags(flags []string) string {
+	return "#, " + strings.Join(flags, ", ") + "\n"
+}
+
+// formatPrevious formats the previous strings of an entry as "#|" lines
+// ("#~|" for obsolete entries)
+func formatPrevious(entry *model.MsgEntry) string {
+	prefix := "#| "
+	if entry.Obsolete {
+		prefix = "#~| "
 	}
+	width := entryWidth(entry)
 
-	sb.WriteString("\n") // Blank line between entries
+	var sb strings.Builder
+	if entry.PreviousMsgCtxt != "" {
+		sb.WriteString(formatString(prefix, "msgctxt", entry.PreviousMsgCtxt, width))
+	}
+	if entry.PreviousMsgID != "" {
+		sb.WriteString(formatString(prefix, "msgid", entry.PreviousMsgID, width))
+	}
+	if entry.PreviousMsgIDPlural != "" {
+		sb.WriteString(formatString(prefix, "msgid_plural", entry.PreviousMsgIDPlural, width))
+	}
 	return sb.String()
 }
 
-// escapeString escapes special characters for .po file format
-func escapeString(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	s = strings.ReplaceAll(s, "\t", "\\t")
-	return s
+// parseRawLines re-parses an entry's raw lines to recover its original field
+// values. Raw lines are already UTF-8, so the header's charset is not applied.
+func parseRawLines(rawLines []string) *model.MsgEntry {
+	p := parser.NewUTF8Parser(strings.NewReader(strings.Join(rawLines, "\n")))
+	if original := p.Next(); original != nil {
+		return original
+	}
+	// The header entry is not returned by Next
+	if header := p.HeaderEntry(); header != nil {
+		return header
+	}
+	return &model.MsgEntry{}
+}
+
+// formatPluralMsgStr formats msgstr[N] lines for a plural entry.
+// An entry without any plural forms still gets empty msgstr[0] and msgstr[1] slots.
+func formatPluralMsgStr(entry *model.MsgEntry, prefix string, width int) string {
+	forms := entry.MsgStrPlural
+	if len(forms) == 0 {
+		forms = []string{"", ""}
+	}
+
+	var sb strings.Builder
+	for i, msgstr := range forms {
+		sb.WriteString(formatString(prefix, fmt.Sprintf("msgstr[%d]", i), msgstr, width))
+	}
+	return sb.String()
 }
 
-// OutputEntryText outputs an entry in .po text format
+// EscapeString escapes s for use inside a quoted .po string in a single pass.
+// It is the inverse of parser.Unescape: backslashes, quotes and control
+// characters with a C escape are written as such, other control characters in octal.
+func EscapeString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			sb.WriteString("\\\\")
+		case '"':
+			sb.WriteString("\\\"")
+		case '\a':
+			sb.WriteString("\\a")
+		case '\b':
+			sb.WriteString("\\b")
+		case '\f':
+			sb.WriteString("\\f")
+		case '\n':
+			sb.WriteString("\\n")
+		case '\r':
+			sb.WriteString("\\r")
+		case '\t':
+			sb.WriteString("\\t")
+		case '\v':
+			sb.WriteString("\\v")
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&sb, "\\%03o", c)
+			} else {
+				sb.WriteByte(c)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// OutputEntryText outputs an entry in .po text format, followed by a blank line
 func OutputEntryText(entry *model.MsgEntry) error {
-	fmt.Print(FormatEntry(entry))
+	if len(entry.RawLines) == 0 {
+		fmt.Print(formatFields(entry) + "\n")
+		return nil
+	}
+	fmt.Print(formatRawLines(entry) + "\n")
 	return nil
 }
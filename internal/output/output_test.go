@@ -0,0 +1,187 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+func TestFormatEntry_FlagsRewritten(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		flags    []string
+		expected string
+	}{
+		{
+			name: "fuzzy cleared, other flags kept",
+			input: `#: lib/page.ex:1
+#, fuzzy, elixir-format
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+			flags: []string{"elixir-format"},
+			expected: `#: lib/page.ex:1
+#, elixir-format
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+		},
+		{
+			name: "only flag removed",
+			input: `#, fuzzy
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+			flags: nil,
+			expected: `msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+		},
+		{
+			name: "flag added",
+			input: `#: lib/page.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+			flags: []string{"fuzzy"},
+			expected: `#: lib/page.ex:1
+#, fuzzy
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+		},
+		{
+			name: "unchanged flags keep raw formatting",
+			input: `#,fuzzy,elixir-format
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+			flags: []string{"fuzzy", "elixir-format"},
+			expected: `#,fuzzy,elixir-format
+msgid "Welcome"
+msgstr "Välkommen"
+
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := parser.NewParser(strings.NewReader(tt.input)).Next()
+			if entry == nil {
+				t.Fatal("expected entry, got nil")
+			}
+
+			entry.Flags = tt.flags
+			if got := FormatEntry(entry); got != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatEntry_CommentMarkers(t *testing.T) {
+	entry := &model.MsgEntry{
+		MsgID:             "Sign In",
+		MsgStr:            "Logga in",
+		Comments:          []string{"Translator note"},
+		ExtractedComments: []string{"Shown on the login page"},
+		References:        []string{"lib/web/live/page.ex:24"},
+		Flags:             []string{"fuzzy"},
+		PreviousMsgID:     "Sign in",
+	}
+
+	expected := `# Translator note
+#. Shown on the login page
+#: lib/web/live/page.ex:24
+#, fuzzy
+#| msgid "Sign in"
+msgid "Sign In"
+msgstr "Logga in"
+
+`
+	if got := FormatEntry(entry); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormatEntry_PreviousCleared(t *testing.T) {
+	input := `#, fuzzy
+#| msgid "Sign in"
+msgid "Sign In"
+msgstr "Logga in"
+
+`
+	entry := parser.NewParser(strings.NewReader(input)).Next()
+	if entry == nil {
+		t.Fatal("expected entry, got nil")
+	}
+
+	translation := &parser.Translation{MsgID: "Sign In", MsgStr: "Logga in"}
+	if _, err := translation.Apply(entry, 0); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	expected := `msgid "Sign In"
+msgstr "Logga in"
+
+`
+	if got := FormatEntry(entry); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+// trickyStrings exercise every escape sequence gettext knows about
+var trickyStrings = []string{
+	"",
+	"plain text",
+	`back\slash`,
+	`a literal \n, not a newline`,
+	`\\n`,
+	"quote \" inside",
+	"line one\nline two\n",
+	"windows\r\nline end",
+	"bell\a backspace\b formfeed\f vtab\v tab\t",
+	"\x1b[1mbold\x1b[0m",
+	"nul\x00byte",
+	"del\x7f",
+	"trailing backslash \\",
+	"Välkommen 日本語",
+}
+
+func TestEscapeString_RoundTrip(t *testing.T) {
+	for _, s := range trickyStrings {
+		escaped := EscapeString(s)
+		if strings.ContainsAny(escaped, "\n\r\a\b\f\v\t\x00\x1b\x7f") {
+			t.Errorf("EscapeString(%q) = %q contains a raw control character", s, escaped)
+		}
+		if got := parser.Unescape(escaped); got != s {
+			t.Errorf("Unescape(EscapeString(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestFormatEntry_EscapeRoundTrip(t *testing.T) {
+	for _, s := range trickyStrings {
+		entry := &model.MsgEntry{MsgID: "key", MsgStr: s}
+		formatted := FormatEntry(entry)
+
+		parsed := parser.NewParser(strings.NewReader(formatted)).Next()
+		if parsed == nil {
+			t.Fatalf("failed to parse formatted entry:\n%s", formatted)
+		}
+		if parsed.MsgStr != s {
+			t.Errorf("msgstr %q came back as %q from:\n%s", s, parsed.MsgStr, formatted)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/charset"
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+// rewrite parses a catalog and writes it back the way the editor and the
+// translate command do, applying change to every entry
+func rewrite(t *testing.T, data []byte, change func(*model.MsgEntry)) []byte {
+	t.Helper()
+
+	p := parser.NewParser(bytes.NewReader(data))
+	var entries []*model.MsgEntry
+	for {
+		entry := p.Next()
+		if entry == nil {
+			break
+		}
+		if change != nil {
+			change(entry)
+		}
+		entries = append(entries, entry)
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := charset.NewWriter(&buf, p.Charset())
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, line := range p.Header() {
+		w.Write([]byte(line + "\n"))
+	}
+	for _, entry := range entries {
+		w.Write([]byte(FormatEntry(entry)))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("encoding failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// catalogs returns the real-world catalogs in testdata, plus CRLF variants
+func catalogs(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	paths, err := filepath.Glob("testdata/*.po")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no testdata catalogs found: %v", err)
+	}
+
+	result := make(map[string][]byte)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		name := filepath.Base(path)
+		result[name] = data
+		result[name+" (CRLF)"] = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+	return result
+}
+
+func TestRoundTrip_Untouched(t *testing.T) {
+	for name, data := range catalogs(t) {
+		t.Run(name, func(t *testing.T) {
+			if got := rewrite(t, data, nil); !bytes.Equal(got, data) {
+				t.Errorf("round trip changed the catalog:\n%s\nexpected:\n%s", got, data)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_OnlyChangedEntryDiffers(t *testing.T) {
+	for name, data := range catalogs(t) {
+		t.Run(name, func(t *testing.T) {
+			changedOne := false
+			got := rewrite(t, data, func(entry *model.MsgEntry) {
+				if !changedOne && !entry.IsPlural() && !entry.Obsolete {
+					entry.MsgStr = "Ändrad"
+					changedOne = true
+				}
+			})
+
+			// Every line except the changed msgstr must be preserved
+			gotLines := strings.Split(string(got), "\n")
+			wantLines := strings.Split(string(data), "\n")
+			removed, added := diffLines(wantLines, gotLines)
+			if len(added) != 1 || !strings.Contains(added[0], `msgstr "`) {
+				t.Errorf("expected only a new msgstr line, added: %q, removed: %q", added, removed)
+			}
+			for _, line := range removed {
+				trimmed := strings.TrimSpace(line)
+				if !strings.HasPrefix(trimmed, "msgstr") && !strings.HasPrefix(trimmed, "\"") {
+					t.Errorf("unexpected removed line: %q", line)
+				}
+			}
+		})
+	}
+}
+
+// diffLines returns the lines only in want and only in got, comparing the
+// common prefix and suffix (enough for a single changed region)
+func diffLines(want, got []string) ([]string, []string) {
+	start := 0
+	for start < len(want) && start < len(got) && want[start] == got[start] {
+		start++
+	}
+	end := 0
+	for end < len(want)-start && end < len(got)-start && want[len(want)-1-end] == got[len(got)-1-end] {
+		end++
+	}
+	return want[start : len(want)-end], got[start : len(got)-end]
+}
+
+func TestFormatEntry_GNULayout(t *testing.T) {
+	data, err := os.ReadFile("testdata/gnu_msgmerge.po")
+	if err != nil {
+		t.Fatalf("failed to read catalog: %v", err)
+	}
+
+	// The catalog follows GNU msgmerge's layout (79-column wrapping, its escapes),
+	// so entries formatted from their fields alone must match it line for line
+	p := parser.NewParser(bytes.NewReader(data))
+	for entry := p.Next(); entry != nil; entry = p.Next() {
+		raw := strings.Join(entry.RawLines, "\n") + "\n"
+		entry.RawLines = nil
+		if got := strings.TrimSuffix(FormatEntry(entry), "\n"); got != raw {
+			t.Errorf("entry at line %d formatted differently:\n%s\nexpected:\n%s", entry.Position.StartLine, got, raw)
+		}
+	}
+}
+
+func TestFormatEntry_LatinHeaderComments(t *testing.T) {
+	input := "#Svensk översättning\n" +
+		"#\n" +
+		"msgid \"\"\n" +
+		"msgstr \"\"\n" +
+		"\"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n" +
+		"\n" +
+		"msgid \"Open\"\n" +
+		"msgstr \"Öppna\"\n"
+	var data bytes.Buffer
+	w, err := charset.NewWriter(&data, "ISO-8859-1")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	w.Write([]byte(input))
+	if err := w.Close(); err != nil {
+		t.Fatalf("encoding failed: %v", err)
+	}
+
+	p := parser.NewParser(&data)
+	for p.Next() != nil {
+	}
+	header := p.HeaderEntry()
+	if header == nil {
+		t.Fatal("expected a header entry")
+	}
+	header.MsgStr += "Language: sv\n"
+
+	// Only the msgstr changes; the decoded comments are kept as they were
+	got := FormatEntry(header)
+	if !strings.HasPrefix(got, "#Svensk översättning\n#\nmsgid \"\"\n") {
+		t.Errorf("expected header comments to be kept, got:\n%s", got)
+	}
+}
+
+func TestFormatEntry_MultilineMsgStr(t *testing.T) {
+	input := `msgid "Address"
+msgstr "Adress"
+
+`
+	entry := parser.NewParser(strings.NewReader(input)).Next()
+	if entry == nil {
+		t.Fatal("expected entry, got nil")
+	}
+
+	entry.MsgStr = "a\nb"
+	expected := `msgid "Address"
+msgstr ""
+"a\n"
+"b"
+
+`
+	if got := FormatEntry(entry); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormatEntry_FallbackKeepsAllFields(t *testing.T) {
+	entries := []*model.MsgEntry{
+		{
+			MsgCtxt:           "menu",
+			MsgID:             "%d file",
+			MsgIDPlural:       "%d files",
+			MsgStrPlural:      []string{"%d fil", "%d filer"},
+			Comments:          []string{"Translator note"},
+			ExtractedComments: []string{"Developer note"},
+			References:        []string{"lib/page.ex:1"},
+			Flags:             []string{"fuzzy", "elixir-format"},
+			PreviousMsgID:     "%d document",
+		},
+		{
+			MsgCtxt:  "menu",
+			MsgID:    "Quit",
+			MsgStr:   "Avsluta\n",
+			Obsolete: true,
+		},
+	}
+
+	for _, entry := range entries {
+		parsed := parser.NewParser(strings.NewReader(FormatEntry(entry))).Next()
+		if parsed == nil {
+			t.Fatalf("failed to parse:\n%s", FormatEntry(entry))
+		}
+		parsed.RawLines = nil
+		parsed.Trailer = nil
+		parsed.Position = nil
+
+		got, _ := json.Marshal(parsed)
+		want, _ := json.Marshal(entry)
+		if !bytes.Equal(got, want) {
+			t.Errorf("fields lost in fallback:\n got %s\nwant %s", got, want)
+		}
+	}
+}
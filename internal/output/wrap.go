@@ -0,0 +1,142 @@
+package output
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"golang.org/x/text/width"
+)
+
+// DefaultWidth is the page width GNU gettext tools wrap strings at
+const DefaultWidth = 79
+
+// wrapWidth is the page width used when re-encoding strings (0 = no wrapping)
+var wrapWidth = DefaultWidth
+
+// SetWrapWidth sets the page width that re-encoded strings are wrapped at,
+// like msgcat --width. A width of 0 disables wrapping like msgcat --no-wrap:
+// strings are then only split after embedded newlines.
+func SetWrapWidth(w int) {
+	if w < 0 {
+		w = 0
+	}
+	wrapWidth = w
+}
+
+// entryWidth returns the wrap width for an entry; entries flagged
+// "#, no-wrap" are never wrapped, as in gettext
+func entryWidth(entry *model.MsgEntry) int {
+	if entry.HasFlag("no-wrap") {
+		return 0
+	}
+	return wrapWidth
+}
+
+// formatString formats a keyword and its value as .po lines the way GNU
+// gettext does. The value is split after each embedded "\n", and each part is
+// wrapped at break opportunities so that lines, including prefix (e.g. "#~ ")
+// and quotes, fit in width columns. A value that needs more than one line
+// starts with an empty string on the keyword line:
+//
+//	msgstr ""
+//	"Language: sv\n"
+//	"Content-Type: text/plain; charset=UTF-8\n"
+func formatString(prefix, keyword, value string, width int) string {
+	portions := strings.SplitAfter(value, "\n")
+	if len(portions) > 1 && portions[len(portions)-1] == "" {
+		portions = portions[:len(portions)-1]
+	}
+
+	// A single part that needs no break stays on the keyword line, even if
+	// it overflows (e.g. a long URL without break opportunities)
+	if len(portions) == 1 {
+		escaped := EscapeString(value)
+		available := width - textWidth(prefix+keyword) - 3 // Space and quotes
+		if width == 0 || len(wrapPortion(escaped, available)) == 1 {
+			return prefix + keyword + " \"" + escaped + "\"\n"
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prefix + keyword + " \"\"\n")
+	for _, portion := range portions {
+		escaped := EscapeString(portion)
+		lines := []string{escaped}
+		if width > 0 {
+			lines = wrapPortion(escaped, width-textWidth(prefix)-2)
+		}
+		for _, line := range lines {
+			sb.WriteString(prefix + "\"" + line + "\"\n")
+		}
+	}
+	return sb.String()
+}
+
+// wrapPortion breaks an escaped string into lines of at most available
+// columns, filling each line greedily. Text between break opportunities is
+// never split, so a line can still overflow.
+func wrapPortion(escaped string, available int) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+
+	for _, piece := range splitPieces(escaped) {
+		pieceWidth := textWidth(piece)
+		if line.Len() > 0 && lineWidth+pieceWidth > available {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		line.WriteString(piece)
+		lineWidth += pieceWidth
+	}
+	return append(lines, line.String())
+}
+
+// splitPieces splits an escaped string at its line break opportunities, a
+// simplified version of the Unicode line breaking rules gettext uses: after
+// spaces, after a hyphen between letters, and between wide (CJK) characters.
+// There is no break before closing punctuation, quotes or escape sequences.
+func splitPieces(s string) []string {
+	runes := []rune(s)
+	var pieces []string
+	start := 0
+
+	for i := 1; i < len(runes); i++ {
+		prev, r := runes[i-1], runes[i]
+		if strings.ContainsRune(`\!?,.:;)]}"'`, r) {
+			continue
+		}
+		afterSpace := prev == ' ' && r != ' '
+		afterHyphen := prev == '-' && i >= 2 && unicode.IsLetter(runes[i-2]) && unicode.IsLetter(r)
+		betweenWide := isWide(prev) && isWide(r)
+		if afterSpace || afterHyphen || betweenWide {
+			pieces = append(pieces, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(pieces, string(runes[start:]))
+}
+
+// textWidth returns the number of columns s occupies in a terminal
+func textWidth(s string) int {
+	columns := 0
+	for _, r := range s {
+		if isWide(r) {
+			columns += 2
+		} else {
+			columns++
+		}
+	}
+	return columns
+}
+
+// isWide reports whether r is an East Asian wide or fullwidth character
+func isWide(r rune) bool {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+func TestFormatString_Wrapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		value    string
+		width    int
+		expected string
+	}{
+		{
+			name:     "short value stays on keyword line",
+			value:    "Hello, world!",
+			width:    79,
+			expected: "msgid \"Hello, world!\"\n",
+		},
+		{
+			name:  "long value wraps after spaces",
+			value: "This is a very long message that xgettext wraps at seventy-nine columns so that the file stays readable",
+			width: 79,
+			expected: "msgid \"\"\n" +
+				"\"This is a very long message that xgettext wraps at seventy-nine columns so \"\n" +
+				"\"that the file stays readable\"\n",
+		},
+		{
+			name:     "exactly fits the page width",
+			value:    strings.Repeat("x ", 35) + "x", // msgid "..." is 79 columns
+			width:    79,
+			expected: "msgid \"" + strings.Repeat("x ", 35) + "x\"\n",
+		},
+		{
+			name:  "one column too wide",
+			value: strings.Repeat("x ", 35) + "xx",
+			width: 79,
+			expected: "msgid \"\"\n" +
+				"\"" + strings.Repeat("x ", 35) + "xx\"\n",
+		},
+		{
+			name:     "no break opportunity keeps the keyword line",
+			value:    "https://example.com/" + strings.Repeat("a", 80),
+			width:    79,
+			expected: "msgid \"https://example.com/" + strings.Repeat("a", 80) + "\"\n",
+		},
+		{
+			name:  "embedded newlines split lines",
+			value: "Line one\nLine two\n",
+			width: 79,
+			expected: "msgid \"\"\n" +
+				"\"Line one\\n\"\n" +
+				"\"Line two\\n\"\n",
+		},
+		{
+			name:     "trailing newline alone stays on keyword line",
+			value:    "Usage: %s [OPTION]...\n",
+			width:    79,
+			expected: "msgid \"Usage: %s [OPTION]...\\n\"\n",
+		},
+		{
+			name:     "no wrapping",
+			value:    strings.Repeat("word ", 30),
+			width:    0,
+			expected: "msgid \"" + strings.Repeat("word ", 30) + "\"\n",
+		},
+		{
+			name:  "narrow width",
+			value: "one two three four",
+			width: 15,
+			expected: "msgid \"\"\n" +
+				"\"one two \"\n" +
+				"\"three four\"\n",
+		},
+		{
+			name:   "obsolete prefix counts towards the width",
+			prefix: "#~ ",
+			value:  "one two three",
+			width:  16,
+			expected: "#~ msgid \"\"\n" +
+				"#~ \"one two \"\n" +
+				"#~ \"three\"\n",
+		},
+		{
+			name:  "no break before punctuation after a space",
+			value: "Bonjour tout le monde !",
+			width: 22,
+			expected: "msgid \"\"\n" +
+				"\"Bonjour tout le \"\n" +
+				"\"monde !\"\n",
+		},
+		{
+			name:  "escape sequences are not split",
+			value: "say \"hello\" to everyone",
+			width: 18,
+			expected: "msgid \"\"\n" +
+				"\"say \\\"hello\\\" \"\n" +
+				"\"to everyone\"\n",
+		},
+		{
+			name:  "wide characters count as two columns",
+			value: "日本語のテキスト",
+			width: 10,
+			expected: "msgid \"\"\n" +
+				"\"日本語の\"\n" +
+				"\"テキスト\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatString(tt.prefix, "msgid", tt.value, tt.width)
+			if got != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatEntry_WrapWidth(t *testing.T) {
+	defer SetWrapWidth(DefaultWidth)
+
+	entry := &model.MsgEntry{MsgID: "Terms", MsgStr: "one two three four"}
+
+	SetWrapWidth(15)
+	expected := "msgid \"Terms\"\nmsgstr \"\"\n\"one two \"\n\"three four\"\n\n"
+	if got := FormatEntry(entry); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	// "#, no-wrap" entries are never wrapped
+	entry.Flags = []string{"no-wrap"}
+	expected = "#, no-wrap\nmsgid \"Terms\"\nmsgstr \"one two three four\"\n\n"
+	if got := FormatEntry(entry); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	SetWrapWidth(0)
+	entry.Flags = nil
+	expected = "msgid \"Terms\"\nmsgstr \"one two three four\"\n\n"
+	if got := FormatEntry(entry); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
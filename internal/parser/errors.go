@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// SyntaxError describes malformed .po input found in strict mode
+type SyntaxError struct {
+	File   string `json:"file,omitempty"` // Catalog path, if known (see SetFilename)
+	Line   int    `json:"line"`           // 1-based line number
+	Column int    `json:"column"`         // 1-based byte column (0 for the whole line)
+	Msg    string `json:"message"`
+}
+
+// Error formats the error like compiler diagnostics: file:line:column: message
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		// Not tied to a position, e.g. an unreadable file
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	}
+	position := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.Column == 0 {
+		position = fmt.Sprintf("%d", e.Line)
+	}
+	if e.File == "" {
+		return fmt.Sprintf("%s: %s", position, e.Msg)
+	}
+	return fmt.Sprintf("%s:%s: %s", e.File, position, e.Msg)
+}
+
+// SetStrict enables strict mode, in which malformed input is reported as
+// SyntaxErrors instead of being silently accepted or dropped
+func (p *Parser) SetStrict(strict bool) {
+	p.strict = strict
+	if strict && p.seen == nil {
+		p.seen = make(map[string]int)
+	}
+}
+
+// SetFilename sets the catalog path used in error messages
+func (p *Parser) SetFilename(name string) {
+	p.filename = name
+}
+
+// Errors returns every syntax error found so far in strict mode
+func (p *Parser) Errors() []*SyntaxError {
+	return p.syntaxErrors
+}
+
+// syntaxError records a syntax error at the given position (strict mode only)
+func (p *Parser) syntaxError(line, column int, format string, args ...any) {
+	if !p.strict {
+		return
+	}
+	p.syntaxErrors = append(p.syntaxErrors, &SyntaxError{
+		File:   p.filename,
+		Line:   line,
+		Column: column,
+		Msg:    fmt.Sprintf(format, args...),
+	})
+}
+
+// checkString reports a malformed quoted string s, which starts at column of the current line
+func (p *Parser) checkString(column int, s string) {
+	if !p.strict {
+		return
+	}
+	lead := len(s) - len(strings.TrimLeft(s, " \t"))
+	s = strings.TrimSpace(s)
+	if offset, msg := quoteError(s); msg != "" {
+		p.syntaxError(p.line, column+lead+offset, "%s", msg)
+	}
+}
+
+// checkEntry reports a complete entry without msgstr, or one whose
+// (msgctxt, msgid) was already defined (strict mode only)
+func (p *Parser) checkEntry(entry *model.MsgEntry, line int, hasMsgStr bool) {
+	if !p.strict {
+		return
+	}
+	if !hasMsgStr {
+		p.syntaxError(line, 1, "missing msgstr for msgid %q", entry.MsgID)
+	}
+	if entry.Obsolete {
+		return
+	}
+	if first, ok := p.seen[entry.Key()]; ok {
+		p.syntaxError(line, 1, "duplicate message definition %q (first defined at line %d)", DisplayKey(entry.Key()), first)
+		return
+	}
+	p.seen[entry.Key()] = line
+}
+
+// quoteError checks that s is exactly one double-quoted string. It returns the
+// byte offset of the problem and a description, or an empty description if s is valid.
+func quoteError(s string) (int, string) {
+	if !strings.HasPrefix(s, "\"") {
+		return 0, "expected quoted string"
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && !strings.ContainsRune(`abfnrtv\"'?01234567x`, rune(s[i+1])) {
+				return i, fmt.Sprintf("invalid escape sequence \\%c", s[i+1])
+			}
+			i++ // Skip the escaped character
+		case '"':
+			if rest := strings.TrimLeft(s[i+1:], " \t"); rest != "" {
+				return len(s) - len(rest), fmt.Sprintf("unexpected text after string: %s", rest)
+			}
+			return 0, ""
+		}
+	}
+	return 0, "unterminated string"
+}
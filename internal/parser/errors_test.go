@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// strictErrors parses input in strict mode and returns the syntax errors
+func strictErrors(t *testing.T, input string) []*SyntaxError {
+	t.Helper()
+	p := NewParser(strings.NewReader(input))
+	p.SetStrict(true)
+	p.SetFilename("test.po")
+	for p.Next() != nil {
+	}
+	return p.Errors()
+}
+
+func TestStrict_ValidInput(t *testing.T) {
+	input := `msgid ""
+msgstr ""
+"Language: sv\n"
+
+#, fuzzy
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgid "Open"
+msgstr ""
+"Öppen"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] "%d filer"
+
+#~ msgid "Open"
+#~ msgstr "Gammal"
+`
+	if errs := strictErrors(t, input); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestStrict_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+		msg    string
+	}{
+		{
+			name:   "msgstr without msgid",
+			input:  "msgid \"A\"\nmsgstr \"a\"\n\nmsgstr \"b\"\n",
+			line:   4,
+			column: 1,
+			msg:    "msgstr without msgid",
+		},
+		{
+			name:   "unterminated quote",
+			input:  "msgid \"A\"\nmsgstr \"a\n",
+			line:   2,
+			column: 8,
+			msg:    "unterminated string",
+		},
+		{
+			name:   "unterminated continuation",
+			input:  "msgid \"A\"\nmsgstr \"\"\n  \"a\n",
+			line:   3,
+			column: 3,
+			msg:    "unterminated string",
+		},
+		{
+			name:   "continuation outside field",
+			input:  "# comment\n\"stray\"\nmsgid \"A\"\nmsgstr \"a\"\n",
+			line:   2,
+			column: 1,
+			msg:    "continuation line outside of any field",
+		},
+		{
+			name:   "duplicate msgid",
+			input:  "msgid \"A\"\nmsgstr \"a\"\n\nmsgid \"A\"\nmsgstr \"b\"\n",
+			line:   4,
+			column: 1,
+			msg:    `duplicate message definition "A" (first defined at line 1)`,
+		},
+		{
+			name:   "missing msgstr",
+			input:  "msgid \"A\"\n\nmsgid \"B\"\nmsgstr \"b\"\n",
+			line:   1,
+			column: 1,
+			msg:    `missing msgstr for msgid "A"`,
+		},
+		{
+			name:   "plural index without msgid_plural",
+			input:  "msgid \"A\"\nmsgstr[0] \"a\"\n",
+			line:   2,
+			column: 1,
+			msg:    "msgstr[0] without msgid_plural",
+		},
+		{
+			name:   "text after string",
+			input:  "msgid \"A\" x\nmsgstr \"a\"\n",
+			line:   1,
+			column: 11,
+			msg:    "unexpected text after string: x",
+		},
+		{
+			name:   "invalid escape",
+			input:  "msgid \"A\\q\"\nmsgstr \"a\"\n",
+			line:   1,
+			column: 9,
+			msg:    `invalid escape sequence \q`,
+		},
+		{
+			name:   "obsolete entry column",
+			input:  "#~ msgid \"A\"\n#~ msgstr \"a\n",
+			line:   2,
+			column: 11,
+			msg:    "unterminated string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := strictErrors(t, tt.input)
+			if len(errs) != 1 {
+				t.Fatalf("expected 1 error, got %v", errs)
+			}
+			err := errs[0]
+			if err.File != "test.po" || err.Line != tt.line || err.Column != tt.column || err.Msg != tt.msg {
+				t.Errorf("expected test.po:%d:%d: %s, got %s", tt.line, tt.column, tt.msg, err)
+			}
+		})
+	}
+}
+
+func TestStrict_DuplicateContextsAreDistinct(t *testing.T) {
+	input := `msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgid "Open"
+msgstr "Öppen"
+`
+	if errs := strictErrors(t, input); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestStrict_ErrReturnsSyntaxError(t *testing.T) {
+	_, err := ParseAll(strings.NewReader("msgid \"A\"\nmsgstr \"a\"\n\nmsgstr \"b\"\n"))
+	if err != nil {
+		t.Fatalf("expected lenient parsing without strict mode, got %v", err)
+	}
+
+	p := NewParser(strings.NewReader("msgid \"A\"\nmsgstr \"a\"\n\nmsgstr \"b\"\n"))
+	p.SetStrict(true)
+	for p.Next() != nil {
+	}
+
+	var syntaxErr *SyntaxError
+	if !errors.As(p.Err(), &syntaxErr) {
+		t.Fatalf("expected *SyntaxError, got %v", p.Err())
+	}
+	if syntaxErr.Error() != "4:1: msgstr without msgid" {
+		t.Errorf("unexpected error message: %s", syntaxErr)
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// lineReader reads input line by line like bufio.Scanner, but without its
+// 64 KiB token limit: only the current line is held in memory, however long it is
+type lineReader struct {
+	r      *bufio.Reader
+	err    error
+	offset int64 // Bytes consumed so far, including line terminators
+}
+
+// newLineReader creates a lineReader, reusing r's buffer if it is already buffered
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
+}
+
+// ReadLine returns the next line without its "\n" terminator, or false at end
+// of input or on a read error (see Err). The "\r" of a "\r\n" line ending is
+// kept so that CRLF catalogs can be written back unchanged.
+func (lr *lineReader) ReadLine() (string, bool) {
+	if lr.err != nil {
+		return "", false
+	}
+
+	line, err := lr.r.ReadString('\n')
+	if err != nil {
+		if err != io.EOF {
+			lr.err = err
+			return "", false
+		}
+		lr.err = io.EOF
+		if line == "" {
+			return "", false
+		}
+	}
+
+	lr.offset += int64(len(line))
+	return strings.TrimSuffix(line, "\n"), true
+}
+
+// Offset returns the number of input bytes consumed by the lines read so far
+func (lr *lineReader) Offset() int64 {
+	return lr.offset
+}
+
+// Err returns the first read error, or nil at a clean end of input
+func (lr *lineReader) Err() error {
+	if lr.err == io.EOF {
+		return nil
+	}
+	return lr.err
+}
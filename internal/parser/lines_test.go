@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineReader(t *testing.T) {
+	lr := newLineReader(strings.NewReader("one\r\ntwo\n\nlast"))
+
+	var lines []string
+	for {
+		line, ok := lr.ReadLine()
+		if !ok {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	expected := []string{"one\r", "two", "", "last"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+	if err := lr.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -4,24 +4,83 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
+	"github.com/xnilsson/poflow/internal/charset"
 	"github.com/xnilsson/poflow/internal/model"
+	"golang.org/x/text/encoding"
 )
 
 // Parser streams .po file entries one by one without loading entire file into memory
 type Parser struct {
-	scanner       *bufio.Scanner
-	err           error
-	header        []string // File header lines (comments before first entry)
-	headerParsed  bool
+	lines            *lineReader
+	err              error
+	header           []string // File header lines (comments before first entry)
+	headerParsed     bool
+	headerEntry      *model.MsgEntry // Header entry (msgid ""), if present
+	headerEntryIndex int             // Position of the header entry's raw lines in header
+	charset          string          // Charset declared in the header
+	decoder          *encoding.Decoder
+	pending          []sourceLine // Lines pushed back by unreadLine (last is read first)
+	nextLine         int          // Number of the next line read from input
+	line             int          // Number of the line last returned by readLine
+	language         string       // Language recorded in entry positions (see SetLanguage)
+	headerLanguage   string       // Language field of the header entry
+
+	filename string // Catalog path for positions and errors (see SetFilename)
+
+	// Strict mode (see SetStrict)
+	strict       bool
+	syntaxErrors []*SyntaxError
+	seen         map[string]int // Line of the first definition of each entry key
 }
 
-// NewParser creates a new streaming parser for .po files
+// NewParser creates a new streaming parser for .po files.
+// The charset declared in the header's Content-Type is detected from the first
+// bytes of the input, and non-UTF-8 catalogs are decoded to UTF-8.
 func NewParser(r io.Reader) *Parser {
-	return &Parser{
-		scanner: bufio.NewScanner(r),
+	// Peek at the start of the input to find the declared charset
+	br := bufio.NewReaderSize(r, charset.DetectLimit)
+	head, _ := br.Peek(charset.DetectLimit)
+
+	p := NewUTF8Parser(br)
+	p.charset = charset.Detect(head)
+
+	// Lines are split before decoding so that positions are byte offsets in the file
+	enc, err := charset.Lookup(p.charset)
+	if err != nil {
+		p.err = err
+	} else if enc != nil {
+		p.decoder = enc.NewDecoder()
+	}
+	return p
+}
+
+// NewUTF8Parser creates a parser for input that is UTF-8 whatever charset its
+// header declares, such as the raw lines of entries that were already decoded
+func NewUTF8Parser(r io.Reader) *Parser {
+	return &Parser{lines: newLineReader(r), nextLine: 1}
+}
+
+// SetLanguage sets the language recorded in entry positions. By default the
+// header's Language field is used.
+func (p *Parser) SetLanguage(language string) {
+	p.language = language
+}
+
+// entryLanguage returns the language recorded in entry positions
+func (p *Parser) entryLanguage() string {
+	if p.language != "" {
+		return p.language
 	}
+	return p.headerLanguage
+}
+
+// Charset returns the charset declared in the header's Content-Type ("" if none).
+// Entries are always returned as UTF-8; writers should encode back to this charset.
+func (p *Parser) Charset() string {
+	return p.charset
 }
 
 // Header returns the file header lines (comments before first entry)
@@ -29,6 +88,82 @@ func (p *Parser) Header() []string {
 	return p.header
 }
 
+// HeaderEntry returns the header entry (msgid "") or nil if the file has none.
+// It is available once the first entry has been read.
+func (p *Parser) HeaderEntry() *model.MsgEntry {
+	return p.headerEntry
+}
+
+// ParsedHeader returns the parsed fields of the header entry (empty if the file has none)
+func (p *Parser) ParsedHeader() *model.Header {
+	if p.headerEntry == nil {
+		return &model.Header{}
+	}
+	return model.ParseHeader(p.headerEntry.MsgStr)
+}
+
+// SplitHeader returns the header lines before and after the header entry's raw
+// lines. Without a header entry, all header lines are returned as before.
+func (p *Parser) SplitHeader() (before, after []string) {
+	if p.headerEntry == nil {
+		return p.header, nil
+	}
+	end := p.headerEntryIndex + len(p.headerEntry.RawLines)
+	return p.header[:p.headerEntryIndex], p.header[end:]
+}
+
+// sourceLine is a decoded input line with its position in the file
+type sourceLine struct {
+	text       string
+	number     int
+	start, end int64 // Byte offsets of the line and just past its terminator
+}
+
+// readLine returns the next input line decoded to UTF-8, or false at end of
+// input, and records its line number in line
+func (p *Parser) readLine() (sourceLine, bool) {
+	var src sourceLine
+	if n := len(p.pending); n > 0 {
+		src = p.pending[n-1]
+		p.pending = p.pending[:n-1]
+	} else {
+		start := p.lines.Offset()
+		text, ok := p.lines.ReadLine()
+		if !ok {
+			return sourceLine{}, false
+		}
+		if p.decoder != nil {
+			decoded, err := p.decoder.String(text)
+			if err != nil && p.err == nil {
+				p.err = fmt.Errorf("line %d: failed to decode %s: %w", p.nextLine, p.charset, err)
+			}
+			text = decoded
+		}
+		src = sourceLine{text: text, number: p.nextLine, start: start, end: p.lines.Offset()}
+		p.nextLine++
+	}
+	p.line = src.number
+	return src, true
+}
+
+// unreadLine pushes a line back so the next readLine returns it again.
+// Several lines can be pushed back; they are returned in reverse order.
+func (p *Parser) unreadLine(src sourceLine) {
+	p.pending = append(p.pending, src)
+}
+
+// field identifies which keyword a continuation line belongs to
+type field int
+
+const (
+	fieldNone field = iota
+	fieldMsgCtxt
+	fieldMsgID
+	fieldMsgIDPlural
+	fieldMsgStr
+	fieldMsgStrPlural
+)
+
 // Next returns the next entry from the .po file, or nil when done
 func (p *Parser) Next() *model.MsgEntry {
 	if p.err != nil {
@@ -36,117 +171,481 @@ func (p *Parser) Next() *model.MsgEntry {
 	}
 
 	var entry model.MsgEntry
+	var msgctxtLines []string
 	var msgidLines []string
+	var msgidPluralLines []string
 	var msgstrLines []string
-	var rawLines []string // Capture original lines
-	inMsgID := false
-	inMsgStr := false
+	var msgstrPluralLines [][]string // One slice of lines per msgstr[N]
+	var rawLines []string            // Capture original lines
+	current := fieldNone
+	previous := fieldNone // Field of the last "#|" previous-string line
+	pluralIndex := 0
+	startLine, endLine := 0, 0       // Lines of the entry's first and last raw lines
+	var startOffset, endOffset int64 // Byte offsets of the entry's raw lines
 
-	for p.scanner.Scan() {
-		line := p.scanner.Text()
+	// build assembles the accumulated lines into the entry
+	build := func() *model.MsgEntry {
+		entry.MsgCtxt = strings.Join(msgctxtLines, "")
+		entry.MsgID = strings.Join(msgidLines, "")
+		entry.MsgIDPlural = strings.Join(msgidPluralLines, "")
+		entry.MsgStr = strings.Join(msgstrLines, "")
+		for _, lines := range msgstrPluralLines {
+			entry.MsgStrPlural = append(entry.MsgStrPlural, strings.Join(lines, ""))
+		}
+		entry.RawLines = rawLines
+		entry.Position = &model.Position{
+			File:        p.filename,
+			Language:    p.entryLanguage(),
+			StartLine:   startLine,
+			EndLine:     endLine,
+			StartOffset: startOffset,
+			EndOffset:   endOffset,
+		}
+		return &entry
+	}
+
+	// finish completes the current entry, or stores header lines and resets
+	// state if no entry with a msgid has been read yet. separator holds the
+	// blank line that ended the entry, if any.
+	finish := func(separator []string) *model.MsgEntry {
+		if strings.Join(msgidLines, "") != "" {
+			// We have a complete entry
+			p.headerParsed = true
+			result := build()
+			p.checkEntry(result, startLine, msgstrLines != nil || msgstrPluralLines != nil)
+			if separator != nil {
+				result.Trailer = append(separator, p.readTrailer()...)
+			}
+			return result
+		}
+		// Only the header entry may have an empty msgid
+		if msgidLines != nil && !entry.Obsolete && (p.headerParsed || p.headerEntry != nil) {
+			p.syntaxError(startLine, 1, "empty msgid is reserved for the header entry")
+		}
+		// Capture header lines (before first entry)
+		if !p.headerParsed && (len(rawLines) > 0 || separator != nil) {
+			// Keep the header entry (msgid "") for structured access
+			if msgidLines != nil && msgctxtLines == nil && !entry.Obsolete && p.headerEntry == nil {
+				headerEntry := *build()
+				p.headerEntry = &headerEntry
+				p.headerLanguage = model.ParseHeader(headerEntry.MsgStr).Language()
+				p.headerEntryIndex = len(p.header)
+			}
+			p.header = append(p.header, rawLines...)
+			p.header = append(p.header, separator...) // Include the empty line
+		}
+		// Reset state if we hit empty line without msgid
+		entry = model.MsgEntry{}
+		msgctxtLines, msgidLines, msgidPluralLines, msgstrLines, msgstrPluralLines = nil, nil, nil, nil, nil
+		current = fieldNone
+		previous = fieldNone
+		rawLines = []string{}
+		return nil
+	}
+
+	for {
+		src, ok := p.readLine()
+		if !ok {
+			break
+		}
+		line := src.text
 		trimmed := strings.TrimSpace(line)
 
+		// A comment or a new msgctxt/msgid after msgstr starts the next entry,
+		// even when entries are not separated by an empty line
+		if (current == fieldMsgStr || current == fieldMsgStrPlural) && startsEntry(trimmed) {
+			p.unreadLine(src)
+			if result := finish(nil); result != nil {
+				return result
+			}
+			continue
+		}
+
 		// Skip empty lines between entries
 		if trimmed == "" {
-			if entry.MsgID != "" {
-				// We have a complete entry
-				entry.MsgStr = strings.Join(msgstrLines, "")
-				entry.RawLines = rawLines
-				p.headerParsed = true
-				return &entry
+			if result := finish([]string{line}); result != nil {
+				return result
 			}
-			// Capture header lines (before first entry)
-			if !p.headerParsed && len(rawLines) > 0 {
-				p.header = append(p.header, rawLines...)
-				p.header = append(p.header, "") // Include the empty line
-			}
-			// Reset state if we hit empty line without msgid
-			inMsgID = false
-			inMsgStr = false
-			rawLines = []string{}
 			continue
 		}
 
 		// Store raw line
+		if len(rawLines) == 0 {
+			startLine, startOffset = src.number, src.start
+		}
+		endLine, endOffset = src.number, src.end
 		rawLines = append(rawLines, line)
 
+		// 1-based column of trimmed within line, for syntax errors
+		column := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+
+		// Obsolete entries ("#~ msgid ...") are parsed like regular keyword lines
+		if isObsoleteLine(trimmed) {
+			entry.Obsolete = true
+			rest := trimmed[2:]
+			trimmed = strings.TrimSpace(rest)
+			column += 2 + len(rest) - len(strings.TrimLeft(rest, " \t"))
+		}
+
 		// Handle comments
 		if strings.HasPrefix(trimmed, "#:") {
 			// Reference comment
 			ref := strings.TrimSpace(trimmed[2:])
 			entry.References = append(entry.References, ref)
 			continue
+		} else if strings.HasPrefix(trimmed, "#,") {
+			// Flags comment
+			entry.Flags = append(entry.Flags, ParseFlags(trimmed[2:])...)
+			continue
+		} else if strings.HasPrefix(trimmed, "#.") {
+			// Extracted (developer) comment
+			comment := strings.TrimSpace(trimmed[2:])
+			entry.ExtractedComments = append(entry.ExtractedComments, comment)
+			continue
+		} else if strings.HasPrefix(trimmed, "#|") || strings.HasPrefix(trimmed, "#~|") {
+			// Previous strings of a fuzzy entry (#| msgid "...")
+			value := strings.TrimSpace(trimmed[strings.Index(trimmed, "|")+1:])
+			previous = parsePrevious(&entry, value, previous)
+			continue
 		} else if strings.HasPrefix(trimmed, "#") {
-			// Other comments
+			// Translator comments
 			comment := strings.TrimSpace(trimmed[1:])
 			entry.Comments = append(entry.Comments, comment)
 			continue
 		}
 
+		// Handle msgctxt
+		if strings.HasPrefix(trimmed, "msgctxt ") {
+			p.checkString(column+7, trimmed[7:])
+			current = fieldMsgCtxt
+			msgctxtLines = []string{unquote(trimmed[8:])}
+			continue
+		}
+
+		// Handle msgid_plural (before msgid, which is its prefix)
+		if strings.HasPrefix(trimmed, "msgid_plural ") {
+			p.checkString(column+12, trimmed[12:])
+			if msgidLines == nil {
+				p.syntaxError(p.line, column, "msgid_plural without msgid")
+			}
+			current = fieldMsgIDPlural
+			msgidPluralLines = []string{unquote(trimmed[13:])}
+			continue
+		}
+
 		// Handle msgid
 		if strings.HasPrefix(trimmed, "msgid ") {
-			inMsgID = true
-			inMsgStr = false
+			p.checkString(column+5, trimmed[5:])
+			if msgidLines != nil {
+				p.syntaxError(p.line, column, "msgid without msgstr for the previous msgid")
+			}
+			current = fieldMsgID
 			msgidLines = []string{unquote(trimmed[6:])}
 			continue
 		}
 
+		// Handle msgstr[N]
+		if strings.HasPrefix(trimmed, "msgstr[") {
+			index, value, ok := parsePluralIndex(trimmed[7:])
+			if !ok {
+				p.syntaxError(p.line, column, "invalid plural index")
+				continue
+			}
+			p.checkString(column+len(trimmed)-len(value), value)
+			if msgidLines == nil {
+				p.syntaxError(p.line, column, "msgstr without msgid")
+			} else if msgidPluralLines == nil {
+				p.syntaxError(p.line, column, "msgstr[%d] without msgid_plural", index)
+			}
+			for len(msgstrPluralLines) <= index {
+				msgstrPluralLines = append(msgstrPluralLines, nil)
+			}
+			current = fieldMsgStrPlural
+			pluralIndex = index
+			msgstrPluralLines[index] = []string{unquote(value)}
+			continue
+		}
+
 		// Handle msgstr
 		if strings.HasPrefix(trimmed, "msgstr ") {
-			// Save accumulated msgid
-			entry.MsgID = strings.Join(msgidLines, "")
-			inMsgID = false
-			inMsgStr = true
+			p.checkString(column+6, trimmed[6:])
+			if msgidLines == nil {
+				p.syntaxError(p.line, column, "msgstr without msgid")
+			} else if msgidPluralLines != nil {
+				p.syntaxError(p.line, column, "msgstr in plural entry, expected msgstr[N]")
+			}
+			current = fieldMsgStr
 			msgstrLines = []string{unquote(trimmed[7:])}
 			continue
 		}
 
 		// Handle continuation lines (quoted strings on their own lines)
+		if strings.HasPrefix(trimmed, "\"") && current == fieldNone {
+			p.syntaxError(p.line, column, "continuation line outside of any field")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\"") {
+			p.checkString(column, trimmed)
+		}
 		if strings.HasPrefix(trimmed, "\"") && strings.HasSuffix(trimmed, "\"") {
-			if inMsgID {
+			switch current {
+			case fieldMsgCtxt:
+				msgctxtLines = append(msgctxtLines, unquote(trimmed))
+			case fieldMsgID:
 				msgidLines = append(msgidLines, unquote(trimmed))
-			} else if inMsgStr {
+			case fieldMsgIDPlural:
+				msgidPluralLines = append(msgidPluralLines, unquote(trimmed))
+			case fieldMsgStr:
 				msgstrLines = append(msgstrLines, unquote(trimmed))
+			case fieldMsgStrPlural:
+				msgstrPluralLines[pluralIndex] = append(msgstrPluralLines[pluralIndex], unquote(trimmed))
 			}
 			continue
 		}
+
+		if !strings.HasPrefix(trimmed, "\"") {
+			p.syntaxError(p.line, column, "unexpected line: %s", trimmed)
+		}
 	}
 
 	// Handle last entry in file (no trailing empty line)
-	if len(msgidLines) > 0 {
-		entry.MsgID = strings.Join(msgidLines, "")
-		entry.MsgStr = strings.Join(msgstrLines, "")
-		entry.RawLines = rawLines
-		return &entry
+	if result := finish(nil); result != nil {
+		return result
 	}
 
-	// Check for scanner errors
-	if err := p.scanner.Err(); err != nil {
+	// Check for read errors
+	if err := p.lines.Err(); err != nil {
 		p.err = err
+	} else if p.err == nil && len(p.syntaxErrors) > 0 {
+		// In strict mode, report the first syntax error once the input is
+		// consumed, unless an error such as a failed decode came first
+		p.err = p.syntaxErrors[0]
 	}
 
 	return nil
 }
 
-// Err returns any error encountered during parsing
+// readTrailer reads the blank lines and stray comment blocks (comments followed
+// by a blank line or the end of input rather than an entry) after an entry, so
+// that writers can reproduce the file byte for byte
+func (p *Parser) readTrailer() []string {
+	var trailer []string
+	for {
+		src, ok := p.readLine()
+		if !ok {
+			return trailer
+		}
+		trimmed := strings.TrimSpace(src.text)
+		if trimmed == "" {
+			trailer = append(trailer, src.text)
+			continue
+		}
+		if !isPlainComment(trimmed) {
+			p.unreadLine(src)
+			return trailer
+		}
+
+		// Look ahead to see whether the comment block belongs to the next entry
+		block := []sourceLine{src}
+		for {
+			next, ok := p.readLine()
+			if !ok {
+				return appendText(trailer, block)
+			}
+			trimmed := strings.TrimSpace(next.text)
+			if trimmed == "" {
+				trailer = appendText(trailer, block)
+				trailer = append(trailer, next.text)
+				break
+			}
+			if !isPlainComment(trimmed) {
+				// Part of the next entry: push everything back in reading order
+				p.unreadLine(next)
+				for i := len(block) - 1; i >= 0; i-- {
+					p.unreadLine(block[i])
+				}
+				return trailer
+			}
+			block = append(block, next)
+		}
+	}
+}
+
+// appendText appends the text of each source line to lines
+func appendText(lines []string, block []sourceLine) []string {
+	for _, src := range block {
+		lines = append(lines, src.text)
+	}
+	return lines
+}
+
+// isPlainComment reports whether a line is a comment rather than part of an
+// entry body; obsolete "#~" keyword lines are part of an entry
+func isPlainComment(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "#") && !isObsoleteLine(trimmed)
+}
+
+// isObsoleteLine reports whether a line belongs to an obsolete entry ("#~"),
+// excluding previous-string comments of obsolete entries ("#~|")
+func isObsoleteLine(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "#~") && !strings.HasPrefix(trimmed, "#~|")
+}
+
+// startsEntry reports whether a line can only begin a new entry: a comment
+// or a msgctxt/msgid keyword (including their obsolete "#~" forms)
+func startsEntry(trimmed string) bool {
+	if isObsoleteLine(trimmed) {
+		trimmed = strings.TrimSpace(trimmed[2:])
+	} else if strings.HasPrefix(trimmed, "#") {
+		return true
+	}
+	return strings.HasPrefix(trimmed, "msgctxt ") || strings.HasPrefix(trimmed, "msgid ")
+}
+
+// parsePrevious applies one "#|" line to the previous-string fields of entry
+// and returns the field that following continuation lines belong to
+func parsePrevious(entry *model.MsgEntry, s string, current field) field {
+	switch {
+	case strings.HasPrefix(s, "msgctxt "):
+		entry.PreviousMsgCtxt = unquote(s[8:])
+		return fieldMsgCtxt
+	case strings.HasPrefix(s, "msgid_plural "):
+		entry.PreviousMsgIDPlural = unquote(s[13:])
+		return fieldMsgIDPlural
+	case strings.HasPrefix(s, "msgid "):
+		entry.PreviousMsgID = unquote(s[6:])
+		return fieldMsgID
+	case strings.HasPrefix(s, "\""):
+		switch current {
+		case fieldMsgCtxt:
+			entry.PreviousMsgCtxt += unquote(s)
+		case fieldMsgID:
+			entry.PreviousMsgID += unquote(s)
+		case fieldMsgIDPlural:
+			entry.PreviousMsgIDPlural += unquote(s)
+		}
+	}
+	return current
+}
+
+// ParseFlags splits the text of a "#," comment into individual flags
+func ParseFlags(s string) []string {
+	var flags []string
+	for _, flag := range strings.Split(s, ",") {
+		if flag = strings.TrimSpace(flag); flag != "" {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// parsePluralIndex parses the "N] value" remainder of a msgstr[N] line
+func parsePluralIndex(s string) (int, string, bool) {
+	end := strings.Index(s, "]")
+	if end < 0 {
+		return 0, "", false
+	}
+	index, err := strconv.Atoi(s[:end])
+	if err != nil || index < 0 {
+		return 0, "", false
+	}
+	return index, s[end+1:], true
+}
+
+// Err returns any error encountered during parsing.
+// In strict mode this is the first *SyntaxError; Errors returns all of them.
 func (p *Parser) Err() error {
 	return p.err
 }
 
-// unquote removes surrounding quotes and handles escape sequences
+// unquote removes surrounding quotes and decodes escape sequences
 func unquote(s string) string {
 	s = strings.TrimSpace(s)
 	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
 		s = s[1 : len(s)-1]
 	}
+	return Unescape(s)
+}
 
-	// Handle common escape sequences
-	s = strings.ReplaceAll(s, "\\n", "\n")
-	s = strings.ReplaceAll(s, "\\t", "\t")
-	s = strings.ReplaceAll(s, "\\\"", "\"")
-	s = strings.ReplaceAll(s, "\\\\", "\\")
+// Unescape decodes the C escape sequences gettext allows in .po strings in a
+// single pass: \a \b \f \n \r \t \v \\ \" \' \?, octal (\033) and hex (\x1b).
+// Unknown escapes are kept as-is.
+func Unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
 
-	return s
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case '\\', '"', '\'', '?':
+			sb.WriteByte(c)
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// Up to three octal digits
+			value := 0
+			end := i
+			for end < len(s) && end < i+3 && s[end] >= '0' && s[end] <= '7' {
+				value = value*8 + int(s[end]-'0')
+				end++
+			}
+			sb.WriteByte(byte(value))
+			i = end - 1
+		case 'x':
+			// Hex digits, if any; "\x" alone is kept as-is
+			value := 0
+			end := i + 1
+			for end < len(s) && isHexDigit(s[end]) {
+				value = value*16 + hexValue(s[end])
+				end++
+			}
+			if end == i+1 {
+				sb.WriteString("\\x")
+				continue
+			}
+			sb.WriteByte(byte(value))
+			i = end - 1
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// isHexDigit reports whether c is a hexadecimal digit
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// hexValue returns the value of the hexadecimal digit c
+func hexValue(c byte) int {
+	switch {
+	case c >= 'a':
+		return int(c-'a') + 10
+	case c >= 'A':
+		return int(c-'A') + 10
+	}
+	return int(c - '0')
 }
 
 // ParseAll reads all entries from a .po file (convenience method for testing)
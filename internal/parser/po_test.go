@@ -3,6 +3,8 @@ package parser
 import (
 	"strings"
 	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
 )
 
 func TestParser_SimplePair(t *testing.T) {
@@ -227,3 +229,388 @@ msgstr "Tre"
 		t.Errorf("expected third msgid 'Three', got '%s'", entries[2].MsgID)
 	}
 }
+
+func TestParser_MsgCtxt(t *testing.T) {
+	input := `msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgctxt ""
+"state"
+msgid "Open"
+msgstr "Öppen"
+
+msgid "Open"
+msgstr "Öppet"
+`
+	entries, err := ParseAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	expected := []struct{ ctxt, msgstr string }{
+		{"button", "Öppna"},
+		{"state", "Öppen"},
+		{"", "Öppet"},
+	}
+
+	for i, exp := range expected {
+		if entries[i].MsgID != "Open" {
+			t.Errorf("entry %d: expected msgid 'Open', got '%s'", i, entries[i].MsgID)
+		}
+		if entries[i].MsgCtxt != exp.ctxt {
+			t.Errorf("entry %d: expected msgctxt '%s', got '%s'", i, exp.ctxt, entries[i].MsgCtxt)
+		}
+		if entries[i].MsgStr != exp.msgstr {
+			t.Errorf("entry %d: expected msgstr '%s', got '%s'", i, exp.msgstr, entries[i].MsgStr)
+		}
+	}
+
+	if entries[0].Key() == entries[2].Key() {
+		t.Error("expected entries with different contexts to have distinct keys")
+	}
+}
+
+func TestParser_PluralForms(t *testing.T) {
+	input := `msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] ""
+"%d filer"
+
+msgid "%d day"
+msgid_plural "%d days"
+msgstr[0] "%d dag"
+msgstr[1] ""
+`
+	entries, err := ParseAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.MsgID != "%d file" || first.MsgIDPlural != "%d files" {
+		t.Errorf("unexpected msgid/msgid_plural: '%s' / '%s'", first.MsgID, first.MsgIDPlural)
+	}
+	if len(first.MsgStrPlural) != 2 || first.MsgStrPlural[0] != "%d fil" || first.MsgStrPlural[1] != "%d filer" {
+		t.Errorf("unexpected plural forms: %q", first.MsgStrPlural)
+	}
+	if first.IsEmpty() {
+		t.Error("expected fully translated plural entry to not be empty")
+	}
+
+	if !entries[1].IsEmpty() {
+		t.Error("expected plural entry with an empty slot to be empty")
+	}
+}
+
+func TestParser_Flags(t *testing.T) {
+	input := `# Translator note
+#, fuzzy, elixir-format
+msgid "Welcome"
+msgstr "Välkommen"
+
+`
+	parser := NewParser(strings.NewReader(input))
+	entry := parser.Next()
+
+	if entry == nil {
+		t.Fatal("expected entry, got nil")
+	}
+
+	if len(entry.Flags) != 2 || entry.Flags[0] != "fuzzy" || entry.Flags[1] != "elixir-format" {
+		t.Errorf("expected flags [fuzzy elixir-format], got %q", entry.Flags)
+	}
+
+	if !entry.IsFuzzy() {
+		t.Error("expected entry.IsFuzzy() to be true")
+	}
+
+	if len(entry.Comments) != 1 || entry.Comments[0] != "Translator note" {
+		t.Errorf("expected flags to be kept out of comments, got %q", entry.Comments)
+	}
+}
+
+func TestParser_ObsoleteEntries(t *testing.T) {
+	input := `msgid "Welcome"
+msgstr "Välkommen"
+
+# Old translator note
+#~ msgid "Sign In"
+#~ msgstr ""
+#~ "Logga in"
+
+#~ msgctxt "button"
+#~ msgid "Open"
+#~ msgstr "Öppna"
+msgid "Profile"
+msgstr "Profil"
+`
+	entries, err := ParseAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+
+	if entries[0].Obsolete {
+		t.Error("expected first entry to not be obsolete")
+	}
+
+	signIn := entries[1]
+	if !signIn.Obsolete || signIn.MsgID != "Sign In" || signIn.MsgStr != "Logga in" {
+		t.Errorf("unexpected obsolete entry: %+v", signIn)
+	}
+	if len(signIn.Comments) != 1 || signIn.Comments[0] != "Old translator note" {
+		t.Errorf("expected translator comment on obsolete entry, got %q", signIn.Comments)
+	}
+
+	open := entries[2]
+	if !open.Obsolete || open.MsgCtxt != "button" || open.MsgID != "Open" {
+		t.Errorf("unexpected obsolete entry with context: %+v", open)
+	}
+
+	// An entry following obsolete lines without an empty line must not inherit them
+	profile := entries[3]
+	if profile.Obsolete || profile.MsgID != "Profile" || len(profile.Comments) != 0 {
+		t.Errorf("unexpected entry after obsolete entries: %+v", profile)
+	}
+}
+
+func TestParser_CommentKinds(t *testing.T) {
+	input := `# Translator note
+#. Shown on the login page
+#: lib/web/live/page.ex:24
+#, fuzzy
+#| msgctxt "old"
+#| msgid "Sign "
+#| "in"
+msgid "Sign In"
+msgstr "Logga in"
+
+`
+	parser := NewParser(strings.NewReader(input))
+	entry := parser.Next()
+
+	if entry == nil {
+		t.Fatal("expected entry, got nil")
+	}
+
+	if len(entry.Comments) != 1 || entry.Comments[0] != "Translator note" {
+		t.Errorf("expected translator comment, got %q", entry.Comments)
+	}
+	if len(entry.ExtractedComments) != 1 || entry.ExtractedComments[0] != "Shown on the login page" {
+		t.Errorf("expected extracted comment, got %q", entry.ExtractedComments)
+	}
+	if len(entry.References) != 1 || entry.References[0] != "lib/web/live/page.ex:24" {
+		t.Errorf("expected reference, got %q", entry.References)
+	}
+	if len(entry.Flags) != 1 || entry.Flags[0] != "fuzzy" {
+		t.Errorf("expected fuzzy flag, got %q", entry.Flags)
+	}
+	if entry.PreviousMsgCtxt != "old" {
+		t.Errorf("expected previous msgctxt 'old', got '%s'", entry.PreviousMsgCtxt)
+	}
+	if entry.PreviousMsgID != "Sign in" {
+		t.Errorf("expected previous msgid 'Sign in', got '%s'", entry.PreviousMsgID)
+	}
+}
+
+func TestParser_Latin1Charset(t *testing.T) {
+	input := "msgid \"\"\n" +
+		"msgstr \"\"\n" +
+		"\"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n" +
+		"\n" +
+		"msgid \"Welcome\"\n" +
+		"msgstr \"V\xe4lkommen\"\n"
+
+	parser := NewParser(strings.NewReader(input))
+	entry := parser.Next()
+
+	if entry == nil {
+		t.Fatalf("expected entry, got nil (err: %v)", parser.Err())
+	}
+	if parser.Charset() != "ISO-8859-1" {
+		t.Errorf("expected charset 'ISO-8859-1', got '%s'", parser.Charset())
+	}
+	if entry.MsgStr != "Välkommen" {
+		t.Errorf("expected msgstr decoded to UTF-8, got %q", entry.MsgStr)
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`plain`, "plain"},
+		{`a\nb`, "a\nb"},
+		{`\\n`, `\n`},
+		{`\\\n`, "\\\n"},
+		{`\r\n`, "\r\n"},
+		{`\a\b\f\t\v`, "\a\b\f\t\v"},
+		{`\"quoted\"`, `"quoted"`},
+		{`it\'s \?`, "it's ?"},
+		{`\033[0m`, "\x1b[0m"},
+		{`\0`, "\x00"},
+		{`\1234`, "S4"},
+		{`\x1b[0m`, "\x1b[0m"},
+		{`\x41\x4a`, "AJ"},
+		{`\x`, `\x`},
+		{`\q`, `\q`},
+		{`trailing\`, `trailing\`},
+	}
+
+	for _, tt := range tests {
+		if got := Unescape(tt.input); got != tt.want {
+			t.Errorf("Unescape(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParser_MultiMegabyteLines(t *testing.T) {
+	// Far beyond bufio.Scanner's 64 KiB default token size
+	longID := strings.Repeat("Terms and conditions. ", 150_000)
+	longStr := strings.Repeat("<path d=\"M0 0L10 10\"/>", 200_000)
+
+	input := "msgid \"Before\"\nmsgstr \"Före\"\n\n" +
+		"msgid \"" + longID + "\"\n" +
+		"msgstr \"" + strings.ReplaceAll(longStr, "\"", "\\\"") + "\"\n\n" +
+		"msgid \"After\"\nmsgstr \"Efter\"\n"
+
+	entries, err := ParseAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[1].MsgID != longID {
+		t.Errorf("long msgid not preserved (got %d bytes, want %d)", len(entries[1].MsgID), len(longID))
+	}
+	if entries[1].MsgStr != longStr {
+		t.Errorf("long msgstr not preserved (got %d bytes, want %d)", len(entries[1].MsgStr), len(longStr))
+	}
+	if entries[2].MsgID != "After" {
+		t.Errorf("expected entry after long lines to parse, got %q", entries[2].MsgID)
+	}
+}
+
+func TestParser_MultiMegabyteMultilineEntry(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("msgid \"Legal\"\nmsgstr \"\"\n")
+	line := strings.Repeat("x", 1<<20) // 1 MiB per continuation line
+	for i := 0; i < 4; i++ {
+		sb.WriteString("\"" + line + "\"\n")
+	}
+
+	entries, err := ParseAll(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(entries) != 1 || len(entries[0].MsgStr) != 4<<20 {
+		t.Fatalf("expected one 4 MiB msgstr, got %d entries", len(entries))
+	}
+}
+
+// readAll reads every entry from parser, failing the test on a parse error
+func readAll(t *testing.T, parser *Parser) []*model.MsgEntry {
+	t.Helper()
+	var entries []*model.MsgEntry
+	for entry := parser.Next(); entry != nil; entry = parser.Next() {
+		entries = append(entries, entry)
+	}
+	if err := parser.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return entries
+}
+
+func TestParser_Positions(t *testing.T) {
+	input := "msgid \"\"\n" +
+		"msgstr \"\"\n" +
+		"\"Language: sv\\n\"\n" +
+		"\n" +
+		"#: lib/page.ex:1\n" +
+		"msgid \"Welcome\"\n" +
+		"msgstr \"Välkommen\"\n" +
+		"\n" +
+		"# stray comment\n" +
+		"\n" +
+		"msgid \"Sign in\"\n" +
+		"msgstr \"\"\n" +
+		"msgid \"Goodbye\"\n" +
+		"msgstr \"Hej då\""
+
+	parser := NewParser(strings.NewReader(input))
+	parser.SetFilename("priv/gettext/sv/LC_MESSAGES/default.po")
+	entries := readAll(t, parser)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	expected := []struct{ startLine, endLine int }{{5, 7}, {11, 12}, {13, 14}}
+	for i, entry := range entries {
+		pos := entry.Position
+		if pos == nil {
+			t.Fatalf("entry %d has no position", i)
+		}
+		if pos.StartLine != expected[i].startLine || pos.EndLine != expected[i].endLine {
+			t.Errorf("entry %d: expected lines %d-%d, got %d-%d", i,
+				expected[i].startLine, expected[i].endLine, pos.StartLine, pos.EndLine)
+		}
+		if pos.File != "priv/gettext/sv/LC_MESSAGES/default.po" {
+			t.Errorf("entry %d: unexpected file %q", i, pos.File)
+		}
+		if pos.Language != "sv" {
+			t.Errorf("entry %d: expected language from header 'sv', got %q", i, pos.Language)
+		}
+
+		// The offsets span exactly the entry's own lines
+		raw := strings.Join(entry.RawLines, "\n")
+		if got := strings.TrimSuffix(input[pos.StartOffset:pos.EndOffset], "\n"); got != raw {
+			t.Errorf("entry %d: offsets %d-%d span %q, expected %q", i, pos.StartOffset, pos.EndOffset, got, raw)
+		}
+	}
+}
+
+func TestParser_PositionsAreFileBytes(t *testing.T) {
+	// "ä" is one byte in Latin-1 but two once decoded
+	input := "msgid \"\"\n" +
+		"msgstr \"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n" +
+		"\n" +
+		"msgid \"\xe4\"\n" +
+		"msgstr \"\xe4\xe4\"\n" +
+		"\n" +
+		"msgid \"b\"\n" +
+		"msgstr \"\"\n"
+
+	parser := NewParser(strings.NewReader(input))
+	parser.SetLanguage("de")
+	entries := readAll(t, parser)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	pos := entries[1].Position
+	if want := int64(strings.Index(input, "msgid \"b\"")); pos.StartOffset != want {
+		t.Errorf("expected start offset %d, got %d", want, pos.StartOffset)
+	}
+	if pos.EndOffset != int64(len(input)) {
+		t.Errorf("expected end offset %d, got %d", len(input), pos.EndOffset)
+	}
+	if pos.Language != "de" {
+		t.Errorf("expected language set on the parser 'de', got %q", pos.Language)
+	}
+}
@@ -1,28 +1,152 @@
 package parser
 
 import (
-	"bufio"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/xnilsson/poflow/internal/model"
 )
 
+// ContextDelimiter separates an optional msgctxt from the msgid in translation input
+const ContextDelimiter = "::"
+
+// pluralSuffix matches the "[N]" plural form index at the end of a msgid
+var pluralSuffix = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
+
 // Translation represents a single translation pair
 type Translation struct {
-	MsgID  string
-	MsgStr string
+	MsgCtxt      string
+	MsgID        string
+	MsgStr       string
+	MsgStrPlural map[int]string // msgstr[N] values, given as "msgid[N] = msgstr"
+	PluralLines  map[int]int    // Input line of each msgstr[N], for errors (0 if unknown)
 }
 
-// ParseTranslations parses translation input in the format: msgid = msgstr
-// Returns a map of msgid -> msgstr for fast lookups
-func ParseTranslations(r io.Reader) (map[string]string, error) {
-	translations := make(map[string]string)
-	scanner := bufio.NewScanner(r)
+// Key returns the lookup key identifying the entry this translation applies to
+func (t *Translation) Key() string {
+	return model.Key(t.MsgCtxt, t.MsgID)
+}
+
+// Apply writes the translation into entry, clearing its fuzzy flag and
+// previous (#|) strings. For plural entries each msgstr[N] form is set;
+// a plain "msgid = msgstr" line fills msgstr[0]. A fuzzy plural entry stays
+// fuzzy unless all its forms are given, as the others are not reviewed.
+// Plural forms do not apply
+// to an entry without msgid_plural: Apply leaves it unchanged and reports
+// false. A form index of nplurals (the catalog's Plural-Forms count, 0 if
+// unknown) or more is an error, and the entry is left unchanged; without
+// a count, the entry's own number of forms, and at least 2, is the limit.
+func (t *Translation) Apply(entry *model.MsgEntry, nplurals int) (bool, error) {
+	if !entry.IsPlural() && len(t.MsgStrPlural) > 0 {
+		return false, nil
+	}
+	if nplurals <= 0 {
+		nplurals = max(len(entry.MsgStrPlural), 2)
+	}
+	for index := range t.MsgStrPlural {
+		if index >= nplurals {
+			err := fmt.Errorf("plural form %d out of range for %s (nplurals=%d)", index, DisplayKey(t.Key()), nplurals)
+			if line := t.PluralLines[index]; line > 0 {
+				err = fmt.Errorf("line %d: %w", line, err)
+			}
+			return false, err
+		}
+	}
+
+	if t.complete(entry, nplurals) {
+		entry.RemoveFlag(model.FlagFuzzy)
+		entry.PreviousMsgCtxt = ""
+		entry.PreviousMsgID = ""
+		entry.PreviousMsgIDPlural = ""
+	}
+
+	if !entry.IsPlural() {
+		entry.MsgStr = t.MsgStr
+		return true, nil
+	}
+
+	forms := t.MsgStrPlural
+	if len(forms) == 0 {
+		forms = map[int]string{0: t.MsgStr}
+	}
+	for index, msgstr := range forms {
+		for len(entry.MsgStrPlural) <= index {
+			entry.MsgStrPlural = append(entry.MsgStrPlural, "")
+		}
+		entry.MsgStrPlural[index] = msgstr
+	}
+	return true, nil
+}
+
+// complete reports whether the translation gives every form of entry: its
+// msgstr, or msgstr[0] to msgstr[nplurals-1] for a plural entry
+func (t *Translation) complete(entry *model.MsgEntry, nplurals int) bool {
+	if !entry.IsPlural() {
+		return true
+	}
+	if len(t.MsgStrPlural) == 0 {
+		return nplurals == 1
+	}
+	for index := range nplurals {
+		if _, ok := t.MsgStrPlural[index]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge applies the translation for entry from translations, if there is one,
+// and removes it from the map, so that what remains afterwards is the
+// translations that matched no entry. Obsolete entries are left alone, as are
+// translations that do not apply (see Translation.Apply); nplurals is passed
+// on to Apply. It reports whether entry was updated.
+func Merge(entry *model.MsgEntry, translations map[string]*Translation, nplurals int) (bool, error) {
+	translation, ok := translations[entry.Key()]
+	if !ok || entry.Obsolete {
+		return false, nil
+	}
+	applied, err := translation.Apply(entry, nplurals)
+	if err != nil || !applied {
+		return false, err
+	}
+	delete(translations, entry.Key())
+	return true, nil
+}
+
+// keyEscapes hide the characters escaped with a backslash in the msgctxt and
+// msgid of translation input (\:, \[ and \=) behind private-use runes while
+// the line is split, so that a msgid containing "::" or "=", or ending in
+// "[N]", can still be addressed
+var keyEscapes = strings.NewReplacer(`\:`, "\uE000", `\[`, "\uE001", `\=`, "\uE002")
+
+// keyUnescapes turns the hidden characters back into the ones they stand for
+var keyUnescapes = strings.NewReplacer("\uE000", ":", "\uE001", "[", "\uE002", "=")
+
+// keyRestores turns the hidden characters back into their escapes, for the
+// msgstr, which is taken as is
+var keyRestores = strings.NewReplacer("\uE000", `\:`, "\uE001", `\[`, "\uE002", `\=`)
+
+// ParseTranslationSet parses translation input in the format: msgid = msgstr
+// Entries with a context are written as: msgctxt::msgid = msgstr
+// Plural forms are written one per line as: msgid[N] = msgstr
+// In the msgctxt and msgid, \:, \[ and \= stand for a literal ":", "[" and
+// "=", e.g. "Ratio 1\:\:2 = Förhållande 1::2" or "Item\[1] = Objekt[1]".
+// Returns a map of model.Key(msgctxt, msgid) -> translation for fast lookups
+func ParseTranslationSet(r io.Reader) (map[string]*Translation, error) {
+	translations := make(map[string]*Translation)
+	lines := newLineReader(r)
 	lineNum := 0
 
-	for scanner.Scan() {
+	for {
+		text, ok := lines.ReadLine()
+		if !ok {
+			break
+		}
 		lineNum++
-		line := strings.TrimSpace(scanner.Text())
+		line := strings.TrimSpace(text)
 
 		// Skip empty lines and comments
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -30,24 +154,86 @@ func ParseTranslations(r io.Reader) (map[string]string, error) {
 		}
 
 		// Parse "msgid = msgstr" format
-		parts := strings.SplitN(line, "=", 2)
+		parts := strings.SplitN(keyEscapes.Replace(line), "=", 2)
 		if len(parts) != 2 {
 			return nil, fmt.Errorf("line %d: invalid format, expected 'msgid = msgstr', got: %s", lineNum, line)
 		}
 
-		msgid := strings.TrimSpace(parts[0])
-		msgstr := strings.TrimSpace(parts[1])
+		msgctxt, msgid := splitContext(strings.TrimSpace(parts[0]))
+		msgstr := keyRestores.Replace(strings.TrimSpace(parts[1]))
+
+		// Split off a plural form index ("msgid[N]")
+		pluralIndex := -1
+		if m := pluralSuffix.FindStringSubmatch(msgid); m != nil {
+			index, err := strconv.Atoi(m[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid plural index: %s", lineNum, m[2])
+			}
+			msgid = strings.TrimSpace(m[1])
+			pluralIndex = index
+		}
 
 		if msgid == "" {
 			return nil, fmt.Errorf("line %d: msgid cannot be empty", lineNum)
 		}
+		msgctxt, msgid = keyUnescapes.Replace(msgctxt), keyUnescapes.Replace(msgid)
+
+		key := model.Key(msgctxt, msgid)
+		t, ok := translations[key]
+		if !ok {
+			t = &Translation{MsgCtxt: msgctxt, MsgID: msgid}
+			translations[key] = t
+		}
 
-		translations[msgid] = msgstr
+		if pluralIndex >= 0 {
+			if t.MsgStrPlural == nil {
+				t.MsgStrPlural = make(map[int]string)
+				t.PluralLines = make(map[int]int)
+			}
+			t.MsgStrPlural[pluralIndex] = msgstr
+			t.PluralLines[pluralIndex] = lineNum
+		} else {
+			t.MsgStr = msgstr
+		}
 	}
 
-	if err := scanner.Err(); err != nil {
+	if err := lines.Err(); err != nil {
 		return nil, fmt.Errorf("error reading translations: %w", err)
 	}
 
 	return translations, nil
 }
+
+// ParseTranslations parses translation input in the format: msgid = msgstr
+// Entries with a context are written as: msgctxt::msgid = msgstr
+// Returns a map of model.Key(msgctxt, msgid) -> msgstr for fast lookups.
+// Plural form lines (msgid[N] = msgstr) are skipped; use ParseTranslationSet for those.
+func ParseTranslations(r io.Reader) (map[string]string, error) {
+	set, err := ParseTranslationSet(r)
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make(map[string]string, len(set))
+	for key, t := range set {
+		if len(t.MsgStrPlural) > 0 {
+			continue
+		}
+		translations[key] = t.MsgStr
+	}
+	return translations, nil
+}
+
+// splitContext splits "msgctxt::msgid" into its parts; input without a delimiter has no context
+func splitContext(s string) (string, string) {
+	parts := strings.SplitN(s, ContextDelimiter, 2)
+	if len(parts) != 2 {
+		return "", s
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// DisplayKey renders a model.Key in translation input syntax (msgctxt::msgid)
+func DisplayKey(key string) string {
+	return strings.Replace(key, model.ContextSeparator, ContextDelimiter, 1)
+}
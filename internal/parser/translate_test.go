@@ -3,6 +3,8 @@ package parser
 import (
 	"strings"
 	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
 )
 
 func TestParseTranslations_Simple(t *testing.T) {
@@ -160,3 +162,225 @@ Sign Out = Logga ut`
 		t.Errorf("unexpected translation for Sign Out: %q", translations["Sign Out"])
 	}
 }
+
+func TestParseTranslations_WithContext(t *testing.T) {
+	input := `button::Open = Öppna
+state :: Open = Öppen
+Open = Öppet`
+
+	translations, err := ParseTranslations(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		model.Key("button", "Open"): "Öppna",
+		model.Key("state", "Open"):  "Öppen",
+		"Open":                      "Öppet",
+	}
+
+	if len(translations) != len(expected) {
+		t.Fatalf("expected %d translations, got %d", len(expected), len(translations))
+	}
+
+	for key, expectedMsgstr := range expected {
+		if msgstr, ok := translations[key]; !ok {
+			t.Errorf("missing translation for %q", DisplayKey(key))
+		} else if msgstr != expectedMsgstr {
+			t.Errorf("for %q: expected %q, got %q", DisplayKey(key), expectedMsgstr, msgstr)
+		}
+	}
+}
+
+func TestParseTranslationSet_PluralForms(t *testing.T) {
+	input := `%d file[0] = %d fil
+%d file[1] = %d filer
+files::%d file[1] = %d filer i mappen`
+
+	translations, err := ParseTranslationSet(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(translations) != 2 {
+		t.Fatalf("expected 2 translations, got %d", len(translations))
+	}
+
+	plain := translations["%d file"]
+	if plain == nil {
+		t.Fatalf("missing translation for %q", "%d file")
+	}
+	if plain.MsgStrPlural[0] != "%d fil" || plain.MsgStrPlural[1] != "%d filer" {
+		t.Errorf("unexpected plural forms: %v", plain.MsgStrPlural)
+	}
+
+	entry := &model.MsgEntry{MsgID: "%d file", MsgIDPlural: "%d files"}
+	if _, err := plain.Apply(entry, 2); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(entry.MsgStrPlural) != 2 || entry.MsgStrPlural[1] != "%d filer" {
+		t.Errorf("unexpected plural forms after Apply: %q", entry.MsgStrPlural)
+	}
+
+	withContext := translations[model.Key("files", "%d file")]
+	if withContext == nil || withContext.MsgStrPlural[1] != "%d filer i mappen" {
+		t.Errorf("unexpected translation with context: %+v", withContext)
+	}
+}
+
+func TestTranslation_ApplyClearsFuzzy(t *testing.T) {
+	entry := &model.MsgEntry{MsgID: "Welcome", MsgStr: "Välkomna", Flags: []string{"fuzzy", "elixir-format"}}
+
+	translation := &Translation{MsgID: "Welcome", MsgStr: "Välkommen"}
+	if _, err := translation.Apply(entry, 0); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if entry.MsgStr != "Välkommen" {
+		t.Errorf("expected msgstr 'Välkommen', got %q", entry.MsgStr)
+	}
+	if entry.IsFuzzy() {
+		t.Error("expected fuzzy flag to be cleared")
+	}
+	if !entry.HasFlag("elixir-format") {
+		t.Error("expected other flags to be kept")
+	}
+}
+
+func TestParseTranslationSet_LongLine(t *testing.T) {
+	long := strings.Repeat("a", 1<<20)
+
+	translations, err := ParseTranslationSet(strings.NewReader("Legal = " + long + "\n"))
+	if err != nil {
+		t.Fatalf("ParseTranslationSet failed: %v", err)
+	}
+	if translations["Legal"] == nil || translations["Legal"].MsgStr != long {
+		t.Error("expected the 1 MiB translation to be parsed")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	translations := map[string]*Translation{
+		model.Key("", "Welcome"):   {MsgID: "Welcome", MsgStr: "Välkommen"},
+		model.Key("", "Goodbye"):   {MsgID: "Goodbye", MsgStr: "Hej då"},
+		model.Key("", "Not found"): {MsgID: "Not found", MsgStr: "Hittades inte"},
+	}
+
+	welcome := &model.MsgEntry{MsgID: "Welcome"}
+	if merged, _ := Merge(welcome, translations, 0); !merged || welcome.MsgStr != "Välkommen" {
+		t.Errorf("expected Welcome to be merged, got %q", welcome.MsgStr)
+	}
+
+	obsolete := &model.MsgEntry{MsgID: "Goodbye", Obsolete: true}
+	if merged, _ := Merge(obsolete, translations, 0); merged || obsolete.MsgStr != "" {
+		t.Error("expected obsolete entry to be left alone")
+	}
+
+	other := &model.MsgEntry{MsgID: "Other"}
+	if merged, _ := Merge(other, translations, 0); merged {
+		t.Error("expected entry without translation not to be merged")
+	}
+
+	// What remains is the translations that matched no entry
+	if len(translations) != 2 || translations[model.Key("", "Welcome")] != nil {
+		t.Errorf("expected only unmatched translations to remain, got %v", translations)
+	}
+}
+
+func TestTranslation_ApplyPluralFormToSingular(t *testing.T) {
+	translations, err := ParseTranslationSet(strings.NewReader("Hello[1] = foo\n"))
+	if err != nil {
+		t.Fatalf("ParseTranslationSet failed: %v", err)
+	}
+
+	entry := &model.MsgEntry{MsgID: "Hello", MsgStr: "Hej"}
+	if merged, _ := Merge(entry, translations, 0); merged {
+		t.Error("expected plural form not to apply to an entry without msgid_plural")
+	}
+	if entry.MsgStr != "Hej" {
+		t.Errorf("expected msgstr to be kept, got %q", entry.MsgStr)
+	}
+	if len(translations) != 1 {
+		t.Error("expected the translation to remain unmatched")
+	}
+}
+
+func TestParseTranslationSet_Escapes(t *testing.T) {
+	input := `Ratio 1\:\:2 = Förhållande 1::2
+Item\[1] = Objekt[1]
+a\=b = a\=b
+menu::Save\: all = Spara: alla
+`
+	translations, err := ParseTranslationSet(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseTranslationSet failed: %v", err)
+	}
+
+	expected := map[string]string{
+		model.Key("", "Ratio 1::2"):    "Förhållande 1::2",
+		model.Key("", "Item[1]"):       "Objekt[1]",
+		model.Key("", "a=b"):           `a\=b`,
+		model.Key("menu", "Save: all"): "Spara: alla",
+	}
+	if len(translations) != len(expected) {
+		t.Fatalf("expected %d translations, got %d", len(expected), len(translations))
+	}
+	for key, msgstr := range expected {
+		translation := translations[key]
+		if translation == nil {
+			t.Errorf("missing translation for %q", DisplayKey(key))
+			continue
+		}
+		if translation.MsgStr != msgstr || len(translation.MsgStrPlural) != 0 {
+			t.Errorf("for %q: expected %q, got %+v", DisplayKey(key), msgstr, translation)
+		}
+	}
+}
+
+func TestTranslation_ApplyPluralIndexOutOfRange(t *testing.T) {
+	translations, err := ParseTranslationSet(strings.NewReader("%d file[0] = %d fil\n%d file[1000000] = x\n"))
+	if err != nil {
+		t.Fatalf("ParseTranslationSet failed: %v", err)
+	}
+
+	entry := &model.MsgEntry{MsgID: "%d file", MsgIDPlural: "%d files", MsgStrPlural: []string{"", ""}}
+	merged, err := Merge(entry, translations, 2)
+	if merged || err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("expected an error for line 2, got %v", err)
+	}
+	if len(entry.MsgStrPlural) != 2 || entry.MsgStrPlural[0] != "" {
+		t.Errorf("expected the entry to be left unchanged, got %q", entry.MsgStrPlural)
+	}
+
+	// Without Plural-Forms, the entry's own forms (at least 2) are the limit
+	three := &Translation{MsgID: "%d file", MsgStrPlural: map[int]string{2: "x"}}
+	if _, err := three.Apply(entry, 0); err == nil {
+		t.Error("expected msgstr[2] to be rejected for an entry with 2 forms")
+	}
+	if _, err := three.Apply(entry, 3); err != nil || entry.MsgStrPlural[2] != "x" {
+		t.Errorf("expected msgstr[2] to apply with nplurals=3, got %v, %q", err, entry.MsgStrPlural)
+	}
+}
+
+func TestTranslation_ApplyPartialPluralKeepsFuzzy(t *testing.T) {
+	entry := &model.MsgEntry{
+		MsgID: "%d file", MsgIDPlural: "%d files", MsgStrPlural: []string{"%d fil", "%d filer"},
+		Flags: []string{"fuzzy"}, PreviousMsgID: "%d files",
+	}
+
+	partial := &Translation{MsgID: "%d file", MsgStrPlural: map[int]string{1: "%d filer!"}}
+	if _, err := partial.Apply(entry, 2); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if entry.MsgStrPlural[1] != "%d filer!" || !entry.IsFuzzy() || entry.PreviousMsgID == "" {
+		t.Errorf("expected the form set and the entry kept fuzzy, got %+v", entry)
+	}
+
+	full := &Translation{MsgID: "%d file", MsgStrPlural: map[int]string{0: "%d fil", 1: "%d filer"}}
+	if _, err := full.Apply(entry, 2); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if entry.IsFuzzy() || entry.PreviousMsgID != "" {
+		t.Errorf("expected all forms to clear the fuzzy flag, got %+v", entry)
+	}
+}
@@ -0,0 +1,151 @@
+// Package stats counts translation progress in catalogs
+package stats
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/xnilsson/poflow/internal/model"
+)
+
+// NoReference groups entries without #: references in ByFile
+const NoReference = "(no reference)"
+
+// Counts are the entries of a catalog by state, with the words in their msgids
+type Counts struct {
+	Total        int `json:"total"`
+	Translated   int `json:"translated"`
+	Fuzzy        int `json:"fuzzy"`
+	Untranslated int `json:"untranslated"`
+	Obsolete     int `json:"obsolete"`
+
+	Words             int `json:"words"`
+	TranslatedWords   int `json:"translated_words"`
+	FuzzyWords        int `json:"fuzzy_words"`
+	UntranslatedWords int `json:"untranslated_words"`
+}
+
+// Add counts an entry. Obsolete entries are only counted as obsolete. Fuzzy
+// entries count as fuzzy even if empty; plural entries with any empty form
+// count as untranslated.
+func (c *Counts) Add(entry *model.MsgEntry) {
+	if entry.Obsolete {
+		c.Obsolete++
+		return
+	}
+
+	words := Words(entry.MsgID)
+	c.Total++
+	c.Words += words
+	switch {
+	case entry.IsFuzzy():
+		c.Fuzzy++
+		c.FuzzyWords += words
+	case entry.IsEmpty():
+		c.Untranslated++
+		c.UntranslatedWords += words
+	default:
+		c.Translated++
+		c.TranslatedWords += words
+	}
+}
+
+// Merge adds the counts of other
+func (c *Counts) Merge(other *Counts) {
+	c.Total += other.Total
+	c.Translated += other.Translated
+	c.Fuzzy += other.Fuzzy
+	c.Untranslated += other.Untranslated
+	c.Obsolete += other.Obsolete
+	c.Words += other.Words
+	c.TranslatedWords += other.TranslatedWords
+	c.FuzzyWords += other.FuzzyWords
+	c.UntranslatedWords += other.UntranslatedWords
+}
+
+// Percent returns the share of entries translated, from 0 to 100. A catalog
+// without entries is fully translated.
+func (c *Counts) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return 100 * float64(c.Translated) / float64(c.Total)
+}
+
+// placeholder matches interpolations and format directives, which are not words
+var placeholder = regexp.MustCompile(`%\{[^}]*\}|\{\{[^}]*\}\}|%(\d+\$)?[-+ #0]*\d*(\.\d+)?[a-zA-Z@%]`)
+
+// Words returns the number of words in a source string, not counting
+// placeholders such as %{name} or %d, or lone punctuation
+func Words(s string) int {
+	words := 0
+	for _, field := range strings.Fields(placeholder.ReplaceAllString(s, " ")) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			words++
+		}
+	}
+	return words
+}
+
+// Count returns the counts of entries
+func Count(entries []*model.MsgEntry) *Counts {
+	counts := &Counts{}
+	for _, entry := range entries {
+		counts.Add(entry)
+	}
+	return counts
+}
+
+// ByFile returns the counts of entries by the source file of their #:
+// references. An entry referenced from several files counts for each;
+// entries without references count under NoReference, except obsolete ones.
+func ByFile(entries []*model.MsgEntry) map[string]*Counts {
+	files := make(map[string]*Counts)
+	for _, entry := range entries {
+		paths := ReferencePaths(entry)
+		if len(paths) == 0 {
+			if entry.Obsolete {
+				continue
+			}
+			paths = []string{NoReference}
+		}
+		for _, path := range paths {
+			if files[path] == nil {
+				files[path] = &Counts{}
+			}
+			files[path].Add(entry)
+		}
+	}
+	return files
+}
+
+// ReferencePaths returns the distinct file paths of an entry's #:
+// references, without line numbers
+func ReferencePaths(entry *model.MsgEntry) []string {
+	var paths []string
+	for _, line := range entry.References {
+		for _, ref := range strings.Fields(line) {
+			if i := strings.LastIndexByte(ref, ':'); i > 0 && isDigits(ref[i+1:]) {
+				ref = ref[:i]
+			}
+			if !slices.Contains(paths, ref) {
+				paths = append(paths, ref)
+			}
+		}
+	}
+	return paths
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
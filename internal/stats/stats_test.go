@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+const sample = `msgid ""
+msgstr ""
+"Language: sv\n"
+
+#: lib/page.ex:1
+msgid "Welcome back"
+msgstr "Välkommen tillbaka"
+
+#: lib/page.ex:2 lib/menu.ex:4
+#, fuzzy
+msgid "Sign in"
+msgstr "Logga in"
+
+#: lib/menu.ex:9
+msgid "Hello %{name}, you have %d messages"
+msgstr ""
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] ""
+
+#~ msgid "Gone"
+#~ msgstr "Borta"
+`
+
+func entries(t *testing.T) []*model.MsgEntry {
+	t.Helper()
+	all, err := parser.ParseAll(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	return all
+}
+
+func TestCount(t *testing.T) {
+	got := Count(entries(t))
+	want := &Counts{
+		Total: 4, Translated: 1, Fuzzy: 1, Untranslated: 2, Obsolete: 1,
+		Words: 9, TranslatedWords: 2, FuzzyWords: 2, UntranslatedWords: 5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Count = %+v, want %+v", got, want)
+	}
+	if got.Percent() != 25 {
+		t.Errorf("Percent = %v, want 25", got.Percent())
+	}
+	if (&Counts{}).Percent() != 100 {
+		t.Errorf("expected an empty catalog to be fully translated")
+	}
+}
+
+func TestByFile(t *testing.T) {
+	files := ByFile(entries(t))
+
+	want := map[string][3]int{ // total, translated, fuzzy
+		"lib/page.ex": {2, 1, 1},
+		"lib/menu.ex": {2, 0, 1},
+		NoReference:   {1, 0, 0},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got files %v, want %v", files, want)
+	}
+	for path, w := range want {
+		c := files[path]
+		if c == nil || c.Total != w[0] || c.Translated != w[1] || c.Fuzzy != w[2] {
+			t.Errorf("%s: got %+v, want total/translated/fuzzy %v", path, c, w)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"Sign in", 2},
+		{"Hello %{name}!", 1},
+		{"%d of %2$s files", 2},
+		{"Hi {{name}}, 100%% done", 3},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.s); got != tt.want {
+			t.Errorf("Words(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
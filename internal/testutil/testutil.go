@@ -0,0 +1,19 @@
+// Package testutil holds helpers shared by poflow's tests
+package testutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+// ReadCatalog parses a catalog from text, failing the test if it cannot
+func ReadCatalog(t testing.TB, text string) *po.Catalog {
+	t.Helper()
+	catalog, err := po.ReadCatalog(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ReadCatalog failed: %v", err)
+	}
+	return catalog
+}
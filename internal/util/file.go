@@ -0,0 +1,39 @@
+// Package util holds helpers shared by poflow's packages
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file at path with what write writes, through
+// a temp file renamed over it, so that readers never see a partial file.
+// An existing file keeps its permissions; a new one is created 0644.
+func WriteFileAtomic(path string, write func(io.Writer) error) error {
+	// Write to a temp file next to the target so the rename stays on one filesystem
+	tempFile, err := os.CreateTemp(filepath.Dir(path), ".poflow-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	tempFileName := tempFile.Name()
+	defer os.Remove(tempFileName)
+
+	if err := write(tempFile); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tempFileName, mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFileName, path)
+}
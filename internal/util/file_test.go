@@ -0,0 +1,41 @@
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	write := func(w io.Writer) error {
+		_, err := io.WriteString(w, "msgid \"\"\n")
+		return err
+	}
+
+	// A new file is readable by others, unlike os.CreateTemp's 0600
+	path := filepath.Join(dir, "default.pot")
+	if err := WriteFileAtomic(path, write); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("expected a new file with mode 0644, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	// An existing file keeps its permissions
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, write); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected the temp file to be gone, found %d files", len(entries))
+	}
+}
@@ -0,0 +1,260 @@
+package po
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xnilsson/poflow/internal/parser"
+	"github.com/xnilsson/poflow/internal/util"
+)
+
+// Catalog is a whole .po catalog held in memory. Entries can be looked up by
+// (msgctxt, msgid) and changed in place; use Add, Remove, Rename and
+// MarkObsolete for changes that affect lookup.
+type Catalog struct {
+	entries     []*Entry
+	index       map[string]*Entry // Live (non-obsolete) entries by key
+	prologue    []string          // Header lines before the header entry
+	headerEntry *Entry            // Header entry (msgid ""), if any
+	epilogue    []string          // Header lines after the header entry
+	header      *Header
+	headerText  string // Header as last read or written, to detect changes
+	charset     string // Charset the catalog was read in
+	binary      bool   // Read from a compiled .mo file
+}
+
+// NewCatalog creates an empty catalog. Set header fields with Header().Set;
+// a header entry is written once the header has fields.
+func NewCatalog() *Catalog {
+	return &Catalog{index: make(map[string]*Entry), header: &Header{}}
+}
+
+// ReadCatalog reads a whole catalog from r
+func ReadCatalog(r io.Reader) (*Catalog, error) {
+	return readCatalog(NewReader(r))
+}
+
+// ReadFile reads the catalog at path. Entry positions record the path.
+func ReadFile(path string) (*Catalog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := NewReader(file)
+	reader.SetFilename(path)
+	return readCatalog(reader)
+}
+
+// readCatalog reads every entry from reader into a new catalog
+func readCatalog(reader *Reader) (*Catalog, error) {
+	c := NewCatalog()
+	for {
+		entry := reader.Next()
+		if entry == nil {
+			break
+		}
+		c.entries = append(c.entries, entry)
+		c.indexEntry(entry)
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	c.prologue, c.epilogue = reader.splitHeader()
+	c.headerEntry = reader.HeaderEntry()
+	c.header = reader.Header()
+	c.headerText = c.header.String()
+	c.charset = reader.Charset()
+	c.binary = reader.IsMO()
+	return c, nil
+}
+
+// IsMO reports whether the catalog was read from a compiled .mo file. Such
+// catalogs have no comments or flags, and are written out as .po text.
+func (c *Catalog) IsMO() bool {
+	return c.binary
+}
+
+// indexEntry adds a live entry to the index; the first of duplicate entries wins
+func (c *Catalog) indexEntry(entry *Entry) {
+	if entry.Obsolete {
+		return
+	}
+	if _, ok := c.index[entry.Key()]; !ok {
+		c.index[entry.Key()] = entry
+	}
+}
+
+// Header returns the catalog's header fields. Changes are written back with the catalog.
+func (c *Catalog) Header() *Header {
+	return c.header
+}
+
+// HeaderEntry returns the header entry (msgid "") as read, or nil if the
+// catalog has none. Change header fields through Header instead.
+func (c *Catalog) HeaderEntry() *Entry {
+	return c.headerEntry
+}
+
+// Charset returns the charset the catalog is written in: the one declared in
+// the header, else the one it was read in
+func (c *Catalog) Charset() string {
+	if cs := c.header.Charset(); cs != "" {
+		return cs
+	}
+	return c.charset
+}
+
+// Entries returns all entries in file order, including obsolete ones.
+// The slice must not be modified; entries themselves may be.
+func (c *Catalog) Entries() []*Entry {
+	return c.entries
+}
+
+// Len returns the number of entries, including obsolete ones
+func (c *Catalog) Len() int {
+	return len(c.entries)
+}
+
+// Get returns the live entry with the given msgctxt and msgid ("" for no
+// context), or nil if there is none. Obsolete entries are not returned.
+func (c *Catalog) Get(msgctxt, msgid string) *Entry {
+	return c.index[Key(msgctxt, msgid)]
+}
+
+// Add appends an entry to the catalog. It fails if a live entry with the same
+// msgctxt and msgid already exists.
+func (c *Catalog) Add(entry *Entry) error {
+	if !entry.Obsolete && c.index[entry.Key()] != nil {
+		return fmt.Errorf("duplicate entry %q", parser.DisplayKey(entry.Key()))
+	}
+
+	// Separate the new entry from a last entry that ended the file without a blank line
+	if n := len(c.entries); n > 0 {
+		last := c.entries[n-1]
+		if len(last.RawLines) > 0 && (len(last.Trailer) == 0 || strings.TrimSpace(last.Trailer[len(last.Trailer)-1]) != "") {
+			last.Trailer = append(last.Trailer, "")
+		}
+	}
+	c.entries = append(c.entries, entry)
+	c.indexEntry(entry)
+	return nil
+}
+
+// Remove deletes the live entry with the given msgctxt and msgid. It reports
+// whether an entry was removed.
+func (c *Catalog) Remove(msgctxt, msgid string) bool {
+	entry := c.Get(msgctxt, msgid)
+	if entry == nil {
+		return false
+	}
+	c.removeEntries(func(e *Entry) bool { return e == entry })
+	return true
+}
+
+// Rename changes the msgid of the live entry with the given msgctxt and msgid,
+// keeping its translation
+func (c *Catalog) Rename(msgctxt, msgid, newMsgID string) error {
+	entry := c.Get(msgctxt, msgid)
+	if entry == nil {
+		return fmt.Errorf("entry %q not found", parser.DisplayKey(Key(msgctxt, msgid)))
+	}
+	if msgid == newMsgID {
+		return nil
+	}
+	if c.Get(msgctxt, newMsgID) != nil {
+		return fmt.Errorf("duplicate entry %q", parser.DisplayKey(Key(msgctxt, newMsgID)))
+	}
+
+	delete(c.index, entry.Key())
+	entry.MsgID = newMsgID
+	c.index[entry.Key()] = entry
+	return nil
+}
+
+// MarkObsolete turns the live entry with the given msgctxt and msgid into an
+// obsolete "#~" entry. It reports whether an entry was found.
+func (c *Catalog) MarkObsolete(msgctxt, msgid string) bool {
+	entry := c.Get(msgctxt, msgid)
+	if entry == nil {
+		return false
+	}
+	delete(c.index, entry.Key())
+	entry.Obsolete = true
+	return true
+}
+
+// PurgeObsolete removes all obsolete entries and returns how many were removed
+func (c *Catalog) PurgeObsolete() int {
+	return c.removeEntries(func(e *Entry) bool { return e.Obsolete })
+}
+
+// removeEntries removes the entries matching remove and returns how many were removed
+func (c *Catalog) removeEntries(remove func(*Entry) bool) int {
+	kept := c.entries[:0]
+	for _, entry := range c.entries {
+		if remove(entry) {
+			if c.index[entry.Key()] == entry {
+				delete(c.index, entry.Key())
+			}
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	removed := len(c.entries) - len(kept)
+	clear(c.entries[len(kept):])
+	c.entries = kept
+	return removed
+}
+
+// Write writes the catalog as .po text to w, encoded in its charset
+func (c *Catalog) Write(w io.Writer) error {
+	writer, err := NewWriter(w, c.Charset())
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteLines(c.prologue); err != nil {
+		return err
+	}
+	if headerEntry := c.currentHeaderEntry(); headerEntry != nil {
+		if err := writer.WriteEntry(headerEntry); err != nil {
+			return err
+		}
+	}
+	if err := writer.WriteLines(c.epilogue); err != nil {
+		return err
+	}
+	for _, entry := range c.entries {
+		if err := writer.WriteEntry(entry); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// WriteFile atomically replaces the file at path with the catalog. An
+// existing file keeps its permissions; a new one is created 0644.
+func (c *Catalog) WriteFile(path string) error {
+	return util.WriteFileAtomic(path, c.Write)
+}
+
+// currentHeaderEntry returns the header entry to write, updated if the header
+// fields changed since the catalog was read, or nil if there is none
+func (c *Catalog) currentHeaderEntry() *Entry {
+	text := c.header.String()
+	if text == c.headerText {
+		return c.headerEntry
+	}
+
+	if c.headerEntry == nil {
+		c.headerEntry = &Entry{}
+	}
+	c.headerEntry.MsgStr = text
+	c.headerText = text
+	return c.headerEntry
+}
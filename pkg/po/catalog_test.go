@@ -0,0 +1,262 @@
+package po
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xnilsson/poflow/internal/mo"
+)
+
+const sample = `# Swedish translations
+msgid ""
+msgstr ""
+"Language: sv\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: lib/page.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+msgctxt "state"
+msgid "Open"
+msgstr ""
+
+#~ msgid "Gone"
+#~ msgstr "Borta"
+`
+
+func writeString(t *testing.T, catalog *Catalog) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := catalog.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestCatalog_RoundTrip(t *testing.T) {
+	catalog := readString(t, sample)
+	if got := writeString(t, catalog); got != sample {
+		t.Errorf("unchanged catalog not written back byte for byte:\n%s", got)
+	}
+}
+
+func TestCatalog_Get(t *testing.T) {
+	catalog := readString(t, sample)
+
+	if catalog.Len() != 4 {
+		t.Errorf("expected 4 entries, got %d", catalog.Len())
+	}
+	if entry := catalog.Get("", "Welcome"); entry == nil || entry.MsgStr != "Välkommen" {
+		t.Errorf("unexpected entry for Welcome: %+v", entry)
+	}
+	if entry := catalog.Get("button", "Open"); entry == nil || entry.MsgStr != "Öppna" {
+		t.Errorf("unexpected entry for button::Open: %+v", entry)
+	}
+	if entry := catalog.Get("", "Open"); entry != nil {
+		t.Errorf("expected no entry for Open without context, got %+v", entry)
+	}
+	if entry := catalog.Get("", "Gone"); entry != nil {
+		t.Error("expected obsolete entries not to be returned")
+	}
+	if catalog.Header().Language() != "sv" {
+		t.Errorf("expected language 'sv', got %q", catalog.Header().Language())
+	}
+}
+
+func TestCatalog_Mutation(t *testing.T) {
+	catalog := readString(t, sample)
+
+	catalog.Get("state", "Open").MsgStr = "Öppen"
+	if err := catalog.Rename("", "Welcome", "Welcome!"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if err := catalog.Rename("button", "Open", "Welcome!"); err != nil {
+		t.Errorf("expected rename into another context to succeed: %v", err)
+	}
+	if err := catalog.Rename("", "Missing", "x"); err == nil {
+		t.Error("expected error renaming a missing entry")
+	}
+	if err := catalog.Add(&Entry{MsgID: "Welcome!"}); err == nil {
+		t.Error("expected error adding a duplicate entry")
+	}
+	if err := catalog.Add(&Entry{MsgID: "Goodbye", MsgStr: "Hej då"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if !catalog.MarkObsolete("button", "Welcome!") {
+		t.Error("expected MarkObsolete to find button::Welcome!")
+	}
+	if catalog.PurgeObsolete() != 2 {
+		t.Error("expected two obsolete entries to be purged")
+	}
+	if catalog.Remove("", "Missing") {
+		t.Error("expected Remove of a missing entry to report false")
+	}
+
+	expected := `# Swedish translations
+msgid ""
+msgstr ""
+"Language: sv\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: lib/page.ex:1
+msgid "Welcome!"
+msgstr "Välkommen"
+
+msgctxt "state"
+msgid "Open"
+msgstr "Öppen"
+
+msgid "Goodbye"
+msgstr "Hej då"
+
+`
+	if got := writeString(t, catalog); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCatalog_HeaderChange(t *testing.T) {
+	catalog := readString(t, sample)
+	catalog.Header().Set("Language", "sv_SE")
+
+	expected := strings.Replace(sample, `"Language: sv\n"`, `"Language: sv_SE\n"`, 1)
+	if got := writeString(t, catalog); got != expected {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+}
+
+func TestCatalog_New(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Header().Set("Language", "de")
+	catalog.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+	if err := catalog.Add(&Entry{MsgID: "Welcome", MsgStr: "Willkommen in Köln"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	got := writeString(t, catalog)
+	if !strings.Contains(got, "K\xf6ln") {
+		t.Errorf("expected output encoded in ISO-8859-1:\n%q", got)
+	}
+
+	// Read it back through the decoder
+	again := readString(t, got)
+	if entry := again.Get("", "Welcome"); entry == nil || entry.MsgStr != "Willkommen in Köln" {
+		t.Errorf("unexpected entry after round trip: %+v", entry)
+	}
+	if again.Header().Language() != "de" {
+		t.Errorf("expected header to round trip, got %q", again.Header().String())
+	}
+}
+
+func TestCatalog_WriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "default.po")
+	if err := os.WriteFile(path, []byte(sample), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if pos := catalog.Get("", "Welcome").Position; pos.File != path || pos.StartLine != 7 {
+		t.Errorf("unexpected position: %+v", pos)
+	}
+
+	catalog.Get("state", "Open").MsgStr = "Öppen"
+	if err := catalog.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `msgstr "Öppen"`) {
+		t.Errorf("change not written:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("expected file mode to be kept, got %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestReadCatalog_MO(t *testing.T) {
+	source := readString(t, sample)
+	messages, err := mo.Messages(source.HeaderEntry(), source.Entries(), mo.Options{})
+	if err != nil {
+		t.Fatalf("mo.Messages failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := mo.Write(&buf, messages); err != nil {
+		t.Fatalf("mo.Write failed: %v", err)
+	}
+
+	catalog, err := ReadCatalog(&buf)
+	if err != nil {
+		t.Fatalf("ReadCatalog failed: %v", err)
+	}
+	if !catalog.IsMO() {
+		t.Error("expected the .mo magic number to be detected")
+	}
+	if entry := catalog.Get("button", "Open"); entry == nil || entry.MsgStr != "Öppna" {
+		t.Errorf("unexpected entry for button::Open: %+v", entry)
+	}
+	if catalog.Get("state", "Open") != nil {
+		t.Error("expected untranslated entries not to be compiled")
+	}
+	if catalog.Header().Language() != "sv" {
+		t.Errorf("expected header from the .mo file, got %q", catalog.Header().String())
+	}
+
+	expected := `msgid ""
+msgstr ""
+"Language: sv\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgid "Welcome"
+msgstr "Välkommen"
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+`
+	if got := writeString(t, catalog); got != expected {
+		t.Errorf("unexpected decompiled catalog:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestReadCatalog_MOLatin1(t *testing.T) {
+	text := "msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n\n" +
+		"msgctxt \"button\"\nmsgid \"Open\"\nmsgstr \"\xd6ppna\"\n"
+	source := readString(t, text)
+	messages, err := mo.Messages(source.HeaderEntry(), source.Entries(), mo.Options{})
+	if err != nil {
+		t.Fatalf("mo.Messages failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := mo.Write(&buf, messages); err != nil {
+		t.Fatalf("mo.Write failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("\x00\xd6ppna\x00")) {
+		t.Errorf("expected the .mo strings encoded in ISO-8859-1:\n%q", buf.Bytes())
+	}
+
+	catalog, err := ReadCatalog(&buf)
+	if err != nil {
+		t.Fatalf("ReadCatalog failed: %v", err)
+	}
+	if entry := catalog.Get("button", "Open"); entry == nil || entry.MsgStr != "Öppna" {
+		t.Errorf("unexpected entry for button::Open: %+v", entry)
+	}
+	if got := writeString(t, catalog); !strings.Contains(got, "msgstr \"\xd6ppna\"") {
+		t.Errorf("expected the decompiled catalog in ISO-8859-1:\n%q", got)
+	}
+}
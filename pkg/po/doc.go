@@ -0,0 +1,18 @@
+// Package po reads and writes gettext .po catalogs.
+//
+// It is the library behind the poflow command line tool. Three types cover
+// the common jobs:
+//
+//   - Reader streams entries one at a time without loading the whole file.
+//   - Catalog holds a whole file in memory, with lookup by (msgctxt, msgid)
+//     and methods to add, remove and rename entries, or merge a template.
+//   - Writer encodes entries back to .po text in the catalog's charset.
+//
+// Entries read from a file keep their original lines. When written back,
+// untouched entries come out byte for byte and only the fields that changed
+// are re-encoded, so diffs stay small.
+//
+// Catalogs declaring a charset other than UTF-8 in their Content-Type header
+// are decoded on reading and encoded back on writing; entries in memory are
+// always UTF-8.
+package po
@@ -0,0 +1,86 @@
+package po_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xnilsson/poflow/pkg/po"
+)
+
+const catalog = `msgid ""
+msgstr ""
+"Language: sv\n"
+
+msgid "Welcome"
+msgstr "Välkommen"
+
+msgctxt "button"
+msgid "Open"
+msgstr ""
+`
+
+func ExampleReader() {
+	r := po.NewReader(strings.NewReader(catalog))
+	for entry := r.Next(); entry != nil; entry = r.Next() {
+		if entry.IsEmpty() {
+			fmt.Printf("untranslated at line %d: %s\n", entry.Position.StartLine, entry.MsgID)
+		}
+	}
+	if err := r.Err(); err != nil {
+		fmt.Println("error:", err)
+	}
+	// Output:
+	// untranslated at line 8: Open
+}
+
+func ExampleCatalog() {
+	c, err := po.ReadCatalog(strings.NewReader(catalog))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	c.Get("button", "Open").MsgStr = "Öppna"
+	c.Add(&po.Entry{MsgID: "Goodbye", MsgStr: "Hej då"})
+
+	if err := c.Write(os.Stdout); err != nil {
+		fmt.Println("error:", err)
+	}
+	// Output:
+	// msgid ""
+	// msgstr ""
+	// "Language: sv\n"
+	//
+	// msgid "Welcome"
+	// msgstr "Välkommen"
+	//
+	// msgctxt "button"
+	// msgid "Open"
+	// msgstr "Öppna"
+	//
+	// msgid "Goodbye"
+	// msgstr "Hej då"
+	//
+}
+
+func ExampleWriter() {
+	w, err := po.NewWriter(os.Stdout, "UTF-8")
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	w.WriteEntry(&po.Entry{
+		MsgID:        "%d file",
+		MsgIDPlural:  "%d files",
+		MsgStrPlural: []string{"%d fil", "%d filer"},
+		References:   []string{"lib/files.ex:12"},
+	})
+	w.Close()
+	// Output:
+	// #: lib/files.ex:12
+	// msgid "%d file"
+	// msgid_plural "%d files"
+	// msgstr[0] "%d fil"
+	// msgstr[1] "%d filer"
+}
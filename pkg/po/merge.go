@@ -0,0 +1,302 @@
+package po
+
+import (
+	"slices"
+	"strings"
+)
+
+// fuzzyThreshold is the similarity a changed msgid needs to an old one for
+// its translation to be reused, as in msgmerge
+const fuzzyThreshold = 0.6
+
+// MergeResult counts what Merge did to a catalog
+type MergeResult struct {
+	Matched  int // Entries still in the template
+	Added    int // New entries, untranslated
+	Fuzzy    int // Entries given the translation of a similar old msgid, or whose msgid_plural changed, marked fuzzy
+	Obsolete int // Entries no longer in the template, marked obsolete
+	Updated  int // Matched entries whose references, extracted comments or flags were refreshed
+
+	changed bool
+}
+
+// Changed reports whether Merge changed the catalog
+func (r *MergeResult) Changed() bool {
+	return r.changed
+}
+
+// Merge updates the catalog to the entries of a template (.pot), like
+// msgmerge. Entries are put in template order: matching entries keep their
+// translation and get the template's references, extracted comments and
+// flags; new entries are added, with the translation of the most similar old
+// msgid marked fuzzy (and the old msgid kept as "#|" previous strings) if
+// there is one; entries no longer in the template are marked obsolete and
+// moved to the end. An obsolete entry whose msgid comes back is revived.
+// The POT-Creation-Date header field is taken from the template.
+func (c *Catalog) Merge(template *Catalog) *MergeResult {
+	result := &MergeResult{}
+	nplurals := max(c.header.NPlurals(), 2)
+
+	// Translated entries to reuse for changed msgids, before anything changes
+	var candidates []*Entry
+	for _, entry := range c.entries {
+		if !entry.IsEmpty() && !entry.IsFuzzy() {
+			candidates = append(candidates, entry)
+		}
+	}
+
+	obsolete := make(map[string]*Entry)
+	for _, entry := range c.entries {
+		if _, ok := obsolete[entry.Key()]; entry.Obsolete && !ok {
+			obsolete[entry.Key()] = entry
+		}
+	}
+
+	used := make(map[*Entry]bool)
+	seen := make(map[string]bool)
+	var entries []*Entry
+	for _, ref := range template.Entries() {
+		if ref.Obsolete || seen[ref.Key()] {
+			continue
+		}
+		seen[ref.Key()] = true
+
+		entry := c.index[ref.Key()]
+		if entry == nil {
+			if revived := obsolete[ref.Key()]; revived != nil && !used[revived] {
+				revived.Obsolete = false
+				entry = revived
+				result.changed = true
+			}
+		}
+
+		if entry != nil {
+			used[entry] = true
+			result.Matched++
+			changed, fuzzy := mergeEntry(entry, ref, nplurals)
+			switch {
+			case fuzzy:
+				result.Fuzzy++
+			case changed:
+				result.Updated++
+			}
+			if changed {
+				result.changed = true
+			}
+			entries = append(entries, entry)
+			continue
+		}
+
+		entry = newEntry(ref)
+		if similar := mostSimilar(ref, candidates); similar != nil {
+			entry.Comments = slices.Clone(similar.Comments)
+			copyTranslation(entry, similar, nplurals)
+			entry.Flags = append([]string{FlagFuzzy}, entry.Flags...)
+			entry.PreviousMsgCtxt = similar.MsgCtxt
+			entry.PreviousMsgID = similar.MsgID
+			entry.PreviousMsgIDPlural = similar.MsgIDPlural
+			result.Fuzzy++
+		} else {
+			result.Added++
+		}
+		result.changed = true
+		entries = append(entries, entry)
+	}
+
+	// Everything not in the template goes to the end, obsolete
+	for _, entry := range c.entries {
+		if used[entry] {
+			continue
+		}
+		if !entry.Obsolete {
+			// Obsolete entries keep no references, as with msgmerge
+			entry.Obsolete = true
+			entry.References = nil
+			result.Obsolete++
+			result.changed = true
+		}
+		entries = append(entries, entry)
+	}
+
+	if !slices.Equal(entries, c.entries) {
+		result.changed = true
+	}
+	c.setEntries(entries)
+
+	date := template.Header().Get("POT-Creation-Date")
+	if date != "" && c.header.Has("POT-Creation-Date") && c.header.Get("POT-Creation-Date") != date {
+		c.header.Set("POT-Creation-Date", date)
+		result.changed = true
+	}
+	return result
+}
+
+// setEntries replaces the entries of the catalog and rebuilds the index
+func (c *Catalog) setEntries(entries []*Entry) {
+	// Entries read without a closing blank line must not run into the next
+	// one, and an entry moved to the end must not leave blank lines behind
+	for i, entry := range entries {
+		if len(entry.RawLines) == 0 {
+			continue
+		}
+		if i < len(entries)-1 {
+			if len(entry.Trailer) == 0 || strings.TrimSpace(entry.Trailer[len(entry.Trailer)-1]) != "" {
+				entry.Trailer = append(entry.Trailer, "")
+			}
+		} else if len(c.entries) == 0 || c.entries[len(c.entries)-1] != entry {
+			for len(entry.Trailer) > 0 && strings.TrimSpace(entry.Trailer[len(entry.Trailer)-1]) == "" {
+				entry.Trailer = entry.Trailer[:len(entry.Trailer)-1]
+			}
+		}
+	}
+
+	c.entries = entries
+	c.index = make(map[string]*Entry)
+	for _, entry := range entries {
+		c.indexEntry(entry)
+	}
+}
+
+// mergeEntry updates an entry to its template entry and reports whether it
+// changed, and whether it was marked fuzzy because a translated entry's
+// msgid_plural changed
+func mergeEntry(entry, ref *Entry, nplurals int) (changed, markedFuzzy bool) {
+	if !slices.Equal(entry.References, ref.References) {
+		entry.References = slices.Clone(ref.References)
+		changed = true
+	}
+	if !slices.Equal(entry.ExtractedComments, ref.ExtractedComments) {
+		entry.ExtractedComments = slices.Clone(ref.ExtractedComments)
+		changed = true
+	}
+
+	fuzzy := entry.IsFuzzy()
+	if entry.MsgIDPlural != ref.MsgIDPlural {
+		if !fuzzy && isTranslated(entry) {
+			fuzzy, markedFuzzy = true, true
+			entry.PreviousMsgCtxt = entry.MsgCtxt
+			entry.PreviousMsgID = entry.MsgID
+			entry.PreviousMsgIDPlural = entry.MsgIDPlural
+		}
+		old := *entry
+		entry.MsgIDPlural = ref.MsgIDPlural
+		entry.MsgStr, entry.MsgStrPlural = "", nil
+		copyTranslation(entry, &old, nplurals)
+		changed = true
+	}
+
+	// Format flags come from the template; fuzzy stays with the translation
+	flags := templateFlags(ref)
+	if fuzzy {
+		flags = append([]string{FlagFuzzy}, flags...)
+	}
+	if !sameFlags(entry.Flags, flags) {
+		entry.Flags = flags
+		changed = true
+	}
+
+	return changed, markedFuzzy
+}
+
+// sameFlags reports whether two flag lists hold the same flags, in any order
+func sameFlags(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// isTranslated reports whether an entry has any translation
+func isTranslated(entry *Entry) bool {
+	return entry.MsgStr != "" || slices.ContainsFunc(entry.MsgStrPlural, func(s string) bool { return s != "" })
+}
+
+// newEntry returns a new untranslated entry for a template entry
+func newEntry(ref *Entry) *Entry {
+	return &Entry{
+		MsgCtxt:           ref.MsgCtxt,
+		MsgID:             ref.MsgID,
+		MsgIDPlural:       ref.MsgIDPlural,
+		ExtractedComments: slices.Clone(ref.ExtractedComments),
+		References:        slices.Clone(ref.References),
+		Flags:             templateFlags(ref),
+	}
+}
+
+// templateFlags returns the flags of a template entry other than fuzzy
+// (set on the header of a fresh template only)
+func templateFlags(ref *Entry) []string {
+	var flags []string
+	for _, flag := range ref.Flags {
+		if flag != FlagFuzzy {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// copyTranslation sets the msgstr of entry from src, converting between
+// singular and plural: a singular translation becomes msgstr[0] of nplurals
+// forms, and msgstr[0] becomes the singular translation
+func copyTranslation(entry, src *Entry, nplurals int) {
+	switch {
+	case entry.IsPlural() && src.IsPlural():
+		entry.MsgStrPlural = slices.Clone(src.MsgStrPlural)
+	case entry.IsPlural():
+		entry.MsgStrPlural = make([]string, nplurals)
+		entry.MsgStrPlural[0] = src.MsgStr
+	case src.IsPlural():
+		if len(src.MsgStrPlural) > 0 {
+			entry.MsgStr = src.MsgStrPlural[0]
+		}
+	default:
+		entry.MsgStr = src.MsgStr
+	}
+}
+
+// mostSimilar returns the candidate whose msgid is most similar to that of
+// ref, if it is similar enough. Candidates with the same msgctxt win ties.
+func mostSimilar(ref *Entry, candidates []*Entry) *Entry {
+	var best *Entry
+	bestScore := fuzzyThreshold
+	source := []rune(ref.MsgID)
+	for _, candidate := range candidates {
+		score := similarity(source, []rune(candidate.MsgID), bestScore)
+		if score < fuzzyThreshold {
+			continue
+		}
+		if best == nil || score > bestScore ||
+			(score == bestScore && candidate.MsgCtxt == ref.MsgCtxt && best.MsgCtxt != ref.MsgCtxt) {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+// similarity returns how similar two strings are, from 0 to 1: twice the
+// length of their longest common subsequence over their total length. It
+// returns 0 early if the result cannot reach atLeast.
+func similarity(a, b []rune, atLeast float64) float64 {
+	total := len(a) + len(b)
+	if total == 0 {
+		return 1
+	}
+	if float64(2*min(len(a), len(b)))/float64(total) < atLeast {
+		return 0
+	}
+
+	// Longest common subsequence, one row at a time
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return float64(2*prev[len(b)]) / float64(total)
+}
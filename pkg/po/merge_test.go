@@ -0,0 +1,173 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+const mergeTemplate = `msgid ""
+msgstr ""
+"POT-Creation-Date: 2025-10-10 12:00+0000\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. Shown on the front page
+#: lib/page.ex:3
+msgid "Welcome"
+msgstr ""
+
+#: lib/menu.ex:7
+#, elixir-format
+msgid "Please sign in to continue"
+msgstr ""
+
+#: lib/menu.ex:9
+msgctxt "button"
+msgid "Open"
+msgstr ""
+
+#: lib/files.ex:2
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#: lib/page.ex:20
+msgid "Brand new"
+msgstr ""
+
+#: lib/page.ex:30
+msgid "Gone"
+msgstr ""
+`
+
+const mergeCatalog = `msgid ""
+msgstr ""
+"Language: sv\n"
+"POT-Creation-Date: 2025-01-01 12:00+0000\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+# Checked by Anna
+#: lib/menu.ex:5
+msgid "Sign in to continue"
+msgstr "Logga in för att fortsätta"
+
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+#: lib/page.ex:1
+msgid "Welcome"
+msgstr "Välkommen"
+
+msgid "%d file"
+msgstr "%d fil"
+
+msgid "Removed"
+msgstr "Borttagen"
+
+#~ msgid "Gone"
+#~ msgstr "Borta"
+`
+
+func readString(t *testing.T, text string) *Catalog {
+	t.Helper()
+	catalog, err := ReadCatalog(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ReadCatalog failed: %v", err)
+	}
+	return catalog
+}
+
+func TestCatalog_Merge(t *testing.T) {
+	catalog := readString(t, mergeCatalog)
+	result := catalog.Merge(readString(t, mergeTemplate))
+
+	if !result.Changed() {
+		t.Fatal("expected the catalog to change")
+	}
+	if result.Matched != 4 || result.Added != 1 || result.Fuzzy != 2 || result.Obsolete != 2 || result.Updated != 3 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	want := `msgid ""
+msgstr ""
+"Language: sv\n"
+"POT-Creation-Date: 2025-10-10 12:00+0000\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. Shown on the front page
+#: lib/page.ex:3
+msgid "Welcome"
+msgstr "Välkommen"
+
+# Checked by Anna
+#: lib/menu.ex:7
+#, fuzzy, elixir-format
+#| msgid "Sign in to continue"
+msgid "Please sign in to continue"
+msgstr "Logga in för att fortsätta"
+
+#: lib/menu.ex:9
+msgctxt "button"
+msgid "Open"
+msgstr "Öppna"
+
+#: lib/files.ex:2
+#, fuzzy
+#| msgid "%d file"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fil"
+msgstr[1] ""
+
+#: lib/page.ex:20
+msgid "Brand new"
+msgstr ""
+
+#: lib/page.ex:30
+msgid "Gone"
+msgstr "Borta"
+
+# Checked by Anna
+#~ msgid "Sign in to continue"
+#~ msgstr "Logga in för att fortsätta"
+
+#~ msgid "Removed"
+#~ msgstr "Borttagen"
+`
+	if got := writeString(t, catalog); got != want {
+		t.Errorf("unexpected merge output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCatalog_MergeUnchanged(t *testing.T) {
+	template := readString(t, mergeTemplate)
+	catalog := readString(t, mergeCatalog)
+	catalog.Merge(template)
+	merged := writeString(t, catalog)
+
+	again := readString(t, merged)
+	if result := again.Merge(template); result.Changed() {
+		t.Errorf("merging twice changed the catalog: %+v", result)
+	}
+	if got := writeString(t, again); got != merged {
+		t.Errorf("second merge changed the output:\n%s", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "xyz", 0},
+		{"abcd", "abxy", 0.5},
+	}
+	for _, tt := range tests {
+		if got := similarity([]rune(tt.a), []rune(tt.b), 0); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package po
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xnilsson/poflow/internal/mo"
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+// moSource serves the entries of a compiled .mo file. The whole file is
+// decoded up front: .mo files are small and their strings are not in order.
+type moSource struct {
+	header   *model.MsgEntry
+	entries  []*model.MsgEntry
+	charset  string
+	err      error
+	filename string
+	language string
+}
+
+// newMOSource decodes a .mo file from r
+func newMOSource(r io.Reader) *moSource {
+	s := &moSource{}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	s.header, s.entries, err = mo.Decode(data)
+	if err != nil {
+		s.err = fmt.Errorf("invalid .mo file: %w", err)
+		return s
+	}
+	s.charset = s.ParsedHeader().Charset()
+	return s
+}
+
+// Next returns the next message, with the file and language as its position
+func (s *moSource) Next() *model.MsgEntry {
+	if s.err != nil || len(s.entries) == 0 {
+		return nil
+	}
+	entry := s.entries[0]
+	s.entries = s.entries[1:]
+
+	language := s.language
+	if language == "" {
+		language = s.ParsedHeader().Language()
+	}
+	entry.Position = &model.Position{File: s.filename, Language: language}
+	return entry
+}
+
+func (s *moSource) Err() error                            { return s.err }
+func (s *moSource) SetStrict(bool)                        {} // .mo files have no syntax to check
+func (s *moSource) Errors() []*parser.SyntaxError         { return nil }
+func (s *moSource) SetFilename(name string)               { s.filename = name }
+func (s *moSource) SetLanguage(language string)           { s.language = language }
+func (s *moSource) Charset() string                       { return s.charset }
+func (s *moSource) Header() []string                      { return nil }
+func (s *moSource) HeaderEntry() *model.MsgEntry          { return s.header }
+func (s *moSource) SplitHeader() (before, after []string) { return nil, nil }
+
+// ParsedHeader returns the parsed header fields (empty without a header entry)
+func (s *moSource) ParsedHeader() *model.Header {
+	if s.header == nil {
+		return &model.Header{}
+	}
+	return model.ParseHeader(s.header.MsgStr)
+}
@@ -0,0 +1,46 @@
+package po
+
+import (
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/output"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+// Entry is a single message in a catalog: msgctxt, msgid, msgid_plural, the
+// translations, comments, references, flags and previous strings
+type Entry = model.MsgEntry
+
+// Header holds the fields of the header entry (msgid "") in file order
+type Header = model.Header
+
+// HeaderField is a single "Key: Value" header line
+type HeaderField = model.HeaderField
+
+// Position records where an entry was read from
+type Position = model.Position
+
+// SyntaxError is a problem found in strict mode, with its line and column
+type SyntaxError = parser.SyntaxError
+
+// FlagFuzzy marks a translation that needs review ("#, fuzzy")
+const FlagFuzzy = model.FlagFuzzy
+
+// DefaultWidth is the column at which re-encoded strings are wrapped by default
+const DefaultWidth = output.DefaultWidth
+
+// Key builds the lookup key for a (msgctxt, msgid) pair, as used by
+// Entry.Key. Entries without a context are keyed by msgid alone.
+func Key(msgctxt, msgid string) string {
+	return model.Key(msgctxt, msgid)
+}
+
+// ParseHeader parses the msgstr of a header entry into its fields
+func ParseHeader(msgstr string) *Header {
+	return model.ParseHeader(msgstr)
+}
+
+// SetWrapWidth sets the column at which re-encoded strings are wrapped,
+// like msgcat --width. Zero or a negative width disables wrapping.
+func SetWrapWidth(width int) {
+	output.SetWrapWidth(width)
+}
@@ -0,0 +1,110 @@
+package po
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/xnilsson/poflow/internal/mo"
+	"github.com/xnilsson/poflow/internal/model"
+	"github.com/xnilsson/poflow/internal/parser"
+)
+
+// Reader streams the entries of a .po catalog one by one
+type Reader struct {
+	p      source
+	binary bool // Input is a compiled .mo file
+}
+
+// source produces entries: the .po parser, or a decoded .mo file
+type source interface {
+	Next() *model.MsgEntry
+	Err() error
+	SetStrict(strict bool)
+	Errors() []*parser.SyntaxError
+	SetFilename(name string)
+	SetLanguage(language string)
+	Charset() string
+	Header() []string
+	HeaderEntry() *model.MsgEntry
+	ParsedHeader() *model.Header
+	SplitHeader() (before, after []string)
+}
+
+// NewReader creates a Reader for r. The charset declared in the header is
+// detected from the first bytes of r and entries are decoded to UTF-8.
+// Compiled .mo files are recognized by their magic number and decoded into
+// the same entries, without comments, flags or line positions.
+func NewReader(r io.Reader) *Reader {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(4); mo.IsMO(head) {
+		return &Reader{p: newMOSource(br), binary: true}
+	}
+	return &Reader{p: parser.NewParser(br)}
+}
+
+// IsMO reports whether the input is a compiled .mo file rather than .po text
+func (r *Reader) IsMO() bool {
+	return r.binary
+}
+
+// Next returns the next entry, or nil at the end of input or on an error (see Err).
+// The header entry (msgid "") is not returned; see Header.
+func (r *Reader) Next() *Entry {
+	return r.p.Next()
+}
+
+// Err returns the first read or decoding error, or in strict mode the first
+// syntax error. It is nil after a clean end of input.
+func (r *Reader) Err() error {
+	return r.p.Err()
+}
+
+// SetStrict enables strict mode: syntax problems that are normally tolerated
+// are recorded and reported by Errors and Err
+func (r *Reader) SetStrict(strict bool) {
+	r.p.SetStrict(strict)
+}
+
+// Errors returns the syntax errors found so far in strict mode
+func (r *Reader) Errors() []*SyntaxError {
+	return r.p.Errors()
+}
+
+// SetFilename sets the catalog path recorded in entry positions and syntax errors
+func (r *Reader) SetFilename(name string) {
+	r.p.SetFilename(name)
+}
+
+// SetLanguage sets the language recorded in entry positions. By default the
+// header's Language field is used.
+func (r *Reader) SetLanguage(language string) {
+	r.p.SetLanguage(language)
+}
+
+// Charset returns the charset declared in the header's Content-Type ("" if none)
+func (r *Reader) Charset() string {
+	return r.p.Charset()
+}
+
+// Header returns the parsed header fields (empty if the catalog has no header
+// entry). It is available once the first entry has been read.
+func (r *Reader) Header() *Header {
+	return r.p.ParsedHeader()
+}
+
+// HeaderEntry returns the header entry (msgid "") or nil if the catalog has none.
+// It is available once the first entry has been read.
+func (r *Reader) HeaderEntry() *Entry {
+	return r.p.HeaderEntry()
+}
+
+// HeaderLines returns the raw lines before the first entry: leading comments,
+// the header entry and the blank lines around them
+func (r *Reader) HeaderLines() []string {
+	return r.p.Header()
+}
+
+// splitHeader returns the header lines before and after the header entry
+func (r *Reader) splitHeader() (before, after []string) {
+	return r.p.SplitHeader()
+}
@@ -0,0 +1,61 @@
+package po
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/xnilsson/poflow/internal/charset"
+	"github.com/xnilsson/poflow/internal/output"
+)
+
+// Writer encodes .po text to an underlying writer in a given charset
+type Writer struct {
+	w       *bufio.Writer
+	encoder io.WriteCloser
+	charset string
+}
+
+// NewWriter creates a Writer that encodes to w in charsetName ("" or "UTF-8"
+// for no conversion). Close must be called to flush the output.
+func NewWriter(w io.Writer, charsetName string) (*Writer, error) {
+	encoder, err := charset.NewWriter(w, charsetName)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: bufio.NewWriter(encoder), encoder: encoder, charset: charsetName}, nil
+}
+
+// WriteLines writes raw lines, such as the header lines of a Reader
+func (w *Writer) WriteLines(lines []string) error {
+	for _, line := range lines {
+		if _, err := w.w.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteEntry writes an entry as .po text. Entries read from a file keep their
+// original lines except for the fields that changed; new entries are written
+// in msgcat style followed by a blank line.
+func (w *Writer) WriteEntry(entry *Entry) error {
+	_, err := w.w.WriteString(output.FormatEntry(entry))
+	return err
+}
+
+// Close flushes buffered output. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", w.charset, err)
+	}
+	if err := w.encoder.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", w.charset, err)
+	}
+	return nil
+}
+
+// FormatEntry returns an entry as .po text, as WriteEntry would write it in UTF-8
+func FormatEntry(entry *Entry) string {
+	return output.FormatEntry(entry)
+}